@@ -0,0 +1,255 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Backup phases
+const (
+	BackupPhaseRunning   = "Running"
+	BackupPhaseCompleted = "Completed"
+	BackupPhaseFailed    = "Failed"
+)
+
+// PolicyReference identifies the BackupPolicy that created a Backup
+type PolicyReference struct {
+	// Name of the BackupPolicy
+	Name string `json:"name"`
+
+	// Namespace of the BackupPolicy
+	Namespace string `json:"namespace"`
+}
+
+// BackupStats summarises what a backup Job stored, as reported by restic
+type BackupStats struct {
+	// Files that were not in the previous snapshot
+	FilesNew int64 `json:"filesNew"`
+
+	// Files that changed since the previous snapshot
+	FilesChanged int64 `json:"filesChanged"`
+
+	// Files unchanged since the previous snapshot
+	FilesUnmodified int64 `json:"filesUnmodified"`
+
+	// Bytes read from the PVC
+	BytesProcessed int64 `json:"bytesProcessed"`
+
+	// Bytes of new data after deduplication
+	BytesAdded int64 `json:"bytesAdded"`
+
+	// Bytes written to the repository after deduplication and compression (restic >= 0.17)
+	// +optional
+	BytesStored int64 `json:"bytesStored,omitempty"`
+
+	// Time restic spent on the backup (e.g., "1m30s")
+	Duration string `json:"duration,omitempty"`
+}
+
+// ReplicaStatus is the state of a backup's copy on one replica destination
+type ReplicaStatus struct {
+	// Name of the replica in the BackupPolicy
+	Name string `json:"name"`
+
+	// Replication phase: Running, Completed, Failed
+	Phase string `json:"phase,omitempty"`
+
+	// Location of the repository holding the copy
+	Location string `json:"location,omitempty"`
+
+	// Job copying the backup
+	// +optional
+	Job string `json:"job,omitempty"`
+
+	// When the copy completed or failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Number of times the copy was started; failed copies are retried with backoff up to a limit
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+
+	// Details about the replication result (e.g., why it failed)
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// Restore phases
+const (
+	RestorePhaseStarted = "Started"
+	RestorePhaseFailed  = "Failed"
+)
+
+// RestoreStatus is the outcome of a restore requested with the restore annotation
+type RestoreStatus struct {
+	// PVC the backup is restored into (namespace/name)
+	Target string `json:"target,omitempty"`
+
+	// Restore phase: Started (the restore Job or PVC was created), Failed
+	Phase string `json:"phase"`
+
+	// Details about the restore, e.g. why it could not start
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// When the restore was requested
+	// +optional
+	RequestTime string `json:"requestTime,omitempty"`
+
+	// When the operator handled the request
+	Time *metav1.Time `json:"time,omitempty"`
+}
+
+// DeletionStatus records failed attempts to delete the artifact of a Backup being deleted
+type DeletionStatus struct {
+	// Number of failed attempts; the operator stops retrying after a limit
+	Attempts int32 `json:"attempts,omitempty"`
+
+	// When the last attempt failed
+	// +optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+
+	// Why the last attempt failed
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ImportSource identifies where an imported Backup was found
+type ImportSource struct {
+	// BackupRepository in the Backup's namespace that imported the backup
+	Repository string `json:"repository"`
+
+	// Namespace of the source PVC in the cluster that wrote the backup
+	Namespace string `json:"namespace"`
+
+	// File holding the dump in the snapshot, set for database dumps
+	// +optional
+	DumpFile string `json:"dumpFile,omitempty"`
+}
+
+// BackupSpec defines the source of a Backup.
+type BackupSpec struct {
+	// Policy that created this backup
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="policyRef is immutable"
+	PolicyRef PolicyReference `json:"policyRef"`
+
+	// Source PVC name (in the Backup's namespace, or in imported.namespace of the source cluster)
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="pvcName is immutable"
+	PVCName string `json:"pvcName"`
+
+	// Backup strategy used: snapshot, external
+	// +kubebuilder:validation:Enum=snapshot;external
+	Strategy string `json:"strategy"`
+
+	// Set on Backups imported by a BackupRepository, whose policyRef names the source policy.
+	// Imported Backups are read-only: deleting them leaves the snapshot in the repository.
+	// +optional
+	Imported *ImportSource `json:"imported,omitempty"`
+}
+
+// BackupStatus defines the observed state of Backup.
+type BackupStatus struct {
+	// Backup phase: Running, Completed, Failed
+	Phase string `json:"phase,omitempty"`
+
+	// Full location/path of the backup
+	// Examples:
+	//   Snapshot: default/pvc-snapshot-xyz
+	//   S3: s3:s3.amazonaws.com/bucket/backups/policy/default/mysql
+	Location string `json:"location,omitempty"`
+
+	// Backup size (human-readable, e.g., "1.5Gi"); the bytes read from the PVC once the backup completed
+	Size string `json:"size,omitempty"`
+
+	// ID of the restic snapshot holding the backup
+	// +optional
+	SnapshotID string `json:"snapshotID,omitempty"`
+
+	// Statistics reported by the backup Job
+	// +optional
+	Stats *BackupStats `json:"stats,omitempty"`
+
+	// Key of the Kubernetes manifests exported with the backup, relative to the destination URL
+	// +optional
+	Manifests string `json:"manifests,omitempty"`
+
+	// When the backup started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// When the backup completed or failed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Details about the backup result (e.g., the error reported by a failed backup Job)
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Timestamp of the last successful verification of this backup
+	// +optional
+	LastVerifiedTime *metav1.Time `json:"lastVerifiedTime,omitempty"`
+
+	// Last restore of this backup requested with the kubectl-backup plugin
+	// +optional
+	LastRestore *RestoreStatus `json:"lastRestore,omitempty"`
+
+	// Failed attempts to delete the backup's artifact after the Backup was deleted
+	// +optional
+	Deletion *DeletionStatus `json:"deletion,omitempty"`
+
+	// Copies of this backup on the policy's replica destinations
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Replicas []ReplicaStatus `json:"replicas,omitempty"`
+
+	// Conditions for this backup (e.g., Verified)
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Policy",type=string,JSONPath=`.spec.policyRef.name`
+// +kubebuilder:printcolumn:name="PVC",type=string,JSONPath=`.spec.pvcName`
+// +kubebuilder:printcolumn:name="Strategy",type=string,JSONPath=`.spec.strategy`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Size",type=string,JSONPath=`.status.size`
+// +kubebuilder:printcolumn:name="Snapshot",type=string,JSONPath=`.status.snapshotID`,priority=1
+// +kubebuilder:printcolumn:name="Completed",type=date,JSONPath=`.status.completionTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Backup is the Schema for the backups API. Each Backup records one backup of a PVC taken by a BackupPolicy.
+type Backup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupSpec   `json:"spec,omitempty"`
+	Status BackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BackupList contains a list of Backup.
+type BackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []Backup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Backup{}, &BackupList{})
+}
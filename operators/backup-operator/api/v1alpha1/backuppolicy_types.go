@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -32,8 +33,27 @@ type Target struct {
 }
 
 type Retention struct {
-	MaxBackups int    `json:"maxBackups,omitempty"`
-	MaxAge     string `json:"maxAge,omitempty"`
+	MaxBackups int `json:"maxBackups,omitempty"`
+
+	// Age after which backups are deleted, in the format of restic forget --keep-within: a sequence of
+	// numbers with the units y(ears), m(onths), d(ays) and h(ours), e.g. "7d" or "1y6m"
+	// +kubebuilder:validation:Pattern=`^([0-9]+[ymdh])+$`
+	MaxAge string `json:"maxAge,omitempty"`
+}
+
+// Replica is a secondary destination that completed backups are copied to
+type Replica struct {
+	// Name of the replica, used in Job names and status
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=20
+	Name string `json:"name"`
+
+	// Destination the backups are copied to; its repository may use a different restic password
+	Destination Destination `json:"destination"`
+
+	// Retention applied to the copies; the policy retention when unset
+	// +optional
+	Retention *Retention `json:"retention,omitempty"`
 }
 
 type Destination struct {
@@ -62,6 +82,103 @@ type Destination struct {
 	// Storage class for S3-compatible backends (STANDARD, GLACIER, DEEP_ARCHIVE)
 	// +optional
 	StorageClass string `json:"storageClass,omitempty"`
+
+	// Secret name containing the restic repository password (key "restic-password")
+	// Keeps encryption keys separate from storage credentials; falls back to
+	// credentialsSecret when empty.
+	// +optional
+	EncryptionSecret string `json:"encryptionSecret,omitempty"`
+
+	// How backup Jobs and the operator authenticate to S3; static keys from credentialsSecret when unset
+	// +optional
+	Auth *DestinationAuth `json:"auth,omitempty"`
+
+	// Server-side encryption of objects written to S3
+	// +optional
+	ServerSideEncryption *ServerSideEncryption `json:"serverSideEncryption,omitempty"`
+
+	// Object lock retention making S3 backups immutable (the bucket must have object lock enabled)
+	// +optional
+	ObjectLock *ObjectLock `json:"objectLock,omitempty"`
+
+	// Transfer tuning for large uploads and downloads
+	// +optional
+	Transfer *TransferOptions `json:"transfer,omitempty"`
+}
+
+// TransferOptions tunes multipart transfers to external storage
+type TransferOptions struct {
+	// Size of each multipart upload part (minimum 5Mi); larger parts allow larger objects
+	// +optional
+	PartSize *resource.Quantity `json:"partSize,omitempty"`
+
+	// Number of parts or connections transferred in parallel
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Concurrency int32 `json:"concurrency,omitempty"`
+
+	// Bandwidth limit in bytes per second (e.g., "50Mi"), applied to uploads and downloads
+	// +optional
+	BandwidthLimit *resource.Quantity `json:"bandwidthLimit,omitempty"`
+
+	// Checksum computed on upload and validated on download: CRC32C or SHA256
+	// +kubebuilder:validation:Enum=CRC32C;SHA256
+	// +optional
+	ChecksumAlgorithm string `json:"checksumAlgorithm,omitempty"`
+}
+
+// ServerSideEncryption configures SSE-S3 or SSE-KMS for S3 objects
+// +kubebuilder:validation:XValidation:rule="!has(self.kmsKeyID) || self.algorithm == 'aws:kms'",message="kmsKeyID requires algorithm aws:kms"
+type ServerSideEncryption struct {
+	// Encryption algorithm: AES256 (SSE-S3) or aws:kms (SSE-KMS)
+	// +kubebuilder:validation:Enum=AES256;"aws:kms"
+	Algorithm string `json:"algorithm"`
+
+	// KMS key ID or ARN for aws:kms; the AWS managed key is used when empty
+	// +optional
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+}
+
+// ObjectLock configures the retention applied to S3 objects
+type ObjectLock struct {
+	// Retention mode: GOVERNANCE (removable with special permissions) or COMPLIANCE (removable by nobody)
+	// +kubebuilder:validation:Enum=GOVERNANCE;COMPLIANCE
+	Mode string `json:"mode"`
+
+	// Days objects are locked after they are written
+	// +kubebuilder:validation:Minimum=1
+	RetentionDays int32 `json:"retentionDays"`
+}
+
+// S3 authentication modes
+const (
+	// AuthModeStatic reads access-key and secret-key from the credentials Secret
+	AuthModeStatic = "Static"
+	// AuthModeDefaultChain uses the AWS default credential chain, e.g. IRSA web identity tokens
+	AuthModeDefaultChain = "DefaultChain"
+)
+
+// DestinationAuth configures credentials for S3 destinations without long-lived keys
+type DestinationAuth struct {
+	// Credential source: Static (keys from credentialsSecret) or DefaultChain (environment, web identity, instance role)
+	// +kubebuilder:validation:Enum=Static;DefaultChain
+	// +kubebuilder:default=Static
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// Service account the backup Jobs run as (must exist in every PVC namespace).
+	// For IRSA, annotate it with eks.amazonaws.com/role-arn so the web identity token is projected into the pods.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// IAM role assumed through STS on top of the base credentials
+	// +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`
+	// +optional
+	RoleARN string `json:"roleARN,omitempty"`
+
+	// External ID required by the trust policy of roleARN
+	// +optional
+	ExternalID string `json:"externalID,omitempty"`
 }
 
 type Restore struct {
@@ -69,6 +186,109 @@ type Restore struct {
 	Selector  metav1.LabelSelector `json:"selector,omitempty"`
 }
 
+// I/O scheduling classes for reading PVC data
+const (
+	IOClassIdle       = "Idle"
+	IOClassBestEffort = "BestEffort"
+)
+
+// Throttle limits the network, disk and CPU usage of backup Jobs so they do not degrade the workloads they protect
+type Throttle struct {
+	// Upload limit in bytes per second (e.g., "20Mi")
+	// +optional
+	UploadLimit *resource.Quantity `json:"uploadLimit,omitempty"`
+
+	// Download limit in bytes per second
+	// +optional
+	DownloadLimit *resource.Quantity `json:"downloadLimit,omitempty"`
+
+	// I/O scheduling class for reading the PVC: Idle (only when the disk is otherwise idle) or BestEffort
+	// +kubebuilder:validation:Enum=Idle;BestEffort
+	// +optional
+	IOClass string `json:"ioClass,omitempty"`
+
+	// BestEffort I/O priority from 0 (highest) to 7 (lowest)
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=7
+	// +optional
+	IOPriority *int32 `json:"ioPriority,omitempty"`
+
+	// CPU limit of the backup container (e.g., "500m"); 1 CPU when unset
+	// +optional
+	CPULimit *resource.Quantity `json:"cpuLimit,omitempty"`
+}
+
+// Verification configures periodic checks that stored backups can actually be restored
+type Verification struct {
+	// Cron schedule for verification runs (e.g., "0 4 * * 0" for weekly on Sunday at 4 AM)
+	// +kubebuilder:validation:Required
+	Schedule string `json:"schedule"`
+
+	// Subset of repository data read back by "restic check --read-data-subset"
+	// Examples: "10%", "1/5", "500M". Empty only checks repository structure.
+	// +optional
+	ReadDataSubset string `json:"readDataSubset,omitempty"`
+
+	// Restore the latest snapshot into a scratch PVC and compare file checksums
+	// +optional
+	TestRestore bool `json:"testRestore,omitempty"`
+
+	// Path inside the volume to restore during test restores (empty restores the whole snapshot)
+	// +optional
+	SamplePath string `json:"samplePath,omitempty"`
+
+	// Storage class for the scratch PVC used by test restores (empty uses the cluster default)
+	// +optional
+	ScratchStorageClassName string `json:"scratchStorageClassName,omitempty"`
+}
+
+// Notifications configures where backup lifecycle notifications are sent
+type Notifications struct {
+	// Webhooks receiving notifications
+	// +optional
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+}
+
+// Webhook is an HTTP endpoint notified about backup events
+type Webhook struct {
+	// Name identifying the webhook in logs and events
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Secret in the policy namespace holding the webhook URL under key "url"
+	// URLs of chat webhooks embed credentials, so they are never stored in the spec.
+	// +kubebuilder:validation:Required
+	URLSecret string `json:"urlSecret"`
+
+	// Payload format: generic (JSON event), slack or teams (incoming webhook messages)
+	// +kubebuilder:validation:Enum=generic;slack;teams
+	// +kubebuilder:default=generic
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// Go text/template rendering the request body from the event instead of the format, e.g.
+	// {"text": {{ printf "%s: %s" .Type .Message | json }}}. The fields are those of the generic
+	// payload (.Type, .PolicyNamespace, .Policy, .Namespace, .PVC, .Backup, .Message, .Time) and
+	// the json function quotes a value for JSON.
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// Events to notify about: BackupSucceeded, BackupFailed, ScheduleMissed (empty means all)
+	// +optional
+	Events []NotificationEvent `json:"events,omitempty"`
+}
+
+// NotificationEvent is a backup lifecycle event that can trigger a notification
+// +kubebuilder:validation:Enum=BackupSucceeded;BackupFailed;ScheduleMissed
+type NotificationEvent string
+
+const (
+	NotificationBackupSucceeded NotificationEvent = "BackupSucceeded"
+	NotificationBackupFailed    NotificationEvent = "BackupFailed"
+	NotificationScheduleMissed  NotificationEvent = "ScheduleMissed"
+)
+
+// StoredBackup describes a backup artifact found in the backup storage of a strategy
 type StoredBackup struct {
 	// Backup name/identifier
 	Name string `json:"name"`
@@ -96,14 +316,131 @@ type StoredBackup struct {
 
 	// Backup strategy used: snapshot, external
 	Strategy string `json:"strategy,omitempty"`
+
+	// restic snapshot ID in the repository (external strategy)
+	SnapshotID string `json:"snapshotID,omitempty"`
+
+	// Key of the exported Kubernetes manifests in the destination
+	Manifests string `json:"manifests,omitempty"`
+}
+
+// KeyRotationStatus tracks an encryption key rotation of the policy's restic repositories
+type KeyRotationStatus struct {
+	// Secret holding the new restic password
+	NewSecret string `json:"newSecret"`
+
+	// Secret holding the restic password being replaced
+	OldSecret string `json:"oldSecret,omitempty"`
+
+	// Rotation phase: AddingKey, AwaitingSecretSwitch, RemovingKey, Completed, Failed
+	Phase string `json:"phase"`
+
+	// Replicas whose repositories used the old password and are rotated with the policy's
+	// +optional
+	Replicas []string `json:"replicas,omitempty"`
+
+	// Human-readable details, set when the rotation fails
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Rotation Jobs of the current phase (namespace/name)
+	// +optional
+	Jobs []string `json:"jobs,omitempty"`
+
+	// When the rotation started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// When the rotation completed or failed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// ReplicaSummary counts the backups copied to a replica destination
+type ReplicaSummary struct {
+	// Name of the replica
+	Name string `json:"name"`
+
+	// Timestamp of the last successful copy
+	// +optional
+	LastReplicationTime *metav1.Time `json:"lastReplicationTime,omitempty"`
+
+	// Number of Backups copied to the replica
+	CompletedBackups int `json:"completedBackups,omitempty"`
+
+	// Number of Backups whose copy failed
+	FailedBackups int `json:"failedBackups,omitempty"`
+}
+
+// Deletion policies applied to backups when their BackupPolicy is deleted
+const (
+	DeletionPolicyRetain = "Retain"
+	DeletionPolicyDelete = "Delete"
+)
+
+// Dump configures a logical database dump streamed into the restic repository
+type Dump struct {
+	// Database engine: postgres (pg_dump), mysql (mysqldump) or mongodb (mongodump)
+	// +kubebuilder:validation:Enum=postgres;mysql;mongodb
+	Engine string `json:"engine"`
+
+	// Service of the database in the namespace of each target PVC
+	// +kubebuilder:validation:MinLength=1
+	Service string `json:"service"`
+
+	// Service port; the engine default (5432, 3306, 27017) when unset
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Database to dump; empty dumps all databases
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// Secret in the policy namespace with "username" and "password" keys; copied into PVC namespaces
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecret string `json:"credentialsSecret"`
+
+	// Image providing the dump tool; use the major version of the server.
+	// Defaults to postgres:16, mysql:8.4 or mongo:7.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Extra arguments passed to the dump tool, e.g. ["--schema=public"]
+	// +optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+}
+
+// Ways of exporting Secrets with the manifests
+const (
+	ManifestSecretsExclude   = "Exclude"
+	ManifestSecretsEncrypted = "Encrypted"
+	ManifestSecretsPlain     = "Plain"
+)
+
+// Manifests configures the export of the Kubernetes objects around each backed up PVC
+type Manifests struct {
+	// How Secrets used by the workload are exported: Exclude, Encrypted (values sealed with the key in
+	// sealKeySecret) or Plain (protected only by the bucket encryption)
+	// +kubebuilder:validation:Enum=Exclude;Encrypted;Plain
+	// +kubebuilder:default=Exclude
+	// +optional
+	Secrets string `json:"secrets,omitempty"`
+
+	// Secret in the policy's namespace holding the key that seals exported Secret values, created with a
+	// random key when missing. Defaults to <policy>-manifests-key. It is independent of the restic
+	// password, so key rotations keep older manifests readable.
+	// +optional
+	SealKeySecret string `json:"sealKeySecret,omitempty"`
 }
 
 // BackupPolicySpec defines the desired state of BackupPolicy.
+// +kubebuilder:validation:XValidation:rule="!has(self.dump) || (has(self.strategy) && self.strategy == 'external')",message="dump requires strategy external"
+// +kubebuilder:validation:XValidation:rule="!has(self.manifests) || (has(self.strategy) && self.strategy == 'external' && has(self.destination) && has(self.destination.type) && self.destination.type == 's3')",message="manifests require strategy external with an s3 destination"
 type BackupPolicySpec struct {
 	// Label selector for PVCs to backup
 	Selector metav1.LabelSelector `json:"selector,omitempty"`
 
-	// Namespaces to search for PVCs (empty means all namespaces if RBAC permits)
+	// Namespaces to search for PVCs (empty means the policy's own namespace)
 	// +optional
 	Namespaces []string `json:"namespaces,omitempty"`
 
@@ -111,6 +448,11 @@ type BackupPolicySpec struct {
 	// +kubebuilder:validation:Required
 	Schedule string `json:"schedule"`
 
+	// Suspend stops scheduled backups, verifications and replication; running Jobs are not affected.
+	// Backups requested with the backup-now annotation still run.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
 	// Backup strategy: "snapshot" (VolumeSnapshot) or "external" (S3/NFS)
 	// snapshot: Fast, local, short-term (default)
 	// external: Slower, remote, long-term
@@ -129,26 +471,132 @@ type BackupPolicySpec struct {
 	// Restore configuration (optional, for future restore operations)
 	// +optional
 	Restore Restore `json:"restore,omitempty"`
+
+	// Number of successful backup and verification Jobs to keep per namespace
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	// +optional
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// Number of failed backup and verification Jobs to keep per namespace for debugging
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	// +optional
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+
+	// Seconds a backup Job may run before Kubernetes stops it (default 1800); raise it for large volumes.
+	// Jobs still running well past their deadline are deleted as stuck.
+	// +kubebuilder:validation:Minimum=60
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// Logical dump of a database Service instead of a copy of the PVC filesystem (external strategy only).
+	// The target PVCs select the database instances; their data is not read.
+	// +optional
+	Dump *Dump `json:"dump,omitempty"`
+
+	// Export the PVC, its Deployment or StatefulSet and the ConfigMaps, Secrets and Services they use
+	// as YAML next to each backup, to re-create them on restore (external strategy with s3 destinations only)
+	// +optional
+	Manifests *Manifests `json:"manifests,omitempty"`
+
+	// Bandwidth, I/O and CPU limits of backup Jobs (external strategy only);
+	// unset fields fall back to the operator defaults
+	// +optional
+	Throttle *Throttle `json:"throttle,omitempty"`
+
+	// Periodic verification of stored backups (external strategy only)
+	// +optional
+	Verification *Verification `json:"verification,omitempty"`
+
+	// Webhook notifications for backup successes, failures and missed schedules
+	// +optional
+	Notifications *Notifications `json:"notifications,omitempty"`
+
+	// Secondary destinations completed backups are copied to (external strategy only)
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=5
+	// +optional
+	Replicas []Replica `json:"replicas,omitempty"`
+
+	// What happens to backups when the policy is deleted
+	// Retain: Backups, VolumeSnapshots and remote data are kept and detached from the policy
+	// Delete: every Backup and its artifact is deleted before the policy goes away
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Retain
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
 }
 
+// Condition types reported in BackupPolicy status
+const (
+	// ConditionReady is true while the policy is reconciled without errors
+	ConditionReady = "Ready"
+	// ConditionScheduled is true while the schedule is valid and the next backup time is known
+	ConditionScheduled = "Scheduled"
+	// ConditionLastBackupSucceeded is true when the latest finished backup of every PVC completed
+	ConditionLastBackupSucceeded = "LastBackupSucceeded"
+	// ConditionDestinationReachable is true when the backup destination can be used
+	ConditionDestinationReachable = "DestinationReachable"
+)
+
 // BackupPolicyStatus defines the observed state of BackupPolicy.
 type BackupPolicyStatus struct {
 	// Current phase: Active, Error, Suspended
 	Phase string `json:"phase,omitempty"`
 
+	// Generation of the spec the status was computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Timestamp of the last successful backup
 	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
 
 	// Calculated next run time based on schedule
 	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
 
-	// Total number of backups currently stored
+	// Timestamp of the last verification run
+	LastVerificationTime *metav1.Time `json:"lastVerificationTime,omitempty"`
+
+	// Calculated next verification time based on the verification schedule
+	NextVerificationTime *metav1.Time `json:"nextVerificationTime,omitempty"`
+
+	// Total number of Backup resources of this policy
 	BackupCount int `json:"backupCount,omitempty"`
 
-	// List of stored backups with metadata
+	// Number of completed Backups
+	CompletedBackups int `json:"completedBackups,omitempty"`
+
+	// Number of failed Backups
+	FailedBackups int `json:"failedBackups,omitempty"`
+
+	// Replication progress per replica destination
+	// +optional
+	Replicas []ReplicaSummary `json:"replicas,omitempty"`
+
+	// Timestamp of the last destination connectivity check (external strategy only)
+	// +optional
+	LastDestinationCheckTime *metav1.Time `json:"lastDestinationCheckTime,omitempty"`
+
+	// Progress of the last requested encryption key rotation
+	// +optional
+	KeyRotation *KeyRotationStatus `json:"keyRotation,omitempty"`
+
+	// Repositories of the backups deleted with the policy, as <namespace>/<pvc>; the emptied ones are removed
+	// from the destination before the policy is released
+	// +optional
+	PurgedRepositories []string `json:"purgedRepositories,omitempty"`
+
+	// Deprecated: backups recorded by earlier versions of the operator. The controller migrates them into
+	// Backup resources and clears the list.
+	// +optional
 	StoredBackups []StoredBackup `json:"storedBackups,omitempty"`
 
-	// Standard condition types for status reporting
+	// Ready, Scheduled, LastBackupSucceeded and DestinationReachable conditions
+	// +listType=map
+	// +listMapKey=type
+	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
@@ -0,0 +1,79 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupPolicyTemplateSpec defines the BackupPolicies generated for annotated PVCs.
+type BackupPolicyTemplateSpec struct {
+	// Namespace the generated policies are created in, named "<template>-<pvc namespace>".
+	// Empty creates a policy named after the template in every namespace with annotated PVCs.
+	// Credential Secrets referenced by the policy must exist in the namespace of the policy.
+	// +optional
+	PolicyNamespace string `json:"policyNamespace,omitempty"`
+
+	// Spec of the generated policies (schedule, retention, destination, ...).
+	// The selector and namespaces are set by the operator to the annotated PVCs of one namespace.
+	Policy BackupPolicySpec `json:"policy"`
+}
+
+// BackupPolicyTemplateStatus defines the observed state of BackupPolicyTemplate.
+type BackupPolicyTemplateStatus struct {
+	// Generation of the spec the generated policies were last updated for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Generated policies (namespace/name)
+	// +optional
+	Policies []string `json:"policies,omitempty"`
+
+	// Number of PVCs annotated with the template
+	PVCCount int `json:"pvcCount,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Strategy",type=string,JSONPath=`.spec.policy.strategy`
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.policy.schedule`
+// +kubebuilder:printcolumn:name="PVCs",type=integer,JSONPath=`.status.pvcCount`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BackupPolicyTemplate is the Schema for the backuppolicytemplates API.
+// PVCs opt in with the backup.backup.example.com/policy-template annotation set to the template name.
+type BackupPolicyTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupPolicyTemplateSpec   `json:"spec,omitempty"`
+	Status BackupPolicyTemplateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BackupPolicyTemplateList contains a list of BackupPolicyTemplate.
+type BackupPolicyTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []BackupPolicyTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupPolicyTemplate{}, &BackupPolicyTemplateList{})
+}
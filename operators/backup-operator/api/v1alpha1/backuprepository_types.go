@@ -0,0 +1,110 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupRepository phases
+const (
+	RepositoryPhaseSyncing = "Syncing"
+	RepositoryPhaseReady   = "Ready"
+	RepositoryPhaseError   = "Error"
+)
+
+// BackupRepositorySpec defines the destination whose backups are imported.
+type BackupRepositorySpec struct {
+	// Destination the BackupPolicies of the source cluster wrote to; its restic repositories are found under
+	// <url>/<policy>/<namespace>/<pvc>. Credential and encryption Secrets are read from the namespace of the
+	// BackupRepository and copied into the namespaces restores run in.
+	// +kubebuilder:validation:XValidation:rule="has(self.type) && self.type == 's3'",message="only s3 destinations can be imported"
+	Destination Destination `json:"destination"`
+
+	// Source policies to import backups of; empty imports all
+	// +optional
+	Policies []string `json:"policies,omitempty"`
+
+	// Namespaces backups are restored into, by source namespace; unmapped namespaces keep their name
+	// +optional
+	NamespaceMapping map[string]string `json:"namespaceMapping,omitempty"`
+
+	// Interval between catalogue refreshes (default 1h)
+	// +optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+}
+
+// BackupRepositoryStatus defines the observed state of BackupRepository.
+type BackupRepositoryStatus struct {
+	// Current phase: Syncing, Ready, Error
+	Phase string `json:"phase,omitempty"`
+
+	// Generation of the spec the catalogue was last synced for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// When the catalogue was last synced
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Number of restic repositories found in the destination
+	Repositories int `json:"repositories,omitempty"`
+
+	// Number of imported Backups
+	BackupCount int `json:"backupCount,omitempty"`
+
+	// Details about the last sync, e.g. the repositories that could not be read
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Ready condition
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.destination.url`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Repositories",type=integer,JSONPath=`.status.repositories`
+// +kubebuilder:printcolumn:name="Backups",type=integer,JSONPath=`.status.backupCount`
+// +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+
+// BackupRepository is the Schema for the backuprepositories API.
+// It imports the backups found in a destination as read-only Backups, e.g. to restore them in another cluster.
+type BackupRepository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BackupRepositorySpec   `json:"spec,omitempty"`
+	Status BackupRepositoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BackupRepositoryList contains a list of BackupRepository.
+type BackupRepositoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []BackupRepository `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupRepository{}, &BackupRepositoryList{})
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -26,11 +27,14 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -39,6 +43,7 @@ import (
 
 	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
 	"github.com/example/backup-operator/internal/controller"
+	"github.com/example/backup-operator/internal/notify"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -64,6 +69,7 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
+	var uploadLimit, downloadLimit, ioClass, cpuLimit string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -81,6 +87,14 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&uploadLimit, "default-upload-limit", "",
+		"Upload limit in bytes per second (e.g. 20Mi) for backup Jobs of policies without spec.throttle.uploadLimit.")
+	flag.StringVar(&downloadLimit, "default-download-limit", "",
+		"Download limit in bytes per second for backup Jobs of policies without spec.throttle.downloadLimit.")
+	flag.StringVar(&ioClass, "default-io-class", "",
+		"I/O scheduling class (Idle or BestEffort) for backup Jobs of policies without spec.throttle.ioClass.")
+	flag.StringVar(&cpuLimit, "default-cpu-limit", "",
+		"CPU limit (e.g. 500m) for backup Jobs of policies without spec.throttle.cpuLimit.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -89,6 +103,12 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	throttle, err := parseDefaultThrottle(uploadLimit, downloadLimit, ioClass, cpuLimit)
+	if err != nil {
+		setupLog.Error(err, "invalid default throttle")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -185,6 +205,9 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "e303f292.backup.example.com",
+		// Secrets are read from the API server so their data is not cached for the whole cluster;
+		// the BackupPolicy controller watches their metadata only
+		Client: client.Options{Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}}},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -203,12 +226,39 @@ func main() {
 	}
 
 	if err := (&controller.BackupPolicyReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Recorder:        mgr.GetEventRecorderFor("backuppolicy-controller"),
+		Notifier:        notify.NewNotifier(mgr.GetClient()),
+		DefaultThrottle: throttle,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BackupPolicy")
 		os.Exit(1)
 	}
+	if err := (&controller.BackupReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("backup-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Backup")
+		os.Exit(1)
+	}
+	if err := (&controller.BackupPolicyTemplateReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("backuppolicytemplate-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupPolicyTemplate")
+		os.Exit(1)
+	}
+	if err := (&controller.BackupRepositoryReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("backuprepository-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupRepository")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -242,3 +292,36 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// parseDefaultThrottle builds the operator-wide backup Job throttle from flag values; nil when none is set
+func parseDefaultThrottle(uploadLimit, downloadLimit, ioClass, cpuLimit string) (*backupv1alpha1.Throttle, error) {
+	if uploadLimit == "" && downloadLimit == "" && ioClass == "" && cpuLimit == "" {
+		return nil, nil
+	}
+
+	throttle := &backupv1alpha1.Throttle{IOClass: ioClass}
+	switch ioClass {
+	case "", backupv1alpha1.IOClassIdle, backupv1alpha1.IOClassBestEffort:
+	default:
+		return nil, fmt.Errorf("unknown I/O class %q, expected %s or %s", ioClass, backupv1alpha1.IOClassIdle, backupv1alpha1.IOClassBestEffort)
+	}
+	for _, value := range []struct {
+		flag   string
+		raw    string
+		target **resource.Quantity
+	}{
+		{"default-upload-limit", uploadLimit, &throttle.UploadLimit},
+		{"default-download-limit", downloadLimit, &throttle.DownloadLimit},
+		{"default-cpu-limit", cpuLimit, &throttle.CPULimit},
+	} {
+		if value.raw == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value.raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s %q: %w", value.flag, value.raw, err)
+		}
+		*value.target = &quantity
+	}
+	return throttle, nil
+}
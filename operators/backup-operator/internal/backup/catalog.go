@@ -0,0 +1,329 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/storage"
+)
+
+// catalogLimit is the number of snapshots described by one catalogue Job, so the list fits into the 4 KiB
+// termination message of the Job
+const catalogLimit = 40
+
+// SourceRepository is the restic repository a BackupPolicy wrote the backups of one PVC to
+type SourceRepository struct {
+	// Policy that wrote the repository
+	Policy string
+	// Namespace of the source PVC
+	Namespace string
+	// Name of the source PVC
+	PVC string
+	// restic repository URL
+	Location string
+}
+
+// CatalogEntry is a snapshot listed by a catalogue Job
+type CatalogEntry struct {
+	// Short restic snapshot ID
+	SnapshotID string
+	// When the snapshot was taken
+	Time time.Time
+	// Backup that wrote the snapshot, from its backup tag; empty for untagged snapshots
+	Backup string
+	// First path of the snapshot: /data for volume backups, the dump file for database dumps
+	Path string
+	// Bytes read by the backup; 0 when restic did not record a summary (restic < 0.17)
+	SizeBytes int64
+}
+
+// IsDump reports whether the snapshot holds a database dump rather than a volume
+func (c *CatalogEntry) IsDump() bool {
+	return c.Path != "/data"
+}
+
+// Size returns the human-readable size of the snapshot, empty when unknown
+func (c *CatalogEntry) Size() string {
+	if c.SizeBytes == 0 {
+		return ""
+	}
+	return humanReadableQuantity(*resource.NewQuantity(c.SizeBytes, resource.BinarySI))
+}
+
+// catalogCommand describes the snapshots in $CATALOG_SNAPSHOTS, newest first, one per line as
+// "<short id> <time> <backup tag or -> <path> <bytes>", trimmed to the termination message limit.
+// Listing runs without a lock: the repository belongs to another cluster, which may be writing to it.
+const catalogCommand = `set -euo pipefail
+restic -r "$RESTIC_REPOSITORY" ${RESTIC_OPTIONS:-} --no-lock snapshots --json $CATALOG_SNAPSHOTS >/tmp/snapshots.json
+{ grep -o '"time":"[^"]*"\|"paths":\["[^"]*"\|"backup:[^"]*"\|"total_bytes_processed":[0-9]*\|"short_id":"[^"]*"' /tmp/snapshots.json || true; } \
+  | awk -F'"' '
+      /^"time"/ { t = $4; p = "-"; b = "-"; n = 0 }
+      /^"paths"/ { p = $4 }
+      /^"backup:/ { b = substr($2, 8) }
+      /^"total_bytes_processed"/ { split($0, kv, ":"); n = kv[2] }
+      /^"short_id"/ { print $4, t, b, p, n }' \
+  | sort -r -k2 \
+  | awk '{ size += length($0) + 1; if (size > 4000) exit; print }' >/tmp/catalog.txt
+echo "Listed $(wc -l </tmp/catalog.txt) snapshots of $RESTIC_REPOSITORY" >&2
+cp /tmp/catalog.txt /dev/termination-log`
+
+// RepositoryPolicy returns a policy writing to the destination of the BackupRepository from its namespace,
+// so the repositories of the named source policy are opened with the BackupRepository's credentials
+func RepositoryPolicy(repository *backupv1alpha1.BackupRepository, policyName string) *backupv1alpha1.BackupPolicy {
+	return &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: policyName, Namespace: repository.Namespace},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Strategy:    "external",
+			Destination: repository.Spec.Destination,
+		},
+	}
+}
+
+// CatalogJobName returns the name of the Job describing a batch of snapshots of a source repository
+func CatalogJobName(repository *backupv1alpha1.BackupRepository, source SourceRepository, snapshotIDs []string) string {
+	sum := sha256.Sum256([]byte(path.Join(append([]string{source.Policy, source.Namespace, source.PVC}, snapshotIDs...)...)))
+	return fmt.Sprintf("%s-catalog-%s", repository.Name, hex.EncodeToString(sum[:])[:10])
+}
+
+// SourceRepositoryForJob returns the source repository a catalogue Job lists
+func SourceRepositoryForJob(job *batchv1.Job) SourceRepository {
+	source := SourceRepository{
+		Policy:    job.Labels[LabelSourcePolicy],
+		Namespace: job.Labels[LabelSourceNamespace],
+		PVC:       job.Labels[LabelPVC],
+	}
+	source.Location = jobEnv(job, "RESTIC_REPOSITORY")
+	return source
+}
+
+// CatalogSnapshotsForJob returns the IDs of the snapshots a catalogue Job describes
+func CatalogSnapshotsForJob(job *batchv1.Job) []string {
+	return strings.Fields(jobEnv(job, "CATALOG_SNAPSHOTS"))
+}
+
+// jobEnv returns the value of an environment variable of the Job's containers
+func jobEnv(job *batchv1.Job, name string) string {
+	for _, container := range job.Spec.Template.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == name {
+				return env.Value
+			}
+		}
+	}
+	return ""
+}
+
+// SnapshotListed reports whether a possibly shortened snapshot ID is one of the sorted full IDs
+func SnapshotListed(sortedIDs []string, id string) bool {
+	if id == "" {
+		return false
+	}
+	i := sort.SearchStrings(sortedIDs, id)
+	return i < len(sortedIDs) && strings.HasPrefix(sortedIDs[i], id)
+}
+
+// ParseCatalog parses the termination message of a catalogue Job; malformed lines are skipped
+func ParseCatalog(message string) []CatalogEntry {
+	var entries []CatalogEntry
+	for _, line := range strings.Split(message, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 5 {
+			continue
+		}
+		taken, err := time.Parse(time.RFC3339Nano, fields[1])
+		if err != nil {
+			continue
+		}
+		entry := CatalogEntry{SnapshotID: fields[0], Time: taken, Path: fields[3]}
+		if fields[2] != "-" {
+			entry.Backup = fields[2]
+		}
+		entry.SizeBytes, _ = strconv.ParseInt(fields[4], 10, 64)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// DiscoverRepositories finds the restic repositories below the destination URL: the directories
+// <policy>/<namespace>/<pvc> holding a restic config file
+func (e *ExternalStrategy) DiscoverRepositories(ctx context.Context, repository *backupv1alpha1.BackupRepository) ([]SourceRepository, error) {
+	lister, ok := e.backend.(storage.PrefixLister)
+	if !ok {
+		return nil, fmt.Errorf("%s destinations cannot be searched for repositories", repository.Spec.Destination.Type)
+	}
+
+	policyDirs, err := lister.ListPrefixes(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	var sources []SourceRepository
+	for _, policyDir := range policyDirs {
+		policyName := path.Base(policyDir)
+		// Manifests and other operator data live in hidden directories next to the policies
+		if strings.HasPrefix(policyName, ".") {
+			continue
+		}
+		if len(repository.Spec.Policies) > 0 && !slices.Contains(repository.Spec.Policies, policyName) {
+			continue
+		}
+		namespaceDirs, err := lister.ListPrefixes(ctx, policyDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, namespaceDir := range namespaceDirs {
+			pvcDirs, err := lister.ListPrefixes(ctx, namespaceDir)
+			if err != nil {
+				return nil, err
+			}
+			for _, pvcDir := range pvcDirs {
+				found, err := e.backend.Exists(ctx, pvcDir+"config")
+				if err != nil {
+					return nil, err
+				}
+				if !found {
+					continue
+				}
+				pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: path.Base(pvcDir), Namespace: path.Base(namespaceDir)}}
+				location, err := e.repositoryURL(RepositoryPolicy(repository, policyName), pvc)
+				if err != nil {
+					return nil, err
+				}
+				sources = append(sources, SourceRepository{Policy: policyName, Namespace: pvc.Namespace, PVC: pvc.Name, Location: location})
+			}
+		}
+	}
+	return sources, nil
+}
+
+// ListSnapshots returns the sorted IDs of all snapshots of the source repository. restic stores every snapshot
+// as a file named after its ID, so the IDs are read from the destination without opening the repository.
+func (e *ExternalStrategy) ListSnapshots(ctx context.Context, source SourceRepository) ([]string, error) {
+	objects, err := e.backend.List(ctx, path.Join(source.Policy, source.Namespace, source.PVC, "snapshots")+"/")
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(objects))
+	for _, object := range objects {
+		ids = append(ids, path.Base(object.Path))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Catalog creates a Job in the namespace of the BackupRepository describing the first catalogLimit of the
+// given snapshots of the source repository
+func (e *ExternalStrategy) Catalog(ctx context.Context, repository *backupv1alpha1.BackupRepository, source SourceRepository, snapshotIDs []string) (string, error) {
+	policy := RepositoryPolicy(repository, source.Policy)
+	if err := e.validatePasswordSecret(ctx, policy); err != nil {
+		return "", err
+	}
+	if err := e.validateServiceAccount(ctx, repository.Namespace, policy); err != nil {
+		return "", err
+	}
+
+	if len(snapshotIDs) > catalogLimit {
+		snapshotIDs = snapshotIDs[:catalogLimit]
+	}
+	jobName := CatalogJobName(repository, source, snapshotIDs)
+	log.FromContext(ctx).Info("Creating catalogue Job", "job", jobName, "repository", source.Location, "snapshots", len(snapshotIDs))
+	job := e.buildCatalogJob(jobName, repository, source, policy, snapshotIDs)
+	if err := e.client.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create catalogue Job %s/%s: %w", repository.Namespace, jobName, err)
+	}
+	return jobName, nil
+}
+
+// ManifestsFor returns the key of the manifests exported with a backup, empty when there are none
+func (e *ExternalStrategy) ManifestsFor(ctx context.Context, source SourceRepository, backupName string) (string, error) {
+	if e.backend == nil || backupName == "" {
+		return "", nil
+	}
+	key := path.Join(manifestsDir(source.Policy, source.Namespace, source.PVC), backupName+".yaml")
+	found, err := e.backend.Exists(ctx, key)
+	if err != nil || !found {
+		return "", err
+	}
+	return key, nil
+}
+
+// buildCatalogJob creates the Job running catalogCommand against the source repository
+func (e *ExternalStrategy) buildCatalogJob(jobName string, repository *backupv1alpha1.BackupRepository, source SourceRepository, policy *backupv1alpha1.BackupPolicy, snapshotIDs []string) *batchv1.Job {
+	backoffLimit := int32(1)
+	activeDeadlineSeconds := int64(600)
+
+	env := append(e.buildBackupEnv(policy, source.Location),
+		corev1.EnvVar{Name: "CATALOG_SNAPSHOTS", Value: strings.Join(snapshotIDs, " ")})
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: repository.Namespace,
+			Labels: map[string]string{
+				LabelRepository:      repository.Name,
+				LabelSourcePolicy:    source.Policy,
+				LabelSourceNamespace: source.Namespace,
+				LabelPVC:             source.PVC,
+				LabelStrategy:        "external",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(repository, backupv1alpha1.GroupVersion.WithKind("BackupRepository")),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"backup.backup.example.com/job": jobName,
+					},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: jobServiceAccountName(policy),
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:                     "catalog",
+							Image:                    "restic/restic:latest",
+							Command:                  []string{"/bin/sh", "-c", catalogCommand},
+							Env:                      env,
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						},
+					},
+				},
+			},
+		},
+	}
+}
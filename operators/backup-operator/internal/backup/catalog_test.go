@@ -0,0 +1,159 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+func (b *objectBackend) Exists(_ context.Context, path string) (bool, error) {
+	_, found := b.objects[path]
+	return found, nil
+}
+
+func (b *objectBackend) ListPrefixes(_ context.Context, prefix string) ([]string, error) {
+	var prefixes []string
+	for path := range b.objects {
+		rest, found := strings.CutPrefix(path, prefix)
+		if !found {
+			continue
+		}
+		if dir, _, found := strings.Cut(rest, "/"); found && !slices.Contains(prefixes, prefix+dir+"/") {
+			prefixes = append(prefixes, prefix+dir+"/")
+		}
+	}
+	slices.Sort(prefixes)
+	return prefixes, nil
+}
+
+func newTestRepository() *backupv1alpha1.BackupRepository {
+	return &backupv1alpha1.BackupRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "dr", UID: "repo-uid"},
+		Spec: backupv1alpha1.BackupRepositorySpec{
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://bucket/backups", EncryptionSecret: "restic"},
+		},
+	}
+}
+
+func TestParseCatalog(t *testing.T) {
+	message := "4f2a9c1e 2025-01-02T02:00:00.123456789Z daily-data-db-0-20250102-020000 /data 2048\n" +
+		"garbage line\n" +
+		"9b1c0d2e 2025-01-01T02:00:00Z - /dump/db.sql 0\n"
+
+	entries := ParseCatalog(message)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	first := entries[0]
+	if first.SnapshotID != "4f2a9c1e" || first.Backup != "daily-data-db-0-20250102-020000" || first.IsDump() || first.Size() != "2Ki" {
+		t.Fatalf("unexpected first entry %+v", first)
+	}
+	if first.Time.Day() != 2 || first.Time.Hour() != 2 {
+		t.Fatalf("unexpected snapshot time %s", first.Time)
+	}
+	second := entries[1]
+	if second.Backup != "" || !second.IsDump() || second.Size() != "" {
+		t.Fatalf("unexpected second entry %+v", second)
+	}
+}
+
+func TestDiscoverRepositories(t *testing.T) {
+	repository := newTestRepository()
+	repository.Spec.Policies = []string{"daily"}
+	backend := &objectBackend{objects: map[string][]byte{
+		"daily/apps/data-db-0/config":        nil,
+		"daily/apps/data-db-0/keys/1":        nil,
+		"daily/apps/scratch/locks/1":         nil,
+		"daily/shop/orders/config":           nil,
+		"weekly/apps/data-db-0/config":       nil,
+		".manifests/daily/apps/data-db-0/x":  nil,
+		".manifests/daily/apps/data-db-0/y":  nil,
+		"daily/apps/data-db-0/snapshots/abc": nil,
+	}}
+	strategy := &ExternalStrategy{backend: backend}
+
+	sources, err := strategy.DiscoverRepositories(context.Background(), repository)
+	if err != nil {
+		t.Fatalf("DiscoverRepositories failed: %v", err)
+	}
+	expected := []SourceRepository{
+		{Policy: "daily", Namespace: "apps", PVC: "data-db-0", Location: "s3:bucket/backups/daily/apps/data-db-0"},
+		{Policy: "daily", Namespace: "shop", PVC: "orders", Location: "s3:bucket/backups/daily/shop/orders"},
+	}
+	if !slices.Equal(sources, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, sources)
+	}
+}
+
+func TestCatalogCreatesJob(t *testing.T) {
+	repository := newTestRepository()
+	password := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "restic", Namespace: "dr"},
+		Data:       map[string][]byte{ResticPasswordKey: []byte("pw")},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(newRestoreScheme(t)).WithObjects(repository, password).Build()
+	strategy := &ExternalStrategy{client: fakeClient}
+	source := SourceRepository{Policy: "daily", Namespace: "apps", PVC: "data-db-0", Location: "s3:bucket/backups/daily/apps/data-db-0"}
+	ctx := context.Background()
+
+	var snapshots []string
+	for i := 0; i < catalogLimit+5; i++ {
+		snapshots = append(snapshots, fmt.Sprintf("%08x", i))
+	}
+	jobName, err := strategy.Catalog(ctx, repository, source, snapshots)
+	if err != nil {
+		t.Fatalf("Catalog failed: %v", err)
+	}
+	if jobName != CatalogJobName(repository, source, snapshots[:catalogLimit]) {
+		t.Fatalf("unexpected Job name %q", jobName)
+	}
+	// A second sync while the Job still exists reuses it
+	if _, err := strategy.Catalog(ctx, repository, source, snapshots); err != nil {
+		t.Fatalf("Catalog failed for an existing Job: %v", err)
+	}
+
+	job := &batchv1.Job{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "dr", Name: jobName}, job); err != nil {
+		t.Fatalf("expected catalogue Job: %v", err)
+	}
+	if owner := metav1.GetControllerOf(job); owner == nil || owner.Name != "prod" {
+		t.Fatalf("expected the Job to be owned by the BackupRepository, got %v", job.OwnerReferences)
+	}
+	if got := SourceRepositoryForJob(job); got != source {
+		t.Fatalf("expected source %+v from the Job, got %+v", source, got)
+	}
+	if got := CatalogSnapshotsForJob(job); !slices.Equal(got, snapshots[:catalogLimit]) {
+		t.Fatalf("expected one batch of snapshots, got %v", got)
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	backend := &objectBackend{objects: map[string][]byte{
+		"daily/apps/data/config":                 nil,
+		"daily/apps/data/snapshots/c3c3c3c3ff00": nil,
+		"daily/apps/data/snapshots/a1a1a1a1ff00": nil,
+		"daily/apps/data2/snapshots/b2b2b2b2ff":  nil,
+	}}
+	strategy := &ExternalStrategy{backend: backend}
+
+	ids, err := strategy.ListSnapshots(context.Background(), SourceRepository{Policy: "daily", Namespace: "apps", PVC: "data"})
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if !slices.Equal(ids, []string{"a1a1a1a1ff00", "c3c3c3c3ff00"}) {
+		t.Fatalf("unexpected snapshots %v", ids)
+	}
+	if !SnapshotListed(ids, "c3c3c3c3") || SnapshotListed(ids, "b2b2b2b2") || SnapshotListed(ids, "") {
+		t.Fatal("expected short IDs to match the full IDs of the repository only")
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/storage"
+)
+
+// DeleteBackup forgets the restic snapshot of a backup and prunes its data in a Job.
+// It returns ErrDeletionInProgress until the Job has succeeded. A failed Job is removed and reported as
+// ErrDeletionFailed, so the next call starts a new one.
+func (e *ExternalStrategy) DeleteBackup(ctx context.Context, backup *backupv1alpha1.StoredBackup, policy *backupv1alpha1.BackupPolicy) error {
+	logger := log.FromContext(ctx)
+
+	jobName := backup.Name + "-forget"
+	job := &batchv1.Job{}
+	err := e.client.Get(ctx, types.NamespacedName{Namespace: backup.Namespace, Name: jobName}, job)
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("failed to get forget Job %s/%s: %w", backup.Namespace, jobName, err)
+	case !job.DeletionTimestamp.IsZero():
+		return ErrDeletionInProgress
+	case job.Status.Succeeded > 0:
+		return e.deleteManifests(ctx, backup)
+	case isJobFailed(job):
+		if err := e.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete failed forget Job %s/%s: %w", backup.Namespace, jobName, err)
+		}
+		return fmt.Errorf("%w: forget Job %s/%s failed: %s", ErrDeletionFailed, backup.Namespace, jobName, jobFailureMessage(job))
+	default:
+		return ErrDeletionInProgress
+	}
+
+	if err := e.ensureCredentialsSecret(ctx, backup.Namespace, policy); err != nil {
+		return err
+	}
+
+	repoURL := backup.Location
+	if repoURL == "" {
+		pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: backup.PVCName, Namespace: backup.Namespace}}
+		if repoURL, err = e.repositoryURL(policy, pvc); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Creating forget Job", "job", jobName, "backup", backup.Name, "namespace", backup.Namespace)
+	job = e.buildForgetJob(jobName, backup, policy, repoURL)
+	if err := e.client.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create forget Job %s/%s: %w", backup.Namespace, jobName, err)
+	}
+	return ErrDeletionInProgress
+}
+
+// buildForgetJob creates a Kubernetes Job removing the restic snapshot of a single backup
+func (e *ExternalStrategy) buildForgetJob(jobName string, backup *backupv1alpha1.StoredBackup, policy *backupv1alpha1.BackupPolicy, repoURL string) *batchv1.Job {
+	backoffLimit := int32(2)
+	ttlSecondsAfterFinished := int32(3600)
+	activeDeadlineSeconds := int64(1800)
+
+	env := append(e.buildBackupEnv(policy, repoURL),
+		corev1.EnvVar{Name: "BACKUP_NAME", Value: backup.Name},
+		corev1.EnvVar{Name: "SNAPSHOT_ID", Value: backup.SnapshotID})
+	// A completed backup has data to delete; finding no snapshot for it means it was looked up wrongly
+	if backup.Status == backupv1alpha1.BackupPhaseCompleted {
+		env = append(env, corev1.EnvVar{Name: "REQUIRE_SNAPSHOT", Value: "true"})
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: backup.Namespace,
+			Labels: map[string]string{
+				LabelPolicy:          policy.Name,
+				LabelPVC:             backup.PVCName,
+				LabelStrategy:        "external",
+				LabelPolicyNamespace: policy.Namespace,
+				LabelDeletedBackup:   backup.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttlSecondsAfterFinished,
+			ActiveDeadlineSeconds:   &activeDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"backup.backup.example.com/job": jobName,
+					},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: jobServiceAccountName(policy),
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					Containers: []corev1.Container{
+						{
+							Name:                     "forget",
+							Image:                    "restic/restic:latest",
+							Command:                  []string{"/bin/sh", "-c", forgetCommand},
+							Env:                      env,
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// forgetCommand removes the snapshot recorded for the backup, or the snapshots tagged with the backup name
+// when no ID was recorded, and prunes the repository. A missing repository means there is nothing left to
+// delete; a completed backup without a matching snapshot fails the Job instead of leaving its data behind.
+const forgetCommand = `set -euo pipefail
+` + reportScript + `
+set +e
+restic -r "$RESTIC_REPOSITORY" cat config >/dev/null 2>&1
+repo_status=$?
+set -e
+if [ "$repo_status" = "10" ]; then
+  echo "Repository $RESTIC_REPOSITORY does not exist, nothing to delete" >&2
+  exit 0
+fi
+if [ -n "${SNAPSHOT_ID:-}" ]; then
+  restic -r "$RESTIC_REPOSITORY" snapshots --json > /tmp/snapshots.json
+  IDS=$(grep -o "\"id\":\"$SNAPSHOT_ID[0-9a-f]*\"" /tmp/snapshots.json | cut -d'"' -f4 || true)
+else
+  restic -r "$RESTIC_REPOSITORY" snapshots --tag "backup:$BACKUP_NAME" --json > /tmp/snapshots.json
+  IDS=$(grep -o '"id":"[0-9a-f]*"' /tmp/snapshots.json | cut -d'"' -f4 || true)
+fi
+if [ -z "$IDS" ]; then
+  if [ "${REQUIRE_SNAPSHOT:-}" = "true" ]; then
+    report "no snapshot ${SNAPSHOT_ID:-tagged backup:$BACKUP_NAME} found for backup $BACKUP_NAME in $RESTIC_REPOSITORY"
+  fi
+  echo "No snapshots found for backup $BACKUP_NAME" >&2
+  exit 0
+fi
+restic -r "$RESTIC_REPOSITORY" forget $IDS
+echo "Deleted snapshots of backup $BACKUP_NAME" >&2
+# A retry after a failed prune would find no snapshot, and the next prune of the repository frees the data too
+restic -r "$RESTIC_REPOSITORY" prune || echo "Failed to prune $RESTIC_REPOSITORY, its data is freed by the next prune" >&2
+`
+
+// jobFailureMessage returns the message of the Job's Failed condition
+func jobFailureMessage(job *batchv1.Job) string {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue && c.Message != "" {
+			return c.Message
+		}
+	}
+	return "see its logs for details"
+}
+
+// RemoveRepositories deletes what is left of the given repositories of the policy once their snapshots
+// were forgotten: config, keys, locks and emptied index files. Repositories still holding snapshots, e.g.
+// of a same-named policy in another namespace backing up the same PVC, are kept.
+func (e *ExternalStrategy) RemoveRepositories(ctx context.Context, policy *backupv1alpha1.BackupPolicy, repositories []string) error {
+	logger := log.FromContext(ctx)
+	if e.backend == nil || len(repositories) == 0 {
+		return nil
+	}
+	lister, ok := e.backend.(storage.PrefixLister)
+	if !ok {
+		return fmt.Errorf("%s destinations cannot be searched for repositories", policy.Spec.Destination.Type)
+	}
+
+	for _, name := range repositories {
+		// Repositories live at <policy>/<namespace>/<pvc> below the destination URL
+		repository := policy.Name + "/" + name + "/"
+		dirs, err := lister.ListPrefixes(ctx, repository)
+		if err != nil {
+			return fmt.Errorf("failed to list repository %s: %w", repository, err)
+		}
+		if slices.Contains(dirs, repository+"snapshots/") {
+			logger.Info("Keeping repository that still holds snapshots", "repository", repository)
+			continue
+		}
+		objects, err := e.backend.List(ctx, repository)
+		if err != nil {
+			return fmt.Errorf("failed to list repository %s: %w", repository, err)
+		}
+		for _, object := range objects {
+			if err := e.backend.Delete(ctx, object.Path); err != nil {
+				return err
+			}
+		}
+		if len(objects) > 0 {
+			logger.Info("Removed empty repository", "repository", repository, "objects", len(objects))
+		}
+	}
+	return nil
+}
+
+// isJobFailed reports whether the Job reached its terminal Failed condition
+func isJobFailed(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
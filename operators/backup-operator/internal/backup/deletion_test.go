@@ -0,0 +1,166 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+func TestExternalDeleteBackupRunsForgetJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, batchv1.AddToScheme, backupv1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "target"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://bucket/backups"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy).Build()
+	strategy := &ExternalStrategy{client: fakeClient}
+	stored := &backupv1alpha1.StoredBackup{
+		Name:       "policy-data-20250101-020000",
+		Namespace:  "target",
+		PVCName:    "data",
+		Location:   "s3:s3.amazonaws.com/bucket/backups/policy/target/data",
+		Status:     backupv1alpha1.BackupPhaseCompleted,
+		SnapshotID: "0123abcd",
+	}
+
+	ctx := context.Background()
+	if err := strategy.DeleteBackup(ctx, stored, policy); !errors.Is(err, ErrDeletionInProgress) {
+		t.Fatalf("expected deletion to be in progress, got %v", err)
+	}
+
+	job := &batchv1.Job{}
+	key := types.NamespacedName{Namespace: "target", Name: stored.Name + "-forget"}
+	if err := fakeClient.Get(ctx, key, job); err != nil {
+		t.Fatalf("expected forget Job to be created: %v", err)
+	}
+	if job.Labels[LabelDeletedBackup] != stored.Name {
+		t.Fatalf("expected deleted backup label, got %v", job.Labels)
+	}
+	env := make(map[string]string)
+	for _, e := range job.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if env["RESTIC_REPOSITORY"] != stored.Location {
+		t.Fatalf("expected the recorded repository %q, got %q", stored.Location, env["RESTIC_REPOSITORY"])
+	}
+	// The recorded snapshot is forgotten, and a completed backup must have one
+	if env["SNAPSHOT_ID"] != stored.SnapshotID || env["REQUIRE_SNAPSHOT"] != "true" {
+		t.Fatalf("expected the Job to forget snapshot %s and require it, got %v", stored.SnapshotID, env)
+	}
+	if script := job.Spec.Template.Spec.Containers[0].Command[2]; !strings.Contains(script, "report \"no snapshot") {
+		t.Fatalf("expected a missing snapshot to fail the Job:\n%s", script)
+	}
+
+	job.Status.Succeeded = 1
+	if err := fakeClient.Status().Update(ctx, job); err != nil {
+		t.Fatalf("failed to update Job status: %v", err)
+	}
+	if err := strategy.DeleteBackup(ctx, stored, policy); err != nil {
+		t.Fatalf("expected deletion to finish once the Job succeeded, got %v", err)
+	}
+}
+
+func TestExternalDeleteBackupRetriesFailedForgetJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, batchv1.AddToScheme, backupv1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "target"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://bucket/backups"},
+		},
+	}
+	stored := &backupv1alpha1.StoredBackup{Name: "policy-data-20250101-020000", Namespace: "target", PVCName: "data"}
+	failed := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: stored.Name + "-forget", Namespace: "target"},
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+			Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "Job has reached the specified backoff limit",
+		}}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy, failed).Build()
+	strategy := &ExternalStrategy{client: fakeClient}
+	ctx := context.Background()
+
+	err := strategy.DeleteBackup(ctx, stored, policy)
+	if !errors.Is(err, ErrDeletionFailed) || !strings.Contains(err.Error(), "backoff limit") {
+		t.Fatalf("expected the failure of the forget Job, got %v", err)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(failed), &batchv1.Job{}); err == nil {
+		t.Fatal("expected the failed Job to be removed")
+	}
+
+	// The next attempt starts a new Job
+	if err := strategy.DeleteBackup(ctx, stored, policy); !errors.Is(err, ErrDeletionInProgress) {
+		t.Fatalf("expected a new forget Job, got %v", err)
+	}
+	job := &batchv1.Job{}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(failed), job); err != nil || isJobFailed(job) {
+		t.Fatalf("expected a fresh forget Job: %v", err)
+	}
+}
+
+func TestRemoveRepositoriesKeepsRepositoriesWithSnapshots(t *testing.T) {
+	backend := &objectBackend{objects: map[string][]byte{
+		"policy/apps/data/config":           nil,
+		"policy/apps/data/keys/abc":         nil,
+		"policy/apps/data/locks/def":        nil,
+		"policy/apps/logs/config":           nil,
+		"policy/apps/logs/snapshots/123":    nil,
+		"policy/apps/logs/data/12/1234":     nil,
+		"policy/web/cache/config":           nil,
+		"other/apps/data/config":            nil,
+		"manifests/policy/apps/data/x.yaml": nil,
+	}}
+	strategy := &ExternalStrategy{backend: backend}
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ops"}}
+
+	// policy/web/cache belongs to a same-named policy of another namespace that has no snapshot yet
+	if err := strategy.RemoveRepositories(context.Background(), policy, []string{"apps/data", "apps/logs"}); err != nil {
+		t.Fatalf("RemoveRepositories returned error: %v", err)
+	}
+	for _, removed := range []string{"policy/apps/data/config", "policy/apps/data/keys/abc", "policy/apps/data/locks/def"} {
+		if _, ok := backend.objects[removed]; ok {
+			t.Errorf("expected %s to be removed", removed)
+		}
+	}
+	for _, kept := range []string{"policy/apps/logs/config", "policy/apps/logs/snapshots/123", "policy/web/cache/config",
+		"other/apps/data/config", "manifests/policy/apps/data/x.yaml"} {
+		if _, ok := backend.objects[kept]; !ok {
+			t.Errorf("expected %s to be kept", kept)
+		}
+	}
+}
+
+func TestBackupCommandTagsSnapshotWithBackupName(t *testing.T) {
+	strategy := &ExternalStrategy{}
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "control"}}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "target"}}
+
+	command := strategy.buildBackupCommand("policy-data-1", pvc, policy, "s3:bucket/repo")
+	if !strings.Contains(command, `--tag "backup:policy-data-1"`) {
+		t.Fatalf("expected snapshot to be tagged with the backup name:\n%s", command)
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+// Database engines supported by dump mode
+const (
+	DumpEnginePostgres = "postgres"
+	DumpEngineMySQL    = "mysql"
+	DumpEngineMongoDB  = "mongodb"
+)
+
+// Keys of the dump credentials Secret
+const (
+	DumpUsernameKey = "username"
+	DumpPasswordKey = "password"
+)
+
+// dumpToolsPath is where the init container puts the restic binary for the database image
+const dumpToolsPath = "/tools"
+
+// validateDump checks that the database Service and credentials exist and copies the credentials next to the PVC
+func (e *ExternalStrategy) validateDump(ctx context.Context, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy) error {
+	dump := policy.Spec.Dump
+	if err := e.client.Get(ctx, types.NamespacedName{Namespace: pvc.Namespace, Name: dump.Service}, &corev1.Service{}); err != nil {
+		return fmt.Errorf("failed to get database Service %s/%s: %w", pvc.Namespace, dump.Service, err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := e.client.Get(ctx, types.NamespacedName{Namespace: policy.Namespace, Name: dump.CredentialsSecret}, secret); err != nil {
+		return fmt.Errorf("failed to read dump credentials secret %s/%s: %w", policy.Namespace, dump.CredentialsSecret, err)
+	}
+	for _, key := range []string{DumpUsernameKey, DumpPasswordKey} {
+		if len(secret.Data[key]) == 0 {
+			return fmt.Errorf("dump credentials secret %s/%s has no %q key", policy.Namespace, dump.CredentialsSecret, key)
+		}
+	}
+	return e.ensureSecretCopy(ctx, dump.CredentialsSecret, pvc.Namespace, policy)
+}
+
+// buildDumpJob turns the backup Job into one that streams a database dump into restic.
+// The database image provides the dump tool; an init container copies the static restic binary next to it.
+func (e *ExternalStrategy) buildDumpJob(backupName string, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy, repoURL string) *batchv1.Job {
+	job := e.buildBackupJob(backupName, pvc, policy, repoURL)
+	podSpec := &job.Spec.Template.Spec
+
+	tools := corev1.VolumeMount{Name: "tools", MountPath: dumpToolsPath}
+	podSpec.InitContainers = []corev1.Container{{
+		Name:         "restic",
+		Image:        "restic/restic:latest",
+		Command:      []string{"cp", "/usr/bin/restic", dumpToolsPath + "/restic"},
+		VolumeMounts: []corev1.VolumeMount{tools},
+	}}
+	// The database is read over the network, so the PVC is not mounted
+	podSpec.Volumes = []corev1.Volume{{
+		Name:         "tools",
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}}
+
+	container := &podSpec.Containers[0]
+	container.Image = dumpImage(policy.Spec.Dump)
+	container.Command = []string{"/bin/bash", "-c", e.buildDumpCommand(backupName, pvc, policy, repoURL)}
+	container.VolumeMounts = []corev1.VolumeMount{tools}
+	container.Env = append(container.Env, dumpEnv(policy.Spec.Dump, pvc.Namespace)...)
+	return job
+}
+
+// buildDumpCommand generates the dump command executed inside the Job pod. restic runs the dump tool itself
+// and fails the backup without saving a snapshot when the tool exits with an error.
+func (e *ExternalStrategy) buildDumpCommand(backupName string, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy, repoURL string) string {
+	return fmt.Sprintf(`set -euo pipefail
+export PATH="%s:$PATH"
+export RESTIC_REPOSITORY="%s"
+export RESTIC_TAG_POLICY="policy:%s"
+export RESTIC_TAG_PVC="pvc:%s"
+export RESTIC_TAG_NAMESPACE="namespace:%s"
+
+echo "Starting %s dump %s of $DUMP_HOST" >&2
+%s
+%s
+%s
+restic -r "$RESTIC_REPOSITORY" ${RESTIC_OPTIONS:-} backup --stdin-from-command --stdin-filename "$DUMP_FILENAME" --tag "$RESTIC_TAG_POLICY" --tag "$RESTIC_TAG_PVC" --tag "$RESTIC_TAG_NAMESPACE" --tag "backup:%s" --tag "dump:%s" --hostname "%s" --json --quiet -- %s >/tmp/backup.json
+awk '/"message_type":"summary"/ { summary = $0 } END { print summary }' /tmp/backup.json >/tmp/summary.json
+cat /tmp/summary.json >&2
+
+%s
+
+# The controller records the summary from the termination message in the Backup status
+cp /tmp/summary.json /dev/termination-log
+`, dumpToolsPath, repoURL, policy.Name, pvc.Name, pvc.Namespace, policy.Spec.Dump.Engine, backupName,
+		repositoryInitScript, ioniceScript, dumpSetupScript(policy.Spec.Dump),
+		backupName, policy.Spec.Dump.Engine, pvc.Namespace, dumpCommand(policy.Spec.Dump), retentionScript)
+}
+
+// dumpSetupScript prepares files the dump tool reads its password from
+func dumpSetupScript(dump *backupv1alpha1.Dump) string {
+	if dump.Engine != DumpEngineMongoDB {
+		return ""
+	}
+	// mongodump has no password environment variable; keep the password off the command line
+	return `printf '%s\n' "$DUMP_PASSWORD" | sed "s/'/''/g; s/^/password: '/; s/\$/'/" >/tmp/mongodump.yaml`
+}
+
+// dumpCommand returns the dump tool invocation writing the dump to stdout
+func dumpCommand(dump *backupv1alpha1.Dump) string {
+	var args []string
+	switch dump.Engine {
+	case DumpEnginePostgres:
+		if dump.Database == "" {
+			args = []string{"pg_dumpall", `--host "$DUMP_HOST"`, `--port "$DUMP_PORT"`, `--username "$DUMP_USERNAME"`}
+		} else {
+			args = []string{"pg_dump", `--host "$DUMP_HOST"`, `--port "$DUMP_PORT"`, `--username "$DUMP_USERNAME"`,
+				"--format=custom", `--dbname "$DUMP_DATABASE"`}
+		}
+	case DumpEngineMySQL:
+		args = []string{"mysqldump", `--host "$DUMP_HOST"`, `--port "$DUMP_PORT"`, `--user "$DUMP_USERNAME"`,
+			"--single-transaction", "--routines", "--events", "--triggers"}
+		if dump.Database == "" {
+			args = append(args, "--all-databases")
+		} else {
+			args = append(args, `--databases "$DUMP_DATABASE"`)
+		}
+	case DumpEngineMongoDB:
+		args = []string{"mongodump", `--host "$DUMP_HOST"`, `--port "$DUMP_PORT"`, `--username "$DUMP_USERNAME"`,
+			"--authenticationDatabase admin", "--config /tmp/mongodump.yaml", "--archive"}
+		if dump.Database != "" {
+			args = append(args, `--db "$DUMP_DATABASE"`)
+		}
+	}
+	for _, arg := range dump.ExtraArgs {
+		args = append(args, shellQuote(arg))
+	}
+	return strings.Join(args, " ")
+}
+
+// dumpEnv returns the connection settings and credentials of the dump tool
+func dumpEnv(dump *backupv1alpha1.Dump, namespace string) []corev1.EnvVar {
+	secretKey := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: dump.CredentialsSecret},
+				Key:                  key,
+			},
+		}
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "DUMP_HOST", Value: fmt.Sprintf("%s.%s.svc", dump.Service, namespace)},
+		{Name: "DUMP_PORT", Value: strconv.Itoa(int(dumpPort(dump)))},
+		{Name: "DUMP_DATABASE", Value: dump.Database},
+		{Name: "DUMP_FILENAME", Value: dumpFilename(dump)},
+		{Name: "DUMP_USERNAME", ValueFrom: secretKey(DumpUsernameKey)},
+		{Name: "DUMP_PASSWORD", ValueFrom: secretKey(DumpPasswordKey)},
+	}
+	// The client libraries read the password from the environment
+	switch dump.Engine {
+	case DumpEnginePostgres:
+		env = append(env, corev1.EnvVar{Name: "PGPASSWORD", ValueFrom: secretKey(DumpPasswordKey)})
+	case DumpEngineMySQL:
+		env = append(env, corev1.EnvVar{Name: "MYSQL_PWD", ValueFrom: secretKey(DumpPasswordKey)})
+	}
+	return env
+}
+
+// dumpImage returns the image providing the dump tool
+func dumpImage(dump *backupv1alpha1.Dump) string {
+	if dump.Image != "" {
+		return dump.Image
+	}
+	switch dump.Engine {
+	case DumpEngineMySQL:
+		return "mysql:8.4"
+	case DumpEngineMongoDB:
+		return "mongo:7"
+	default:
+		return "postgres:16"
+	}
+}
+
+func dumpPort(dump *backupv1alpha1.Dump) int32 {
+	if dump.Port != 0 {
+		return dump.Port
+	}
+	switch dump.Engine {
+	case DumpEngineMySQL:
+		return 3306
+	case DumpEngineMongoDB:
+		return 27017
+	default:
+		return 5432
+	}
+}
+
+// dumpFilename returns the name of the dump file inside the restic snapshot
+func dumpFilename(dump *backupv1alpha1.Dump) string {
+	name := dump.Database
+	if name == "" {
+		name = "all"
+	}
+	switch dump.Engine {
+	case DumpEngineMongoDB:
+		return name + ".archive"
+	case DumpEnginePostgres:
+		if dump.Database != "" {
+			// pg_dump custom format, restored with pg_restore
+			return name + ".dump"
+		}
+	}
+	return name + ".sql"
+}
+
+// shellQuote quotes s as a single shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,144 @@
+package backup
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+func newDumpPolicy() *backupv1alpha1.BackupPolicy {
+	return &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "control"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Strategy: "external",
+			Destination: backupv1alpha1.Destination{
+				Type:              "s3",
+				URL:               "s3://bucket/backups",
+				CredentialsSecret: "creds",
+			},
+			Dump: &backupv1alpha1.Dump{
+				Engine:            DumpEnginePostgres,
+				Service:           "db",
+				Database:          "orders",
+				CredentialsSecret: "db-creds",
+				ExtraArgs:         []string{"--schema=it's"},
+			},
+		},
+	}
+}
+
+func TestDumpBackupStreamsIntoRestic(t *testing.T) {
+	policy := newDumpPolicy()
+	objects := []runtime.Object{
+		policy,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "control"},
+			Data:       map[string][]byte{ResticPasswordKey: []byte("pw")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-creds", Namespace: "control"},
+			Data:       map[string][]byte{DumpUsernameKey: []byte("backup"), DumpPasswordKey: []byte("secret")},
+		},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps"}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(newRestoreScheme(t)).WithRuntimeObjects(objects...).Build()
+	strategy := &ExternalStrategy{client: fakeClient}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pgdata", Namespace: "apps"}}
+
+	ctx := context.Background()
+	result, err := strategy.Backup(ctx, pvc, policy)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if result.Metadata["dump"] != DumpEnginePostgres {
+		t.Fatalf("expected dump metadata, got %v", result.Metadata)
+	}
+
+	job := &batchv1.Job{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "apps", Name: result.Name}, job); err != nil {
+		t.Fatalf("expected backup Job: %v", err)
+	}
+	if job.Labels[LabelPolicy] != "policy" || job.Labels[LabelPVC] != "pgdata" {
+		t.Fatalf("expected the backup Job labels, got %v", job.Labels)
+	}
+	podSpec := job.Spec.Template.Spec
+	if len(podSpec.InitContainers) != 1 || len(podSpec.Volumes) != 1 || podSpec.Volumes[0].PersistentVolumeClaim != nil {
+		t.Fatalf("expected only the tools volume and the restic init container, got %+v", podSpec)
+	}
+	container := podSpec.Containers[0]
+	if container.Image != "postgres:16" {
+		t.Fatalf("expected the postgres image, got %s", container.Image)
+	}
+	command := container.Command[2]
+	for _, want := range []string{
+		"--stdin-from-command",
+		`-- pg_dump --host "$DUMP_HOST"`,
+		`--dbname "$DUMP_DATABASE" '--schema=it'\''s'`,
+		`--tag "backup:` + result.Name + `"`,
+	} {
+		if !strings.Contains(command, want) {
+			t.Fatalf("expected %q in command:\n%s", want, command)
+		}
+	}
+	env := map[string]corev1.EnvVar{}
+	for _, e := range container.Env {
+		env[e.Name] = e
+	}
+	if env["DUMP_HOST"].Value != "db.apps.svc" || env["DUMP_PORT"].Value != "5432" || env["DUMP_FILENAME"].Value != "orders.dump" {
+		t.Fatalf("unexpected connection settings %v", env)
+	}
+	if ref := env["PGPASSWORD"].ValueFrom; ref == nil || ref.SecretKeyRef.Name != "db-creds" {
+		t.Fatalf("expected PGPASSWORD from the credentials secret, got %+v", env["PGPASSWORD"])
+	}
+
+	// The credentials are copied next to the PVC like the storage credentials
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "apps", Name: "db-creds"}, &corev1.Secret{}); err != nil {
+		t.Fatalf("expected dump credentials to be copied: %v", err)
+	}
+}
+
+func TestDumpBackupRequiresService(t *testing.T) {
+	policy := newDumpPolicy()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "control"},
+		Data:       map[string][]byte{ResticPasswordKey: []byte("pw")},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(newRestoreScheme(t)).WithObjects(policy, secret).Build()
+	strategy := &ExternalStrategy{client: fakeClient}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pgdata", Namespace: "apps"}}
+
+	_, err := strategy.Backup(context.Background(), pvc, policy)
+	if err == nil || !strings.Contains(err.Error(), "database Service apps/db") {
+		t.Fatalf("expected a missing Service error, got %v", err)
+	}
+}
+
+func TestDumpCommandPerEngine(t *testing.T) {
+	tests := []struct {
+		dump     backupv1alpha1.Dump
+		command  string
+		filename string
+	}{
+		{backupv1alpha1.Dump{Engine: DumpEnginePostgres}, "pg_dumpall", "all.sql"},
+		{backupv1alpha1.Dump{Engine: DumpEngineMySQL, Database: "shop"}, `--databases "$DUMP_DATABASE"`, "shop.sql"},
+		{backupv1alpha1.Dump{Engine: DumpEngineMySQL}, "--all-databases", "all.sql"},
+		{backupv1alpha1.Dump{Engine: DumpEngineMongoDB}, "mongodump", "all.archive"},
+	}
+	for _, tt := range tests {
+		if command := dumpCommand(&tt.dump); !strings.Contains(command, tt.command) {
+			t.Errorf("%s: expected %q in %q", tt.dump.Engine, tt.command, command)
+		}
+		if filename := dumpFilename(&tt.dump); filename != tt.filename {
+			t.Errorf("%s: expected filename %q, got %q", tt.dump.Engine, tt.filename, filename)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+// Key rotation runs in two phases across all repositories of a policy so that every
+// repository accepts the new password before any old password is removed.
+const (
+	// KeyRotationAddKey adds the new password as an additional restic key
+	KeyRotationAddKey = "add"
+	// KeyRotationRemoveKey removes the key belonging to the old password
+	KeyRotationRemoveKey = "remove"
+)
+
+// RotateKey creates a Job performing one phase of a restic key rotation for the PVC's repository.
+// oldSecret and newSecret name Secrets in the policy namespace holding the "restic-password" key.
+func (e *ExternalStrategy) RotateKey(ctx context.Context, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy, oldSecret, newSecret, phase string) (string, error) {
+	logger := log.FromContext(ctx)
+
+	if phase != KeyRotationAddKey && phase != KeyRotationRemoveKey {
+		return "", fmt.Errorf("unknown key rotation phase: %s", phase)
+	}
+
+	for _, name := range []string{oldSecret, newSecret} {
+		if err := validatePasswordSecret(ctx, e.client, policy.Namespace, name); err != nil {
+			return "", err
+		}
+		if err := e.ensureSecretCopy(ctx, name, pvc.Namespace, policy); err != nil {
+			return "", err
+		}
+	}
+	if err := e.ensureSecretCopy(ctx, policy.Spec.Destination.CredentialsSecret, pvc.Namespace, policy); err != nil {
+		return "", err
+	}
+
+	repoURL, err := e.repositoryURL(policy, pvc)
+	if err != nil {
+		return "", err
+	}
+
+	// Replica repositories of the same PVC are rotated alongside, so the name tells the repositories apart
+	sum := sha256.Sum256([]byte(repoURL))
+	jobName := fmt.Sprintf("%s-%s-key%s-%s-%s", policy.Name, pvc.Name, phase, hex.EncodeToString(sum[:])[:5], time.Now().Format("20060102-150405"))
+	logger.Info("Creating key rotation Job", "job", jobName, "phase", phase, "pvc", pvc.Name, "namespace", pvc.Namespace)
+
+	job := e.buildKeyRotationJob(jobName, pvc, policy, repoURL, oldSecret, newSecret, phase)
+	if err := e.client.Create(ctx, job); err != nil {
+		return "", fmt.Errorf("failed to create key rotation Job %s/%s: %w", pvc.Namespace, jobName, err)
+	}
+
+	return jobName, nil
+}
+
+// buildKeyRotationJob creates a Kubernetes Job running one key rotation phase against a restic repository
+func (e *ExternalStrategy) buildKeyRotationJob(jobName string, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy, repoURL, oldSecret, newSecret, phase string) *batchv1.Job {
+	backoffLimit := int32(2)
+	ttlSecondsAfterFinished := int32(3600)
+	activeDeadlineSeconds := int64(600)
+
+	env := e.buildBackupEnv(policy, repoURL)
+	// Replace the policy password with the explicit old/new passwords of this rotation
+	filtered := env[:0]
+	for _, v := range env {
+		if v.Name != "RESTIC_PASSWORD" {
+			filtered = append(filtered, v)
+		}
+	}
+	env = append(filtered,
+		passwordEnv("RESTIC_OLD_PASSWORD", oldSecret),
+		passwordEnv("RESTIC_NEW_PASSWORD", newSecret),
+	)
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: pvc.Namespace,
+			Labels: map[string]string{
+				LabelPolicy:           policy.Name,
+				LabelPVC:              pvc.Name,
+				LabelStrategy:         "external",
+				LabelPolicyNamespace:  policy.Namespace,
+				LabelKeyRotation:      newSecret,
+				LabelKeyRotationPhase: phase,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttlSecondsAfterFinished,
+			ActiveDeadlineSeconds:   &activeDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"backup.backup.example.com/job": jobName,
+					},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: jobServiceAccountName(policy),
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					Containers: []corev1.Container{
+						{
+							Name:                     "rotate-key",
+							Image:                    "restic/restic:latest",
+							Command:                  []string{"/bin/sh", "-c", buildKeyRotationCommand(phase)},
+							Env:                      env,
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if policy.Namespace == pvc.Namespace {
+		job.OwnerReferences = []metav1.OwnerReference{*ownerReferenceFor(policy, pvc.Namespace)}
+	}
+
+	return job
+}
+
+// buildKeyRotationCommand generates the key rotation script; both phases are idempotent so retries are safe
+func buildKeyRotationCommand(phase string) string {
+	if phase == KeyRotationAddKey {
+		return `set -euo pipefail
+if RESTIC_PASSWORD="$RESTIC_NEW_PASSWORD" restic -r "$RESTIC_REPOSITORY" cat config >/dev/null 2>&1; then
+  echo "New key already present in $RESTIC_REPOSITORY" >&2
+  exit 0
+fi
+printf '%s' "$RESTIC_NEW_PASSWORD" > /tmp/new-password
+RESTIC_PASSWORD="$RESTIC_OLD_PASSWORD" restic -r "$RESTIC_REPOSITORY" key add --new-password-file /tmp/new-password
+rm -f /tmp/new-password
+echo "Added new key to $RESTIC_REPOSITORY" >&2
+`
+	}
+
+	return `set -euo pipefail
+# The key list marks the key used to open the repository with a leading "*"
+OLD_KEY=$(RESTIC_PASSWORD="$RESTIC_OLD_PASSWORD" restic -r "$RESTIC_REPOSITORY" key list 2>/dev/null | awk '/^\*/ {print substr($1, 2)}' || true)
+if [ -z "$OLD_KEY" ]; then
+  echo "Old key already removed from $RESTIC_REPOSITORY" >&2
+  exit 0
+fi
+RESTIC_PASSWORD="$RESTIC_NEW_PASSWORD" restic -r "$RESTIC_REPOSITORY" key remove "$OLD_KEY"
+echo "Removed old key $OLD_KEY from $RESTIC_REPOSITORY" >&2
+`
+}
+
+func passwordEnv(name, secretName string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  ResticPasswordKey,
+			},
+		},
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Event reasons emitted on BackupPolicies and the PVCs they protect
+const (
+	EventReasonBackupStarted          = "BackupStarted"
+	EventReasonBackupCompleted        = "BackupCompleted"
+	EventReasonBackupFailed           = "BackupFailed"
+	EventReasonRetentionDeleted       = "RetentionDeleted"
+	EventReasonStuckJobKilled         = "StuckJobKilled"
+	EventReasonCredentialsCopied      = "CredentialsCopied"
+	EventReasonCredentialsSynced      = "CredentialsSynced"
+	EventReasonCredentialsDeleted     = "CredentialsDeleted"
+	EventReasonCredentialsConflict    = "CredentialsConflict"
+	EventReasonBackupDeleted          = "BackupDeleted"
+	EventReasonDeleteFailed           = "DeleteFailed"
+	EventReasonBackupsRetained        = "BackupsRetained"
+	EventReasonBackupsPurged          = "BackupsPurged"
+	EventReasonReplicationStarted     = "ReplicationStarted"
+	EventReasonReplicated             = "Replicated"
+	EventReasonReplicationFailed      = "ReplicationFailed"
+	EventReasonDestinationUnreachable = "DestinationUnreachable"
+	EventReasonManifestExportFailed   = "ManifestExportFailed"
+	EventReasonPolicyGenerated        = "PolicyGenerated"
+	EventReasonPolicyRemoved          = "PolicyRemoved"
+	EventReasonPolicyConflict         = "PolicyConflict"
+	EventReasonCatalogSynced          = "CatalogSynced"
+	EventReasonCatalogFailed          = "CatalogFailed"
+	EventReasonRestoreStarted         = "RestoreStarted"
+	EventReasonRestoreFailed          = "RestoreFailed"
+)
+
+// RecordEvent emits an event on each non-nil object; a nil recorder disables events (e.g., in unit tests)
+func RecordEvent(recorder record.EventRecorder, eventType, reason, message string, objects ...runtime.Object) {
+	if recorder == nil {
+		return
+	}
+	for _, obj := range objects {
+		if obj == nil {
+			continue
+		}
+		recorder.Event(obj, eventType, reason, message)
+	}
+}
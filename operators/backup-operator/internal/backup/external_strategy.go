@@ -26,10 +26,12 @@ import (
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -37,27 +39,57 @@ import (
 	"github.com/example/backup-operator/internal/storage"
 )
 
-const managedSecretNamespaceLabel = "backup.backup.example.com/namespace"
+const (
+	// ResticPasswordKey is the Secret key holding the restic repository password
+	ResticPasswordKey = "restic-password"
+
+	// Deadline of backup Jobs of policies without spec.activeDeadlineSeconds
+	defaultActiveDeadlineSeconds = int64(1800)
+)
 
 // ExternalStrategy implements backup using external storage (S3, NFS, etc.)
 type ExternalStrategy struct {
-	client  client.Client
-	backend storage.Backend
+	client   client.Client
+	backend  storage.Backend
+	recorder record.EventRecorder
+	// Operator-wide throttle for policies that leave spec.throttle fields unset
+	defaultThrottle *backupv1alpha1.Throttle
 }
 
-// NewExternalStrategy creates a new external storage backup strategy
-func NewExternalStrategy(c client.Client, backend storage.Backend) Strategy {
-	return &ExternalStrategy{client: c, backend: backend}
+// NewExternalStrategy creates a new external storage backup strategy; defaultThrottle may be nil
+func NewExternalStrategy(c client.Client, backend storage.Backend, recorder record.EventRecorder, defaultThrottle *backupv1alpha1.Throttle) Strategy {
+	return &ExternalStrategy{client: c, backend: backend, recorder: recorder, defaultThrottle: defaultThrottle}
 }
 
 // Backup creates a backup Job that uploads PVC data to external storage
 func (e *ExternalStrategy) Backup(ctx context.Context, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy) (*BackupResult, error) {
 	logger := log.FromContext(ctx)
 
+	if err := e.validatePasswordSecret(ctx, policy); err != nil {
+		return nil, err
+	}
+
 	if err := e.ensureCredentialsSecret(ctx, pvc.Namespace, policy); err != nil {
 		return nil, err
 	}
 
+	if err := e.validateServiceAccount(ctx, pvc.Namespace, policy); err != nil {
+		return nil, err
+	}
+
+	if policy.Spec.Dump != nil {
+		if err := e.validateDump(ctx, pvc, policy); err != nil {
+			return nil, err
+		}
+	}
+
+	// restic cannot set encryption or retention per object, so the bucket defaults have to provide them
+	if verifier, ok := e.backend.(storage.ProtectionVerifier); ok {
+		if err := verifier.VerifyProtection(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	repoURL, err := e.repositoryURL(policy, pvc)
 	if err != nil {
 		return nil, err
@@ -66,18 +98,36 @@ func (e *ExternalStrategy) Backup(ctx context.Context, pvc *corev1.PersistentVol
 	backupName := fmt.Sprintf("%s-%s-%s", policy.Name, pvc.Name, time.Now().Format("20060102-150405"))
 	logger.Info("Creating backup Job for external storage", "job", backupName, "pvc", pvc.Name, "namespace", pvc.Namespace, "repo", repoURL)
 
+	var manifestsKey string
+	if policy.Spec.Manifests != nil {
+		// The volume data matters more than the objects around it, so the backup goes ahead without them
+		if manifestsKey, err = e.exportManifests(ctx, backupName, pvc, policy); err != nil {
+			logger.Error(err, "Failed to export manifests", "pvc", pvc.Name, "namespace", pvc.Namespace)
+			RecordEvent(e.recorder, corev1.EventTypeWarning, EventReasonManifestExportFailed,
+				fmt.Sprintf("Failed to export manifests of PVC %s/%s: %v", pvc.Namespace, pvc.Name, err), policy)
+		}
+	}
+
 	job := e.buildBackupJob(backupName, pvc, policy, repoURL)
+	if policy.Spec.Dump != nil {
+		job = e.buildDumpJob(backupName, pvc, policy, repoURL)
+	}
 	if err := e.client.Create(ctx, job); err != nil {
 		return nil, fmt.Errorf("failed to create backup Job %s/%s: %w", pvc.Namespace, backupName, err)
 	}
 
 	sizeQty := pvc.Status.Capacity[corev1.ResourceStorage]
+	if policy.Spec.Dump != nil {
+		// The dump size is only known from the restic summary once the Job finishes
+		sizeQty = resource.Quantity{}
+	}
 	result := &BackupResult{
-		Name:      backupName,
-		Location:  repoURL,
-		Timestamp: time.Now(),
-		SizeBytes: sizeQty.Value(),
-		Size:      humanReadableQuantity(sizeQty),
+		Name:         backupName,
+		Location:     repoURL,
+		Timestamp:    time.Now(),
+		SizeBytes:    sizeQty.Value(),
+		Size:         humanReadableQuantity(sizeQty),
+		ManifestsKey: manifestsKey,
 		Metadata: map[string]string{
 			"pvc":         pvc.Name,
 			"namespace":   pvc.Namespace,
@@ -86,6 +136,9 @@ func (e *ExternalStrategy) Backup(ctx context.Context, pvc *corev1.PersistentVol
 			"destination": policy.Spec.Destination.Type,
 		},
 	}
+	if policy.Spec.Dump != nil {
+		result.Metadata["dump"] = policy.Spec.Dump.Engine
+	}
 
 	return result, nil
 }
@@ -93,10 +146,8 @@ func (e *ExternalStrategy) Backup(ctx context.Context, pvc *corev1.PersistentVol
 // buildBackupJob creates a Kubernetes Job for backing up PVC to external storage
 func (e *ExternalStrategy) buildBackupJob(backupName string, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy, repoURL string) *batchv1.Job {
 	backoffLimit := int32(3)
-	// Auto-cleanup 60 seconds after completion (changed from 10 minutes to 1 minute)
-	ttlSecondsAfterFinished := int32(60)
-	// Job runs for at most 30 minutes to avoid excessive load from frequent retries
-	activeDeadlineSeconds := int64(1800)
+	// Finished Jobs are removed by the controller according to the policy's history limits
+	activeDeadlineSeconds := ActiveDeadlineSeconds(policy)
 
 	labels := map[string]string{
 		LabelPolicy:          policy.Name,
@@ -112,9 +163,8 @@ func (e *ExternalStrategy) buildBackupJob(backupName string, pvc *corev1.Persist
 			Labels:    labels,
 		},
 		Spec: batchv1.JobSpec{
-			BackoffLimit:            &backoffLimit,
-			TTLSecondsAfterFinished: &ttlSecondsAfterFinished,
-			ActiveDeadlineSeconds:   &activeDeadlineSeconds,
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
@@ -122,7 +172,8 @@ func (e *ExternalStrategy) buildBackupJob(backupName string, pvc *corev1.Persist
 					},
 				},
 				Spec: corev1.PodSpec{
-					RestartPolicy: corev1.RestartPolicyOnFailure,
+					ServiceAccountName: jobServiceAccountName(policy),
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
 					Containers: []corev1.Container{
 						{
 							Name:    "backup",
@@ -135,7 +186,8 @@ func (e *ExternalStrategy) buildBackupJob(backupName string, pvc *corev1.Persist
 									ReadOnly:  true,
 								},
 							},
-							Env: e.buildBackupEnv(policy, repoURL),
+							Env:                      e.buildBackupEnv(policy, repoURL),
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 							Resources: corev1.ResourceRequirements{
 								Requests: corev1.ResourceList{
 									corev1.ResourceCPU:    resource.MustParse("250m"),
@@ -164,6 +216,8 @@ func (e *ExternalStrategy) buildBackupJob(backupName string, pvc *corev1.Persist
 		},
 	}
 
+	applyThrottle(&job.Spec.Template.Spec.Containers[0], e.throttle(policy))
+
 	if policy.Namespace == pvc.Namespace {
 		job.OwnerReferences = []metav1.OwnerReference{*ownerReferenceFor(policy, pvc.Namespace)}
 	}
@@ -171,6 +225,28 @@ func (e *ExternalStrategy) buildBackupJob(backupName string, pvc *corev1.Persist
 	return job
 }
 
+// CheckDestination probes the destination with the operator's credentials and verifies its protection settings.
+// Object lock keeps every version of a written object, so locked buckets only get a read probe.
+func (e *ExternalStrategy) CheckDestination(ctx context.Context, policy *backupv1alpha1.BackupPolicy) error {
+	probeKey := storage.ProbeKey(policy.Namespace + "." + policy.Name)
+	if err := storage.Probe(ctx, e.backend, probeKey, policy.Spec.Destination.ObjectLock == nil); err != nil {
+		return err
+	}
+	if verifier, ok := e.backend.(storage.ProtectionVerifier); ok {
+		return verifier.VerifyProtection(ctx)
+	}
+	return nil
+}
+
+// ActiveDeadlineSeconds returns the deadline of the policy's backup Jobs
+func ActiveDeadlineSeconds(policy *backupv1alpha1.BackupPolicy) int64 {
+	if policy.Spec.ActiveDeadlineSeconds != nil {
+		return *policy.Spec.ActiveDeadlineSeconds
+	}
+	// Long enough for most volumes while bounding the load of retries
+	return defaultActiveDeadlineSeconds
+}
+
 // buildBackupCommand generates the backup command executed inside the Job pod
 func (e *ExternalStrategy) buildBackupCommand(backupName string, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy, repoURL string) string {
 	return fmt.Sprintf(`set -euo pipefail
@@ -180,10 +256,21 @@ export RESTIC_TAG_PVC="pvc:%s"
 export RESTIC_TAG_NAMESPACE="namespace:%s"
 
 echo "Starting backup %s" >&2
-restic -r "$RESTIC_REPOSITORY" init >/dev/null 2>&1 || true
-restic -r "$RESTIC_REPOSITORY" backup /data --tag "$RESTIC_TAG_POLICY" --tag "$RESTIC_TAG_PVC" --tag "$RESTIC_TAG_NAMESPACE" --hostname "%s"
+%s
+%s
+restic -r "$RESTIC_REPOSITORY" ${RESTIC_OPTIONS:-} backup /data --tag "$RESTIC_TAG_POLICY" --tag "$RESTIC_TAG_PVC" --tag "$RESTIC_TAG_NAMESPACE" --tag "backup:%s" --hostname "%s" --json --quiet >/tmp/backup.json
+awk '/"message_type":"summary"/ { summary = $0 } END { print summary }' /tmp/backup.json >/tmp/summary.json
+cat /tmp/summary.json >&2
+
+%s
+
+# The controller records the summary from the termination message in the Backup status
+cp /tmp/summary.json /dev/termination-log
+`, repoURL, policy.Name, pvc.Name, pvc.Namespace, backupName, repositoryInitScript, ioniceScript, backupName, pvc.Namespace, retentionScript)
+}
 
-if [ -n "${RETENTION_MAX_BACKUPS:-}" ] || [ -n "${RETENTION_MAX_AGE:-}" ]; then
+// retentionScript forgets and prunes the snapshots outside the RETENTION_* limits, if any are set
+const retentionScript = `if [ -n "${RETENTION_MAX_BACKUPS:-}" ] || [ -n "${RETENTION_MAX_AGE:-}" ]; then
   ARGS=""
   if [ -n "${RETENTION_MAX_BACKUPS:-}" ]; then
     ARGS="$ARGS --keep-last ${RETENTION_MAX_BACKUPS}"
@@ -191,11 +278,66 @@ if [ -n "${RETENTION_MAX_BACKUPS:-}" ] || [ -n "${RETENTION_MAX_AGE:-}" ]; then
   if [ -n "${RETENTION_MAX_AGE:-}" ]; then
     ARGS="$ARGS --keep-within ${RETENTION_MAX_AGE}"
   fi
-  restic -r "$RESTIC_REPOSITORY" forget $ARGS --prune
-fi
-`, repoURL, policy.Name, pvc.Name, pvc.Namespace, backupName, pvc.Namespace)
+  restic -r "$RESTIC_REPOSITORY" ${RESTIC_OPTIONS:-} forget $ARGS --prune
+fi`
+
+// resticOptions returns the global restic options for the destination's storage class and transfer limits.
+// The throttle limits take precedence over the destination's bandwidth limit.
+func resticOptions(dest backupv1alpha1.Destination, throttle *backupv1alpha1.Throttle) string {
+	var options []string
+	if dest.Type == "s3" && dest.StorageClass != "" {
+		options = append(options, "-o s3.storage-class="+dest.StorageClass)
+	}
+	var upload, download *resource.Quantity
+	if transfer := dest.Transfer; transfer != nil {
+		if transfer.Concurrency > 0 {
+			options = append(options, fmt.Sprintf("-o %s.connections=%d", dest.Type, transfer.Concurrency))
+		}
+		upload, download = transfer.BandwidthLimit, transfer.BandwidthLimit
+	}
+	if throttle != nil {
+		if throttle.UploadLimit != nil {
+			upload = throttle.UploadLimit
+		}
+		if throttle.DownloadLimit != nil {
+			download = throttle.DownloadLimit
+		}
+	}
+	// restic limits bandwidth in KiB/s
+	if upload != nil && upload.Value() > 0 {
+		options = append(options, fmt.Sprintf("--limit-upload %d", max(upload.Value()/1024, 1)))
+	}
+	if download != nil && download.Value() > 0 {
+		options = append(options, fmt.Sprintf("--limit-download %d", max(download.Value()/1024, 1)))
+	}
+	return strings.Join(options, " ")
 }
 
+// reportScript defines report, which writes a failure to the termination log and exits
+const reportScript = `report() {
+  echo "$1" | tee /dev/termination-log >&2
+  exit 1
+}`
+
+// repositoryInitScript opens the restic repository and initialises it only when it does not exist yet.
+// Failures are written to the termination log so the controller can report them in status.
+// RESTIC_INIT_ARGS passes extra init flags, e.g. to copy the chunker parameters of a source repository.
+// Exit codes 10 (repository does not exist) and 12 (wrong password) require restic >= 0.17.
+const repositoryInitScript = reportScript + `
+set +e
+restic -r "$RESTIC_REPOSITORY" cat config >/dev/null 2>/tmp/repo-check
+repo_status=$?
+set -e
+case "$repo_status" in
+  0) ;;
+  10)
+    echo "Initialising restic repository $RESTIC_REPOSITORY" >&2
+    restic -r "$RESTIC_REPOSITORY" init ${RESTIC_INIT_ARGS:-} >/dev/null 2>/tmp/repo-init || report "failed to initialise restic repository: $(tail -n 1 /tmp/repo-init)"
+    ;;
+  12) report "wrong restic password for repository $RESTIC_REPOSITORY" ;;
+  *) report "failed to open restic repository: $(tail -n 1 /tmp/repo-check)" ;;
+esac`
+
 // buildBackupEnv creates environment variables for the backup Job
 func (e *ExternalStrategy) buildBackupEnv(policy *backupv1alpha1.BackupPolicy, repoURL string) []corev1.EnvVar {
 	dest := policy.Spec.Destination
@@ -211,8 +353,7 @@ func (e *ExternalStrategy) buildBackupEnv(policy *backupv1alpha1.BackupPolicy, r
 		env = append(env, corev1.EnvVar{Name: "RETENTION_MAX_AGE", Value: policy.Spec.Retention.MaxAge})
 	}
 
-	if dest.CredentialsSecret != "" {
-		optional := true
+	if dest.CredentialsSecret != "" && !UsesDefaultCredentialChain(policy) {
 		env = append(env,
 			corev1.EnvVar{
 				Name: "AWS_ACCESS_KEY_ID",
@@ -232,26 +373,34 @@ func (e *ExternalStrategy) buildBackupEnv(policy *backupv1alpha1.BackupPolicy, r
 					},
 				},
 			},
-			corev1.EnvVar{
-				Name: "RESTIC_PASSWORD",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{Name: dest.CredentialsSecret},
-						Key:                  "restic-password",
-					},
-				},
-			},
-			corev1.EnvVar{
-				Name: "AWS_DEFAULT_REGION",
-				ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{Name: dest.CredentialsSecret},
-						Key:                  "region",
-						Optional:             &optional,
-					},
+		)
+	}
+	// The region may still come from the Secret when credentials come from the default chain
+	if dest.CredentialsSecret != "" {
+		optional := true
+		env = append(env, corev1.EnvVar{
+			Name: "AWS_DEFAULT_REGION",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: dest.CredentialsSecret},
+					Key:                  "region",
+					Optional:             &optional,
 				},
 			},
-		)
+		})
+	}
+	if options := resticOptions(dest, e.throttle(policy)); options != "" {
+		env = append(env, corev1.EnvVar{Name: "RESTIC_OPTIONS", Value: options})
+	}
+	if dest.Auth != nil && dest.Auth.RoleARN != "" {
+		env = append(env, corev1.EnvVar{Name: "RESTIC_AWS_ASSUME_ROLE_ARN", Value: dest.Auth.RoleARN})
+		if dest.Auth.ExternalID != "" {
+			env = append(env, corev1.EnvVar{Name: "RESTIC_AWS_ASSUME_ROLE_EXTERNAL_ID", Value: dest.Auth.ExternalID})
+		}
+	}
+
+	if passwordSecret := ResticPasswordSecret(policy); passwordSecret != "" {
+		env = append(env, passwordEnv("RESTIC_PASSWORD", passwordSecret))
 	}
 
 	if dest.Endpoint != "" {
@@ -270,28 +419,16 @@ func (e *ExternalStrategy) ListBackups(ctx context.Context, pvc *corev1.Persiste
 	return []backupv1alpha1.StoredBackup{}, nil
 }
 
-// DeleteBackup deletes metadata or artifacts recorded for a backup
-func (e *ExternalStrategy) DeleteBackup(ctx context.Context, backup *backupv1alpha1.StoredBackup, policy *backupv1alpha1.BackupPolicy) error {
-	if e.backend == nil || backup.Location == "" {
-		return nil
-	}
-	if err := e.backend.Delete(ctx, backup.Location); err != nil {
-		return fmt.Errorf("failed to delete backup object %s: %w", backup.Location, err)
-	}
-	return nil
-}
-
 // Cleanup relies on the Job's restic forget/prune logic
 func (e *ExternalStrategy) Cleanup(ctx context.Context, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy) error {
 	log.FromContext(ctx).Info("Cleanup for external strategy is handled inside backup Jobs", "pvc", pvc.Name)
+	// Manifests live outside the repository, so restic retention does not remove them
+	if policy.Spec.Manifests != nil && e.backend != nil {
+		return e.cleanupManifests(ctx, pvc, policy)
+	}
 	return nil
 }
 
-// Restore restores a backup from external storage (not yet implemented)
-func (e *ExternalStrategy) Restore(ctx context.Context, backup *backupv1alpha1.StoredBackup, targetPVC *corev1.PersistentVolumeClaim) error {
-	return fmt.Errorf("external restore not yet implemented")
-}
-
 func (e *ExternalStrategy) repositoryURL(policy *backupv1alpha1.BackupPolicy, pvc *corev1.PersistentVolumeClaim) (string, error) {
 	dest := policy.Spec.Destination
 	switch dest.Type {
@@ -318,44 +455,149 @@ func splitS3URL(raw string) (bucket string, prefix string) {
 	return bucket, prefix
 }
 
+// ManagedSecretLabels returns the labels of Secrets copied into PVC namespaces on behalf of the policy
+func ManagedSecretLabels(policy *backupv1alpha1.BackupPolicy) map[string]string {
+	return map[string]string{
+		LabelManaged:               "true",
+		LabelPolicy:                policy.Name,
+		LabelSecretSourceNamespace: policy.Namespace,
+	}
+}
+
+// IsManagedSecret reports whether a Secret is a copy created by the operator
+func IsManagedSecret(secret *corev1.Secret) bool {
+	return secret.Labels[LabelManaged] == "true" && secret.Labels[LabelSecretSourceNamespace] != ""
+}
+
+// SyncSecretCopy updates the data of a copied Secret from its source and reports whether it changed
+func SyncSecretCopy(copy, source *corev1.Secret) bool {
+	if equality.Semantic.DeepEqual(copy.Data, source.Data) {
+		return false
+	}
+	copy.Data = source.Data
+	return true
+}
+
+// UsesDefaultCredentialChain reports whether S3 credentials come from the AWS default chain instead of static keys
+func UsesDefaultCredentialChain(policy *backupv1alpha1.BackupPolicy) bool {
+	auth := policy.Spec.Destination.Auth
+	return auth != nil && auth.Mode == backupv1alpha1.AuthModeDefaultChain
+}
+
+// jobServiceAccountName returns the service account Jobs run as; empty means the namespace default
+func jobServiceAccountName(policy *backupv1alpha1.BackupPolicy) string {
+	if auth := policy.Spec.Destination.Auth; auth != nil {
+		return auth.ServiceAccountName
+	}
+	return ""
+}
+
+// validateServiceAccount fails early when the Job service account is missing, since the Job would never start a pod
+func (e *ExternalStrategy) validateServiceAccount(ctx context.Context, namespace string, policy *backupv1alpha1.BackupPolicy) error {
+	name := jobServiceAccountName(policy)
+	if name == "" {
+		return nil
+	}
+	if err := e.client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &corev1.ServiceAccount{}); err != nil {
+		return fmt.Errorf("failed to get service account %s/%s for backup Jobs: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// ResticPasswordSecret returns the Secret holding the restic repository password of the policy
+func ResticPasswordSecret(policy *backupv1alpha1.BackupPolicy) string {
+	if policy.Spec.Destination.EncryptionSecret != "" {
+		return policy.Spec.Destination.EncryptionSecret
+	}
+	return policy.Spec.Destination.CredentialsSecret
+}
+
+// validatePasswordSecret fails early when the restic password is missing instead of letting the Job fail later
+func (e *ExternalStrategy) validatePasswordSecret(ctx context.Context, policy *backupv1alpha1.BackupPolicy) error {
+	return validatePasswordSecret(ctx, e.client, policy.Namespace, ResticPasswordSecret(policy))
+}
+
+func validatePasswordSecret(ctx context.Context, c client.Client, namespace, name string) error {
+	if name == "" {
+		return fmt.Errorf("restic repository password is not configured: set destination.encryptionSecret")
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		return fmt.Errorf("failed to read encryption secret %s/%s: %w", namespace, name, err)
+	}
+	if len(secret.Data[ResticPasswordKey]) == 0 {
+		return fmt.Errorf("encryption secret %s/%s has no %q key", namespace, name, ResticPasswordKey)
+	}
+	return nil
+}
+
+// ensureCredentialsSecret copies the storage credentials and encryption Secrets into the target namespace
 func (e *ExternalStrategy) ensureCredentialsSecret(ctx context.Context, targetNamespace string, policy *backupv1alpha1.BackupPolicy) error {
-	dest := policy.Spec.Destination
-	if dest.CredentialsSecret == "" {
+	for _, name := range []string{policy.Spec.Destination.CredentialsSecret, ResticPasswordSecret(policy)} {
+		if err := e.ensureSecretCopy(ctx, name, targetNamespace, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ExternalStrategy) ensureSecretCopy(ctx context.Context, name string, targetNamespace string, policy *backupv1alpha1.BackupPolicy) error {
+	if name == "" {
 		return nil
 	}
 	if targetNamespace == policy.Namespace {
 		return nil
 	}
 
-	namespacedName := types.NamespacedName{Name: dest.CredentialsSecret, Namespace: targetNamespace}
+	source := &corev1.Secret{}
+	if err := e.client.Get(ctx, types.NamespacedName{Name: name, Namespace: policy.Namespace}, source); err != nil {
+		return fmt.Errorf("failed to read secret %s/%s: %w", policy.Namespace, name, err)
+	}
+
+	namespacedName := types.NamespacedName{Name: name, Namespace: targetNamespace}
 	existing := &corev1.Secret{}
 	if err := e.client.Get(ctx, namespacedName, existing); err == nil {
+		// Secrets created by users in the target namespace are left alone
+		if !IsManagedSecret(existing) {
+			return nil
+		}
+		// A copy of a same-named Secret from another namespace must not be overwritten with this policy's credentials
+		if from := existing.Labels[LabelSecretSourceNamespace]; from != policy.Namespace {
+			err := fmt.Errorf("secret %s/%s is a copy of the Secret in namespace %s, rename the Secret of this policy",
+				targetNamespace, name, from)
+			RecordEvent(e.recorder, corev1.EventTypeWarning, EventReasonCredentialsConflict, err.Error(), policy)
+			return err
+		}
+		if !SyncSecretCopy(existing, source) {
+			return nil
+		}
+		if err := e.client.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to sync secret %s/%s: %w", targetNamespace, name, err)
+		}
 		return nil
 	} else if !errors.IsNotFound(err) {
-		return fmt.Errorf("failed to check secret %s/%s: %w", targetNamespace, dest.CredentialsSecret, err)
-	}
-
-	source := &corev1.Secret{}
-	if err := e.client.Get(ctx, types.NamespacedName{Name: dest.CredentialsSecret, Namespace: policy.Namespace}, source); err != nil {
-		return fmt.Errorf("failed to read credentials secret %s/%s: %w", policy.Namespace, dest.CredentialsSecret, err)
+		return fmt.Errorf("failed to check secret %s/%s: %w", targetNamespace, name, err)
 	}
 
 	copy := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      dest.CredentialsSecret,
+			Name:      name,
 			Namespace: targetNamespace,
-			Labels: map[string]string{
-				LabelManaged:                "true",
-				LabelPolicy:                 policy.Name,
-				managedSecretNamespaceLabel: policy.Namespace,
-			},
+			Labels:    ManagedSecretLabels(policy),
 		},
 		Data: source.Data,
 		Type: source.Type,
 	}
 
-	if err := e.client.Create(ctx, copy); err != nil && !errors.IsAlreadyExists(err) {
-		return fmt.Errorf("failed to copy credentials secret to namespace %s: %w", targetNamespace, err)
+	if err := e.client.Create(ctx, copy); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to copy secret %s to namespace %s: %w", name, targetNamespace, err)
 	}
+
+	RecordEvent(e.recorder, corev1.EventTypeNormal, EventReasonCredentialsCopied,
+		fmt.Sprintf("Copied Secret %s to namespace %s", name, targetNamespace), policy)
 	return nil
 }
@@ -2,13 +2,16 @@ package backup
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
@@ -65,7 +68,8 @@ func TestEnsureCredentialsSecretCopiesSecret(t *testing.T) {
 	}
 
 	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(srcSecret, policy).Build()
-	strategy := &ExternalStrategy{client: fakeClient}
+	recorder := record.NewFakeRecorder(1)
+	strategy := &ExternalStrategy{client: fakeClient, recorder: recorder}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -89,4 +93,195 @@ func TestEnsureCredentialsSecretCopiesSecret(t *testing.T) {
 	if copied.Labels[LabelManaged] != "true" {
 		t.Fatalf("expected managed label on copied secret")
 	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, EventReasonCredentialsCopied) {
+			t.Fatalf("unexpected event %q", event)
+		}
+	default:
+		t.Fatalf("expected a CredentialsCopied event")
+	}
+
+	// A rotated source is propagated to the existing copy
+	srcSecret.Data["secret-key"] = []byte("rotated")
+	if err := fakeClient.Update(ctx, srcSecret); err != nil {
+		t.Fatalf("failed to update source secret: %v", err)
+	}
+	if err := strategy.ensureCredentialsSecret(ctx, "target", policy); err != nil {
+		t.Fatalf("ensureCredentialsSecret returned error: %v", err)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: "creds", Namespace: "target"}, copied); err != nil {
+		t.Fatalf("copied secret not found: %v", err)
+	}
+	if string(copied.Data["secret-key"]) != "rotated" {
+		t.Fatalf("expected copied secret to follow the source, got %q", copied.Data["secret-key"])
+	}
+}
+
+func TestEnsureCredentialsSecretKeepsCopiesOfOtherNamespaces(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add corev1 to scheme: %v", err)
+	}
+
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "control"},
+		Data:       map[string][]byte{"secret-key": []byte("control")},
+	}
+	other := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "other"}}
+	foreign := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "target", Labels: ManagedSecretLabels(other)},
+		Data:       map[string][]byte{"secret-key": []byte("other")},
+	}
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "control"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Destination: backupv1alpha1.Destination{Type: "s3", CredentialsSecret: "creds"},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source, foreign).Build()
+	recorder := record.NewFakeRecorder(1)
+	strategy := &ExternalStrategy{client: fakeClient, recorder: recorder}
+
+	if err := strategy.ensureCredentialsSecret(context.Background(), "target", policy); err == nil {
+		t.Fatalf("expected a conflict with the copy from namespace other")
+	}
+
+	copied := &corev1.Secret{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: "creds", Namespace: "target"}, copied); err != nil {
+		t.Fatalf("copied secret not found: %v", err)
+	}
+	if string(copied.Data["secret-key"]) != "other" {
+		t.Fatalf("expected the copy of namespace other to be left alone, got %q", copied.Data["secret-key"])
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, EventReasonCredentialsConflict) {
+			t.Fatalf("unexpected event %q", event)
+		}
+	default:
+		t.Fatalf("expected a CredentialsConflict event")
+	}
+}
+
+func TestBackupCommandInitialisesRepositoryExplicitly(t *testing.T) {
+	strategy := &ExternalStrategy{}
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "control"}}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "target"}}
+
+	command := strategy.buildBackupCommand("backup", pvc, policy, "s3:bucket/repo")
+	if strings.Contains(command, "|| true") {
+		t.Fatalf("repository initialisation errors must not be swallowed:\n%s", command)
+	}
+	if !strings.Contains(command, "/dev/termination-log") {
+		t.Fatalf("expected initialisation failures to be reported via the termination log:\n%s", command)
+	}
+}
+
+func TestBuildBackupEnvUsesEncryptionSecret(t *testing.T) {
+	strategy := &ExternalStrategy{}
+	policy := &backupv1alpha1.BackupPolicy{}
+	policy.Spec.Destination = backupv1alpha1.Destination{
+		Type:              "s3",
+		CredentialsSecret: "creds",
+		EncryptionSecret:  "restic-key",
+	}
+
+	for _, env := range strategy.buildBackupEnv(policy, "s3:bucket/repo") {
+		if env.Name != "RESTIC_PASSWORD" {
+			continue
+		}
+		if ref := env.ValueFrom.SecretKeyRef; ref.Name != "restic-key" || ref.Key != ResticPasswordKey {
+			t.Fatalf("expected RESTIC_PASSWORD from restic-key/%s, got %s/%s", ResticPasswordKey, ref.Name, ref.Key)
+		}
+		return
+	}
+	t.Fatalf("expected RESTIC_PASSWORD env var")
+}
+
+func TestBuildBackupEnvWithDefaultCredentialChain(t *testing.T) {
+	strategy := &ExternalStrategy{}
+	policy := &backupv1alpha1.BackupPolicy{}
+	policy.Spec.Destination = backupv1alpha1.Destination{
+		Type:              "s3",
+		CredentialsSecret: "creds",
+		Auth: &backupv1alpha1.DestinationAuth{
+			Mode:               backupv1alpha1.AuthModeDefaultChain,
+			ServiceAccountName: "backup-irsa",
+			RoleARN:            "arn:aws:iam::123456789012:role/backup",
+			ExternalID:         "tenant-a",
+		},
+	}
+
+	env := map[string]corev1.EnvVar{}
+	for _, e := range strategy.buildBackupEnv(policy, "s3:bucket/repo") {
+		env[e.Name] = e
+	}
+	if _, found := env["AWS_ACCESS_KEY_ID"]; found {
+		t.Errorf("expected no static access key with the default credential chain")
+	}
+	if _, found := env["AWS_DEFAULT_REGION"]; !found {
+		t.Errorf("expected region to still be read from the credentials Secret")
+	}
+	if env["RESTIC_AWS_ASSUME_ROLE_ARN"].Value != policy.Spec.Destination.Auth.RoleARN {
+		t.Errorf("expected assume role ARN, got %q", env["RESTIC_AWS_ASSUME_ROLE_ARN"].Value)
+	}
+	if env["RESTIC_AWS_ASSUME_ROLE_EXTERNAL_ID"].Value != "tenant-a" {
+		t.Errorf("expected external ID, got %q", env["RESTIC_AWS_ASSUME_ROLE_EXTERNAL_ID"].Value)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "apps"}}
+	job := strategy.buildBackupJob("policy-data-1", pvc, policy, "s3:bucket/repo")
+	if got := job.Spec.Template.Spec.ServiceAccountName; got != "backup-irsa" {
+		t.Errorf("expected Job to run as backup-irsa, got %q", got)
+	}
+}
+
+func TestResticOptions(t *testing.T) {
+	limit := resource.MustParse("10Mi")
+	dest := backupv1alpha1.Destination{
+		Type:         "s3",
+		StorageClass: "STANDARD_IA",
+		Transfer: &backupv1alpha1.TransferOptions{
+			Concurrency:    8,
+			BandwidthLimit: &limit,
+		},
+	}
+
+	want := "-o s3.storage-class=STANDARD_IA -o s3.connections=8 --limit-upload 10240 --limit-download 10240"
+	if got := resticOptions(dest, nil); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	upload := resource.MustParse("1Mi")
+	want = "-o s3.storage-class=STANDARD_IA -o s3.connections=8 --limit-upload 1024 --limit-download 10240"
+	if got := resticOptions(dest, &backupv1alpha1.Throttle{UploadLimit: &upload}); got != want {
+		t.Fatalf("expected the throttle upload limit to take precedence, want %q, got %q", want, got)
+	}
+}
+
+func TestValidatePasswordSecretRequiresPassword(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add corev1 to scheme: %v", err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "control"},
+		Data:       map[string][]byte{"access-key": []byte("id")},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	strategy := &ExternalStrategy{client: fakeClient}
+
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "control"}}
+	if err := strategy.validatePasswordSecret(context.Background(), policy); err == nil {
+		t.Fatalf("expected error when no password secret is configured")
+	}
+
+	policy.Spec.Destination.CredentialsSecret = "creds"
+	if err := strategy.validatePasswordSecret(context.Background(), policy); err == nil {
+		t.Fatalf("expected error when the secret has no restic-password key")
+	}
 }
@@ -18,12 +18,19 @@ package backup
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 )
 
+// ErrDeletionInProgress is returned by DeleteBackup while an asynchronous deletion has not finished yet
+var ErrDeletionInProgress = errors.New("backup deletion in progress")
+
+// ErrDeletionFailed is returned by DeleteBackup when an attempt failed; calling it again starts a new attempt
+var ErrDeletionFailed = errors.New("backup deletion failed")
+
 // BackupResult contains the result of a backup operation
 type BackupResult struct {
 	// Name of the backup (snapshot name, file name, etc.)
@@ -36,6 +43,8 @@ type BackupResult struct {
 	Size string
 	// Backup timestamp
 	Timestamp time.Time
+	// Key of the exported Kubernetes manifests in the destination, if any
+	ManifestsKey string
 	// Additional metadata
 	Metadata map[string]string
 }
@@ -48,12 +57,65 @@ type Strategy interface {
 	// ListBackups lists all backups for the given PVC
 	ListBackups(ctx context.Context, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy) ([]backupv1alpha1.StoredBackup, error)
 
-	// DeleteBackup deletes a specific backup
+	// DeleteBackup deletes the artifact of a specific backup; it may return ErrDeletionInProgress and must be called again
 	DeleteBackup(ctx context.Context, backup *backupv1alpha1.StoredBackup, policy *backupv1alpha1.BackupPolicy) error
 
 	// Cleanup removes old backups according to retention policy
 	Cleanup(ctx context.Context, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy) error
 
-	// Restore restores a backup to a PVC (optional, for future implementation)
-	Restore(ctx context.Context, backup *backupv1alpha1.StoredBackup, targetPVC *corev1.PersistentVolumeClaim) error
+	// Restore starts restoring a backup into targetPVC, creating the claim when it does not exist yet
+	Restore(ctx context.Context, backup *backupv1alpha1.StoredBackup, policy *backupv1alpha1.BackupPolicy, targetPVC *corev1.PersistentVolumeClaim) error
+}
+
+// RepositoryRemover is implemented by strategies that keep storage per policy besides the backups themselves
+type RepositoryRemover interface {
+	// RemoveRepositories deletes the given repositories of the policy, named <namespace>/<pvc>, that no longer
+	// hold any backup
+	RemoveRepositories(ctx context.Context, policy *backupv1alpha1.BackupPolicy, repositories []string) error
+}
+
+// Verifier is implemented by strategies that can prove a stored backup is restorable
+type Verifier interface {
+	// Verify starts an asynchronous verification of the given backup and returns the name of the Job running it
+	Verify(ctx context.Context, backup *backupv1alpha1.Backup, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy) (string, error)
+}
+
+// KeyRotator is implemented by strategies whose repositories are protected by a rotatable encryption key
+type KeyRotator interface {
+	// RotateKey starts one phase of a key rotation for the PVC's repository and returns the name of the Job running it
+	RotateKey(ctx context.Context, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy, oldSecret, newSecret, phase string) (string, error)
+}
+
+// Detacher is implemented by strategies whose artifacts are owned by the policy and would be garbage collected with it
+type Detacher interface {
+	// Detach removes the policy's owner references from its artifacts in the given namespaces
+	Detach(ctx context.Context, policy *backupv1alpha1.BackupPolicy, namespaces []string) error
+}
+
+// Replicator is implemented by strategies that can copy completed backups to the policy's replica destinations
+type Replicator interface {
+	// Replicate starts copying the backup to the replica; the result names the Job running it and the replica location
+	Replicate(ctx context.Context, backup *backupv1alpha1.Backup, policy *backupv1alpha1.BackupPolicy, replica *backupv1alpha1.Replica) (*BackupResult, error)
+}
+
+// DestinationChecker is implemented by strategies that store backups outside the cluster
+type DestinationChecker interface {
+	// CheckDestination returns an error describing why the policy's destination cannot be used
+	CheckDestination(ctx context.Context, policy *backupv1alpha1.BackupPolicy) error
+}
+
+// Cataloguer is implemented by strategies whose repositories can be imported by a BackupRepository,
+// e.g. in another cluster after a disaster
+type Cataloguer interface {
+	// DiscoverRepositories lists the repositories found in the destination of the BackupRepository
+	DiscoverRepositories(ctx context.Context, repository *backupv1alpha1.BackupRepository) ([]SourceRepository, error)
+
+	// ListSnapshots returns the sorted IDs of all snapshots of a repository
+	ListSnapshots(ctx context.Context, source SourceRepository) ([]string, error)
+
+	// Catalog starts describing a batch of the given snapshots of a repository and returns the name of the Job doing it
+	Catalog(ctx context.Context, repository *backupv1alpha1.BackupRepository, source SourceRepository, snapshotIDs []string) (string, error)
+
+	// ManifestsFor returns the key of the manifests exported with a backup, empty when there are none
+	ManifestsFor(ctx context.Context, source SourceRepository, backupName string) (string, error)
 }
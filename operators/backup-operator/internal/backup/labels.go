@@ -6,4 +6,50 @@ const (
 	LabelStrategy        = "backup.backup.example.com/strategy"
 	LabelPolicyNamespace = "backup.backup.example.com/policy-namespace"
 	LabelManaged         = "backup.backup.example.com/managed"
+	// LabelSecretSourceNamespace is set on copied Secrets to the namespace of the Secret they were copied from
+	LabelSecretSourceNamespace = "backup.backup.example.com/namespace"
+	// LabelVerifiedBackup is set on verification Jobs to the name of the backup being verified
+	LabelVerifiedBackup = "backup.backup.example.com/verified-backup"
+	// LabelKeyRotation is set on key rotation Jobs to the name of the Secret holding the new password
+	LabelKeyRotation = "backup.backup.example.com/key-rotation"
+	// LabelKeyRotationPhase is set on key rotation Jobs to the rotation step they perform
+	LabelKeyRotationPhase = "backup.backup.example.com/key-rotation-phase"
+	// LabelDeletedBackup is set on Jobs deleting the artifact of the named backup
+	LabelDeletedBackup = "backup.backup.example.com/deleted-backup"
+	// LabelReplicatedBackup is set on replication Jobs to the name of the backup being copied
+	LabelReplicatedBackup = "backup.backup.example.com/replicated-backup"
+	// LabelReplica is set on replication Jobs to the name of the replica the backup is copied to
+	LabelReplica = "backup.backup.example.com/replica"
+	// LabelRestoredBackup is set on restore Jobs and restored PVCs to the name of the backup being restored
+	LabelRestoredBackup = "backup.backup.example.com/restored-backup"
+	// LabelPolicyTemplate is set on annotated PVCs and on generated BackupPolicies to the name of the template
+	LabelPolicyTemplate = "backup.backup.example.com/policy-template"
+	// LabelRepository is set on imported Backups and catalogue Jobs to the name of their BackupRepository
+	LabelRepository = "backup.backup.example.com/repository"
+	// LabelSourcePolicy is set on imported Backups and catalogue Jobs to the policy that wrote the repository
+	LabelSourcePolicy = "backup.backup.example.com/source-policy"
+	// LabelSourceNamespace is set on imported Backups and catalogue Jobs to the namespace of the source PVC
+	LabelSourceNamespace = "backup.backup.example.com/source-namespace"
+
+	// BackupFinalizer keeps a Backup until its artifact has been deleted from backup storage
+	BackupFinalizer = "backup.backup.example.com/delete-artifact"
+	// PolicyFinalizer applies the deletion policy to a BackupPolicy's backups before it is removed
+	PolicyFinalizer = "backup.backup.example.com/deletion-policy"
+
+	// AnnotationRotateEncryptionKey requests rotation of the restic password to the named Secret
+	AnnotationRotateEncryptionKey = "backup.backup.example.com/rotate-encryption-key"
+	// AnnotationBackupNow requests a backup of all target PVCs outside the schedule; the value is the request time
+	AnnotationBackupNow = "backup.backup.example.com/backup-now"
+	// AnnotationVerifyNow requests a verification of the latest backups outside the verification schedule
+	AnnotationVerifyNow = "backup.backup.example.com/verify-now"
+	// AnnotationRestore requests a restore of a Backup; the value is a JSON RestoreRequest
+	AnnotationRestore = "backup.backup.example.com/restore"
+	// AnnotationSealed marks exported Secrets whose values are encrypted with the manifest key
+	AnnotationSealed = "backup.backup.example.com/sealed"
+	// AnnotationSealKey records the Secret holding the key an exported Secret was sealed with
+	AnnotationSealKey = "backup.backup.example.com/seal-key"
+	// AnnotationRestoredReplicas records the replicas of a workload re-created with zero replicas by a restore
+	AnnotationRestoredReplicas = "backup.backup.example.com/restored-replicas"
+	// AnnotationPolicyTemplate opts a PVC into the BackupPolicyTemplate it names
+	AnnotationPolicyTemplate = "backup.backup.example.com/policy-template"
 )
@@ -0,0 +1,608 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+// manifestsPrefix keeps exported manifests apart from the restic repositories under the destination URL
+const manifestsPrefix = ".manifests"
+
+// sealAlgorithm is recorded in the sealed annotation of exported Secrets
+const sealAlgorithm = "aes-256-gcm"
+
+// ManifestSealKeyKey is the key of the seal-key Secret holding the 32 byte manifest key
+const ManifestSealKeyKey = "key"
+
+// ManifestSealKeySecret returns the name of the Secret holding the key that seals exported Secrets
+func ManifestSealKeySecret(policy *backupv1alpha1.BackupPolicy) string {
+	if policy.Spec.Manifests != nil && policy.Spec.Manifests.SealKeySecret != "" {
+		return policy.Spec.Manifests.SealKeySecret
+	}
+	return policy.Name + "-manifests-key"
+}
+
+// manifestsDir returns the destination directory of the manifests exported for a PVC
+func manifestsDir(policyName, namespace, pvcName string) string {
+	return path.Join(manifestsPrefix, policyName, namespace, pvcName)
+}
+
+// exportManifests uploads the Kubernetes objects around a PVC as one YAML stream and returns its key
+func (e *ExternalStrategy) exportManifests(ctx context.Context, backupName string, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy) (string, error) {
+	if e.backend == nil {
+		return "", fmt.Errorf("manifest export needs an s3 destination")
+	}
+
+	objects, err := e.collectManifests(ctx, pvc, policy)
+	if err != nil {
+		return "", err
+	}
+	var seal *manifestSeal
+	if policy.Spec.Manifests.Secrets == backupv1alpha1.ManifestSecretsEncrypted {
+		if seal, err = e.ensureManifestSealKey(ctx, policy); err != nil {
+			return "", err
+		}
+	}
+	data, err := encodeManifests(objects, e.client.Scheme(), seal)
+	if err != nil {
+		return "", err
+	}
+
+	key := path.Join(manifestsDir(policy.Name, pvc.Namespace, pvc.Name), backupName+".yaml")
+	if err := e.backend.Upload(ctx, bytes.NewReader(data), key, map[string]string{"backup": backupName}); err != nil {
+		return "", fmt.Errorf("failed to upload manifests to %s: %w", key, err)
+	}
+	log.FromContext(ctx).Info("Exported manifests", "key", key, "objects", len(objects))
+	return key, nil
+}
+
+// collectManifests returns the PVC, the Deployments and StatefulSets using it, and the ConfigMaps,
+// Secrets and Services of their pods
+func (e *ExternalStrategy) collectManifests(ctx context.Context, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy) ([]client.Object, error) {
+	namespace := pvc.Namespace
+	objects := []client.Object{pvc.DeepCopy()}
+	var templates []*corev1.PodTemplateSpec
+
+	deployments := &appsv1.DeploymentList{}
+	if err := e.client.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list Deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if mountsClaim(&d.Spec.Template.Spec, pvc.Name) {
+			objects = append(objects, d)
+			templates = append(templates, &d.Spec.Template)
+		}
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := e.client.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list StatefulSets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		if mountsClaim(&s.Spec.Template.Spec, pvc.Name) || claimFromTemplate(s, pvc.Name) {
+			objects = append(objects, s)
+			templates = append(templates, &s.Spec.Template)
+		}
+	}
+
+	configMaps, secrets := referencedConfig(templates)
+	for _, name := range configMaps {
+		cm := &corev1.ConfigMap{}
+		if err := e.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, cm); err != nil {
+			// References may be optional
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, name, err)
+		}
+		objects = append(objects, cm)
+	}
+	if policy.Spec.Manifests.Secrets == backupv1alpha1.ManifestSecretsEncrypted || policy.Spec.Manifests.Secrets == backupv1alpha1.ManifestSecretsPlain {
+		for _, name := range secrets {
+			secret := &corev1.Secret{}
+			if err := e.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to get Secret %s/%s: %w", namespace, name, err)
+			}
+			// Token Secrets are issued by the cluster and credential copies by the operator
+			if secret.Type == corev1.SecretTypeServiceAccountToken || IsManagedSecret(secret) {
+				continue
+			}
+			objects = append(objects, secret)
+		}
+	}
+
+	services := &corev1.ServiceList{}
+	if err := e.client.List(ctx, services, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list Services: %w", err)
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(svc.Spec.Selector)
+		for _, template := range templates {
+			if selector.Matches(labels.Set(template.Labels)) {
+				objects = append(objects, svc)
+				break
+			}
+		}
+	}
+	return objects, nil
+}
+
+// mountsClaim reports whether a pod spec mounts the named PVC
+func mountsClaim(spec *corev1.PodSpec, claim string) bool {
+	for _, volume := range spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claim {
+			return true
+		}
+	}
+	return false
+}
+
+// claimFromTemplate reports whether the PVC was created from a volume claim template of the StatefulSet
+func claimFromTemplate(s *appsv1.StatefulSet, claim string) bool {
+	for _, template := range s.Spec.VolumeClaimTemplates {
+		ordinal, found := strings.CutPrefix(claim, template.Name+"-"+s.Name+"-")
+		if !found {
+			continue
+		}
+		if _, err := strconv.Atoi(ordinal); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedConfig returns the sorted names of the ConfigMaps and Secrets used by pod templates
+func referencedConfig(templates []*corev1.PodTemplateSpec) (configMaps, secrets []string) {
+	cms, scs := map[string]bool{}, map[string]bool{}
+	for _, template := range templates {
+		spec := &template.Spec
+		for _, volume := range spec.Volumes {
+			if volume.ConfigMap != nil {
+				cms[volume.ConfigMap.Name] = true
+			}
+			if volume.Secret != nil {
+				scs[volume.Secret.SecretName] = true
+			}
+			if volume.Projected != nil {
+				for _, source := range volume.Projected.Sources {
+					if source.ConfigMap != nil {
+						cms[source.ConfigMap.Name] = true
+					}
+					if source.Secret != nil {
+						scs[source.Secret.Name] = true
+					}
+				}
+			}
+		}
+		for _, ref := range spec.ImagePullSecrets {
+			scs[ref.Name] = true
+		}
+		for _, container := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+			for _, source := range container.EnvFrom {
+				if source.ConfigMapRef != nil {
+					cms[source.ConfigMapRef.Name] = true
+				}
+				if source.SecretRef != nil {
+					scs[source.SecretRef.Name] = true
+				}
+			}
+			for _, env := range container.Env {
+				if env.ValueFrom == nil {
+					continue
+				}
+				if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+					cms[ref.Name] = true
+				}
+				if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+					scs[ref.Name] = true
+				}
+			}
+		}
+	}
+	return sortedKeys(cms), sortedKeys(scs)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// encodeManifests writes objects as a YAML stream without cluster-assigned fields, sealing Secret values
+// when seal is set
+func encodeManifests(objects []client.Object, scheme *runtime.Scheme, seal *manifestSeal) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, obj := range objects {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return nil, err
+		}
+		if secret, ok := obj.(*corev1.Secret); ok && seal != nil {
+			if obj, err = sealSecret(secret, seal); err != nil {
+				return nil, err
+			}
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		u := &unstructured.Unstructured{Object: content}
+		u.SetGroupVersionKind(gvk)
+		cleanManifest(u)
+
+		data, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("---\n")
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// cleanManifest removes the fields the cluster assigns, so the object can be created again elsewhere
+func cleanManifest(u *unstructured.Unstructured) {
+	for _, field := range []string{"namespace", "uid", "resourceVersion", "generation", "creationTimestamp",
+		"deletionTimestamp", "deletionGracePeriodSeconds", "managedFields", "ownerReferences", "finalizers", "selfLink"} {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(u.Object, "status")
+
+	annotations := u.GetAnnotations()
+	for key := range annotations {
+		if key == "kubectl.kubernetes.io/last-applied-configuration" || strings.HasPrefix(key, "pv.kubernetes.io/") ||
+			strings.HasPrefix(key, "volume.kubernetes.io/") || strings.HasPrefix(key, "volume.beta.kubernetes.io/") ||
+			strings.HasPrefix(key, "deployment.kubernetes.io/") {
+			delete(annotations, key)
+		}
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(u.Object, "metadata", "annotations")
+	} else {
+		u.SetAnnotations(annotations)
+	}
+
+	switch u.GetKind() {
+	case "Service":
+		unstructured.RemoveNestedField(u.Object, "spec", "clusterIP")
+		unstructured.RemoveNestedField(u.Object, "spec", "clusterIPs")
+	case "PersistentVolumeClaim":
+		unstructured.RemoveNestedField(u.Object, "spec", "volumeName")
+	}
+}
+
+// decodeManifests parses a YAML stream written by encodeManifests
+func decodeManifests(data []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, doc := range strings.Split(string(data), "\n---\n") {
+		doc = strings.TrimPrefix(strings.TrimSpace(doc), "---")
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		content, err := yaml.YAMLToJSON([]byte(doc))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		// The unstructured decoder keeps integers as int64, unlike a plain map
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(content); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		objects = append(objects, u)
+	}
+	return objects, nil
+}
+
+// applyManifests creates the exported objects that do not exist in the namespace of the restore target.
+// Workloads start with zero replicas so they do not use the volume while it is restored; the original
+// count is kept in an annotation. PVCs are not re-created, the restore target takes their place, and
+// workload volumes claiming the backed up PVC are pointed at the target.
+func (e *ExternalStrategy) applyManifests(ctx context.Context, backup *backupv1alpha1.StoredBackup, policy *backupv1alpha1.BackupPolicy, targetPVC *corev1.PersistentVolumeClaim) error {
+	logger := log.FromContext(ctx)
+
+	reader, err := e.backend.Download(ctx, backup.Manifests)
+	if err != nil {
+		return fmt.Errorf("failed to download manifests %s: %w", backup.Manifests, err)
+	}
+	defer func() { _ = reader.Close() }()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read manifests %s: %w", backup.Manifests, err)
+	}
+	objects, err := decodeManifests(data)
+	if err != nil {
+		return err
+	}
+
+	// Seal keys by Secret name; manifests exported by earlier versions have none recorded
+	sealKeys := map[string][]byte{}
+	for _, u := range objects {
+		u.SetNamespace(targetPVC.Namespace)
+		switch u.GetKind() {
+		case "PersistentVolumeClaim":
+			continue
+		case "Secret":
+			if u.GetAnnotations()[AnnotationSealed] == "" {
+				break
+			}
+			keySecret := u.GetAnnotations()[AnnotationSealKey]
+			key, found := sealKeys[keySecret]
+			if !found {
+				if keySecret == "" {
+					key, err = e.legacyManifestSealKey(ctx, policy)
+				} else {
+					key, err = e.manifestSealKey(ctx, policy.Namespace, keySecret)
+				}
+				if err != nil {
+					return err
+				}
+				sealKeys[keySecret] = key
+			}
+			if err := unsealSecret(u, key); err != nil {
+				return err
+			}
+		case "Deployment", "StatefulSet":
+			if targetPVC.Name != backup.PVCName {
+				if err := retargetClaim(u, backup.PVCName, targetPVC.Name); err != nil {
+					return err
+				}
+			}
+			replicas, found, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+			if !found {
+				replicas = 1
+			}
+			if err := unstructured.SetNestedField(u.Object, int64(0), "spec", "replicas"); err != nil {
+				return err
+			}
+			annotations := u.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[AnnotationRestoredReplicas] = strconv.FormatInt(replicas, 10)
+			u.SetAnnotations(annotations)
+		}
+
+		if err := e.client.Create(ctx, u); err != nil {
+			if errors.IsAlreadyExists(err) {
+				logger.Info("Keeping existing object", "kind", u.GetKind(), "name", u.GetName())
+				continue
+			}
+			return fmt.Errorf("failed to create %s %s/%s: %w", u.GetKind(), u.GetNamespace(), u.GetName(), err)
+		}
+		logger.Info("Re-created object from manifests", "kind", u.GetKind(), "name", u.GetName())
+	}
+	return nil
+}
+
+// deleteManifests removes the manifests exported with a backup
+func (e *ExternalStrategy) deleteManifests(ctx context.Context, backup *backupv1alpha1.StoredBackup) error {
+	if backup.Manifests == "" || e.backend == nil {
+		return nil
+	}
+	if err := e.backend.Delete(ctx, backup.Manifests); err != nil {
+		return fmt.Errorf("failed to delete manifests %s: %w", backup.Manifests, err)
+	}
+	return nil
+}
+
+// cleanupManifests deletes exported manifests of a PVC whose Backup no longer exists, e.g. after retention
+func (e *ExternalStrategy) cleanupManifests(ctx context.Context, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy) error {
+	infos, err := e.backend.List(ctx, manifestsDir(policy.Name, pvc.Namespace, pvc.Name)+"/")
+	if err != nil {
+		return fmt.Errorf("failed to list manifests: %w", err)
+	}
+	for _, info := range infos {
+		name := strings.TrimSuffix(path.Base(info.Path), ".yaml")
+		err := e.client.Get(ctx, types.NamespacedName{Namespace: pvc.Namespace, Name: name}, &backupv1alpha1.Backup{})
+		if err == nil {
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get Backup %s/%s: %w", pvc.Namespace, name, err)
+		}
+		if err := e.backend.Delete(ctx, info.Path); err != nil {
+			return fmt.Errorf("failed to delete manifests %s: %w", info.Path, err)
+		}
+		log.FromContext(ctx).Info("Deleted manifests of removed backup", "key", info.Path)
+	}
+	return nil
+}
+
+// retargetClaim points the pod volumes of a workload manifest that claim the PVC from at the PVC to
+func retargetClaim(u *unstructured.Unstructured, from, to string) error {
+	volumes, found, err := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "volumes")
+	if err != nil || !found {
+		return err
+	}
+	for _, volume := range volumes {
+		claim, ok := volume.(map[string]interface{})["persistentVolumeClaim"].(map[string]interface{})
+		if ok && claim["claimName"] == from {
+			claim["claimName"] = to
+		}
+	}
+	return unstructured.SetNestedSlice(u.Object, volumes, "spec", "template", "spec", "volumes")
+}
+
+// manifestSeal is the key exported Secrets are sealed with and the Secret holding it
+type manifestSeal struct {
+	key    []byte
+	secret string
+}
+
+// ensureManifestSealKey returns the seal key of the policy, creating its Secret with a random key when missing
+func (e *ExternalStrategy) ensureManifestSealKey(ctx context.Context, policy *backupv1alpha1.BackupPolicy) (*manifestSeal, error) {
+	name := ManifestSealKeySecret(policy)
+	key, err := e.manifestSealKey(ctx, policy.Namespace, name)
+	switch {
+	case err == nil:
+		return &manifestSeal{key: key, secret: name}, nil
+	case !errors.IsNotFound(err):
+		return nil, err
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: policy.Namespace},
+		Data:       map[string][]byte{ManifestSealKeyKey: key},
+	}
+	if err := e.client.Create(ctx, secret); err != nil {
+		if errors.IsAlreadyExists(err) {
+			// Created concurrently by another backup of the policy
+			if key, err = e.manifestSealKey(ctx, policy.Namespace, name); err != nil {
+				return nil, err
+			}
+			return &manifestSeal{key: key, secret: name}, nil
+		}
+		return nil, fmt.Errorf("failed to create manifest seal key %s/%s: %w", policy.Namespace, name, err)
+	}
+	log.FromContext(ctx).Info("Created manifest seal key", "secret", name, "namespace", policy.Namespace)
+	return &manifestSeal{key: key, secret: name}, nil
+}
+
+// manifestSealKey reads the key sealing exported Secret values from a seal-key Secret
+func (e *ExternalStrategy) manifestSealKey(ctx context.Context, namespace, name string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := e.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to read manifest seal key %s/%s: %w", namespace, name, err)
+	}
+	key := secret.Data[ManifestSealKeyKey]
+	if len(key) != 32 {
+		return nil, fmt.Errorf("manifest seal key %s/%s must hold 32 bytes under %q", namespace, name, ManifestSealKeyKey)
+	}
+	return key, nil
+}
+
+// legacyManifestSealKey derives the key that sealed Secrets exported before seal-key Secrets from the
+// current restic password of the policy
+func (e *ExternalStrategy) legacyManifestSealKey(ctx context.Context, policy *backupv1alpha1.BackupPolicy) ([]byte, error) {
+	name := ResticPasswordSecret(policy)
+	secret := &corev1.Secret{}
+	if err := e.client.Get(ctx, types.NamespacedName{Namespace: policy.Namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to read encryption secret %s/%s: %w", policy.Namespace, name, err)
+	}
+	password := secret.Data[ResticPasswordKey]
+	if len(password) == 0 {
+		return nil, fmt.Errorf("encryption secret %s/%s has no %q key", policy.Namespace, name, ResticPasswordKey)
+	}
+	key := sha256.Sum256(append([]byte("backup-operator manifests:"), password...))
+	return key[:], nil
+}
+
+// sealSecret returns a copy of the Secret with every value encrypted
+func sealSecret(secret *corev1.Secret, seal *manifestSeal) (*corev1.Secret, error) {
+	aead, err := newAEAD(seal.key)
+	if err != nil {
+		return nil, err
+	}
+	sealed := secret.DeepCopy()
+	sealed.StringData = nil
+	sealed.Data = make(map[string][]byte, len(secret.Data))
+	for k, v := range secret.Data {
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		sealed.Data[k] = aead.Seal(nonce, nonce, v, nil)
+	}
+	if sealed.Annotations == nil {
+		sealed.Annotations = map[string]string{}
+	}
+	sealed.Annotations[AnnotationSealed] = sealAlgorithm
+	sealed.Annotations[AnnotationSealKey] = seal.secret
+	return sealed, nil
+}
+
+// unsealSecret decrypts the values of a sealed Secret manifest in place
+func unsealSecret(u *unstructured.Unstructured, key []byte) error {
+	secret := &corev1.Secret{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, secret); err != nil {
+		return err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	for k, v := range secret.Data {
+		if len(v) < aead.NonceSize() {
+			return fmt.Errorf("sealed value %q of Secret %s is truncated", k, secret.Name)
+		}
+		plain, err := aead.Open(nil, v[:aead.NonceSize()], v[aead.NonceSize():], nil)
+		if err != nil {
+			return fmt.Errorf("failed to unseal Secret %s; it was sealed with a different key", secret.Name)
+		}
+		secret.Data[k] = plain
+	}
+	delete(secret.Annotations, AnnotationSealed)
+	delete(secret.Annotations, AnnotationSealKey)
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(secret)
+	if err != nil {
+		return err
+	}
+	u.Object = content
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,271 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/storage"
+)
+
+// objectBackend keeps uploaded objects in memory
+type objectBackend struct {
+	storage.Backend
+	objects map[string][]byte
+}
+
+func (b *objectBackend) Upload(_ context.Context, data io.Reader, path string, _ map[string]string) error {
+	content, err := io.ReadAll(data)
+	b.objects[path] = content
+	return err
+}
+
+func (b *objectBackend) Download(_ context.Context, path string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(b.objects[path])), nil
+}
+
+func (b *objectBackend) Delete(_ context.Context, path string) error {
+	delete(b.objects, path)
+	return nil
+}
+
+func (b *objectBackend) List(_ context.Context, prefix string) ([]storage.BackupInfo, error) {
+	var infos []storage.BackupInfo
+	for path := range b.objects {
+		if strings.HasPrefix(path, prefix) {
+			infos = append(infos, storage.BackupInfo{Path: path})
+		}
+	}
+	return infos, nil
+}
+
+func newManifestObjects() []runtime.Object {
+	replicas := int32(2)
+	return []runtime.Object{
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-db-0", Namespace: "apps", UID: "pvc-uid"}},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:             &replicas,
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name: "db",
+							EnvFrom: []corev1.EnvFromSource{
+								{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db-config"}}},
+								{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db-auth"}}},
+							},
+						}},
+					},
+				},
+			},
+		},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "apps"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "db-config", Namespace: "apps"}, Data: map[string]string{"mode": "primary"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db-auth", Namespace: "apps"}, Data: map[string][]byte{"password": []byte("hunter2")}},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "db"}, ClusterIP: "10.0.0.10"},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "control"},
+			Data:       map[string][]byte{ResticPasswordKey: []byte("pw")},
+		},
+	}
+}
+
+func TestManifestsExportAndRestore(t *testing.T) {
+	scheme := newRestoreScheme(t)
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "control"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Strategy:    "external",
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://bucket/backups", CredentialsSecret: "creds"},
+			Manifests:   &backupv1alpha1.Manifests{Secrets: backupv1alpha1.ManifestSecretsEncrypted},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(append(newManifestObjects(), policy)...).Build()
+	backend := &objectBackend{objects: map[string][]byte{}}
+	strategy := &ExternalStrategy{client: fakeClient, backend: backend}
+	pvc := &corev1.PersistentVolumeClaim{}
+	ctx := context.Background()
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "apps", Name: "data-db-0"}, pvc); err != nil {
+		t.Fatalf("failed to get PVC: %v", err)
+	}
+
+	result, err := strategy.Backup(ctx, pvc, policy)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	expectedKey := ".manifests/policy/apps/data-db-0/" + result.Name + ".yaml"
+	if result.ManifestsKey != expectedKey {
+		t.Fatalf("expected manifests at %q, got %q", expectedKey, result.ManifestsKey)
+	}
+	exported := string(backend.objects[expectedKey])
+	for _, want := range []string{"kind: PersistentVolumeClaim", "kind: StatefulSet", "kind: ConfigMap", "kind: Secret", "name: db-config"} {
+		if !strings.Contains(exported, want) {
+			t.Fatalf("expected %q in manifests:\n%s", want, exported)
+		}
+	}
+	for _, unwanted := range []string{"unrelated", "name: web", "10.0.0.10", "pvc-uid", "resourceVersion", "aHVudGVyMg=="} {
+		if strings.Contains(exported, unwanted) {
+			t.Fatalf("unexpected %q in manifests:\n%s", unwanted, exported)
+		}
+	}
+
+	sealKey := &corev1.Secret{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "control", Name: "policy-manifests-key"}, sealKey); err != nil {
+		t.Fatalf("expected the seal key Secret to be created: %v", err)
+	}
+	if !strings.Contains(exported, AnnotationSealKey+": policy-manifests-key") {
+		t.Fatalf("expected the seal key to be recorded in manifests:\n%s", exported)
+	}
+
+	// A key rotation of the restic repository does not affect sealed manifests
+	creds := &corev1.Secret{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "control", Name: "creds"}, creds); err != nil {
+		t.Fatalf("failed to get restic password: %v", err)
+	}
+	creds.Data[ResticPasswordKey] = []byte("rotated")
+	if err := fakeClient.Update(ctx, creds); err != nil {
+		t.Fatalf("failed to rotate restic password: %v", err)
+	}
+
+	// Restore into a namespace where the application was deleted
+	for _, obj := range []client.Object{
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "apps"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db-auth", Namespace: "apps"}},
+	} {
+		if err := fakeClient.Delete(ctx, obj); err != nil {
+			t.Fatalf("failed to delete object: %v", err)
+		}
+	}
+	stored := &backupv1alpha1.StoredBackup{
+		Name:      result.Name,
+		Namespace: "apps",
+		PVCName:   "data-db-0",
+		Location:  result.Location,
+		Status:    backupv1alpha1.BackupPhaseCompleted,
+		Manifests: result.ManifestsKey,
+	}
+	if err := strategy.Restore(ctx, stored, policy, restoreTargetPVC("data-db-0")); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "apps", Name: "db"}, sts); err != nil {
+		t.Fatalf("expected the StatefulSet to be re-created: %v", err)
+	}
+	if *sts.Spec.Replicas != 0 || sts.Annotations[AnnotationRestoredReplicas] != "2" {
+		t.Fatalf("expected the StatefulSet scaled to zero with its replicas recorded, got %d and %v", *sts.Spec.Replicas, sts.Annotations)
+	}
+	secret := &corev1.Secret{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "apps", Name: "db-auth"}, secret); err != nil {
+		t.Fatalf("expected the Secret to be re-created: %v", err)
+	}
+	if string(secret.Data["password"]) != "hunter2" || secret.Annotations[AnnotationSealed] != "" || secret.Annotations[AnnotationSealKey] != "" {
+		t.Fatalf("expected the unsealed Secret, got %v and %v", secret.Data, secret.Annotations)
+	}
+
+	// Deleting the Backup removes its manifests once the forget Job succeeded
+	job := &batchv1.Job{}
+	if err := strategy.DeleteBackup(ctx, stored, policy); err != ErrDeletionInProgress {
+		t.Fatalf("expected deletion to be in progress, got %v", err)
+	}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "apps", Name: stored.Name + "-forget"}, job); err != nil {
+		t.Fatalf("expected forget Job: %v", err)
+	}
+	job.Status.Succeeded = 1
+	if err := fakeClient.Status().Update(ctx, job); err != nil {
+		t.Fatalf("failed to update Job status: %v", err)
+	}
+	if err := strategy.DeleteBackup(ctx, stored, policy); err != nil {
+		t.Fatalf("DeleteBackup failed: %v", err)
+	}
+	if _, found := backend.objects[expectedKey]; found {
+		t.Fatal("expected the manifests to be deleted with the backup")
+	}
+}
+
+func TestApplyManifestsRetargetsClaims(t *testing.T) {
+	scheme := newRestoreScheme(t)
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+			{Name: "cache", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "cache"}}},
+		}}}},
+	}
+	data, err := encodeManifests([]client.Object{deployment}, scheme, nil)
+	if err != nil {
+		t.Fatalf("encodeManifests failed: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	backend := &objectBackend{objects: map[string][]byte{"manifests.yaml": data}}
+	strategy := &ExternalStrategy{client: fakeClient, backend: backend}
+	stored := &backupv1alpha1.StoredBackup{Name: "policy-data-1", Namespace: "apps", PVCName: "data", Manifests: "manifests.yaml"}
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "apps"}}
+	ctx := context.Background()
+
+	if err := strategy.applyManifests(ctx, stored, policy, restoreTargetPVC("data-restored")); err != nil {
+		t.Fatalf("applyManifests failed: %v", err)
+	}
+	restored := &appsv1.Deployment{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "apps", Name: "web"}, restored); err != nil {
+		t.Fatalf("expected the Deployment to be re-created: %v", err)
+	}
+	volumes := restored.Spec.Template.Spec.Volumes
+	if volumes[0].PersistentVolumeClaim.ClaimName != "data-restored" || volumes[1].PersistentVolumeClaim.ClaimName != "cache" {
+		t.Fatalf("expected only the restored claim to be retargeted, got %+v", volumes)
+	}
+}
+
+func TestCleanupManifestsOfRemovedBackups(t *testing.T) {
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "apps"},
+		Spec:       backupv1alpha1.BackupPolicySpec{Manifests: &backupv1alpha1.Manifests{}},
+	}
+	kept := &backupv1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "policy-data-2", Namespace: "apps"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(newRestoreScheme(t)).WithObjects(kept).Build()
+	backend := &objectBackend{objects: map[string][]byte{
+		".manifests/policy/apps/data/policy-data-1.yaml": nil,
+		".manifests/policy/apps/data/policy-data-2.yaml": nil,
+		".manifests/policy/apps/logs/policy-logs-1.yaml": nil,
+	}}
+	strategy := &ExternalStrategy{client: fakeClient, backend: backend}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "apps"}}
+
+	if err := strategy.Cleanup(context.Background(), pvc, policy); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	if len(backend.objects) != 2 {
+		t.Fatalf("expected only the manifests of the removed backup to be deleted, got %v", backend.objects)
+	}
+	if _, found := backend.objects[".manifests/policy/apps/data/policy-data-1.yaml"]; found {
+		t.Fatal("expected manifests without a Backup to be deleted")
+	}
+}
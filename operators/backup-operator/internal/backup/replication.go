@@ -0,0 +1,263 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+// ReplicaPolicy returns a copy of the policy that uses the replica's destination and retention,
+// so repository URLs, credentials and Job environments are built for the replica
+func ReplicaPolicy(policy *backupv1alpha1.BackupPolicy, replica *backupv1alpha1.Replica) *backupv1alpha1.BackupPolicy {
+	replicaPolicy := policy.DeepCopy()
+	replicaPolicy.Spec.Destination = *replica.Destination.DeepCopy()
+	if replica.Retention != nil {
+		replicaPolicy.Spec.Retention = *replica.Retention
+	}
+	return replicaPolicy
+}
+
+// ReplicationJobName returns the name of the Job copying a backup to a replica
+func ReplicationJobName(backupName, replica string) string {
+	return backupName + "-replica-" + replica
+}
+
+// Replicate creates a Job that copies the restic snapshot of a completed backup into the replica's repository
+// and applies the replica's retention there. The source repository is read with the primary destination's own
+// credentials; when they differ from the replica's, the snapshot is staged in a local repository first.
+func (e *ExternalStrategy) Replicate(ctx context.Context, backup *backupv1alpha1.Backup, policy *backupv1alpha1.BackupPolicy, replica *backupv1alpha1.Replica) (*BackupResult, error) {
+	logger := log.FromContext(ctx)
+
+	if backup.Status.Phase != backupv1alpha1.BackupPhaseCompleted {
+		return nil, fmt.Errorf("backup %s/%s is not completed", backup.Namespace, backup.Name)
+	}
+
+	replicaPolicy := ReplicaPolicy(policy, replica)
+	if err := e.validatePasswordSecret(ctx, replicaPolicy); err != nil {
+		return nil, err
+	}
+	if err := e.ensureCredentialsSecret(ctx, backup.Namespace, replicaPolicy); err != nil {
+		return nil, err
+	}
+	// The source repository is opened with the credentials and password of the primary destination
+	if err := e.ensureCredentialsSecret(ctx, backup.Namespace, policy); err != nil {
+		return nil, err
+	}
+	if err := e.validateServiceAccount(ctx, backup.Namespace, replicaPolicy); err != nil {
+		return nil, err
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: backup.Spec.PVCName, Namespace: backup.Namespace}}
+	sourceURL := backup.Status.Location
+	if sourceURL == "" {
+		var err error
+		if sourceURL, err = e.repositoryURL(policy, pvc); err != nil {
+			return nil, err
+		}
+	}
+	repoURL, err := e.repositoryURL(replicaPolicy, pvc)
+	if err != nil {
+		return nil, err
+	}
+
+	jobName := ReplicationJobName(backup.Name, replica.Name)
+	logger.Info("Creating replication Job", "job", jobName, "backup", backup.Name, "replica", replica.Name, "repo", repoURL)
+
+	job := e.buildReplicationJob(jobName, backup, policy, replica, sourceURL, repoURL)
+	if err := e.client.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("failed to create replication Job %s/%s: %w", backup.Namespace, jobName, err)
+	}
+
+	return &BackupResult{
+		Name:      jobName,
+		Location:  repoURL,
+		Timestamp: time.Now(),
+		Metadata: map[string]string{
+			"backup":      backup.Name,
+			"replica":     replica.Name,
+			"source":      sourceURL,
+			"destination": replica.Destination.Type,
+		},
+	}, nil
+}
+
+// buildReplicationJob creates a Kubernetes Job running restic copy from the primary repository to the replica
+func (e *ExternalStrategy) buildReplicationJob(jobName string, backup *backupv1alpha1.Backup, policy *backupv1alpha1.BackupPolicy, replica *backupv1alpha1.Replica, sourceURL, repoURL string) *batchv1.Job {
+	replicaPolicy := ReplicaPolicy(policy, replica)
+	backoffLimit := int32(2)
+	ttlSecondsAfterFinished := int32(3600)
+	activeDeadlineSeconds := int64(3600)
+
+	env := append(e.buildBackupEnv(replicaPolicy, repoURL),
+		corev1.EnvVar{Name: "RESTIC_FROM_REPOSITORY", Value: sourceURL},
+		passwordEnv("RESTIC_FROM_PASSWORD", ResticPasswordSecret(policy)),
+		corev1.EnvVar{Name: "BACKUP_NAME", Value: backup.Name},
+	)
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if !sameCredentials(policy, replicaPolicy) {
+		env = append(env, sourceCredentialsEnv(e.buildBackupEnv(policy, sourceURL))...)
+		env = append(env, corev1.EnvVar{Name: "SOURCE_STAGING", Value: stagingPath + "/repository"})
+		volumes = []corev1.Volume{{Name: "staging", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+		volumeMounts = []corev1.VolumeMount{{Name: "staging", MountPath: stagingPath}}
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: backup.Namespace,
+			Labels: map[string]string{
+				LabelPolicy:           policy.Name,
+				LabelPVC:              backup.Spec.PVCName,
+				LabelStrategy:         "external",
+				LabelPolicyNamespace:  policy.Namespace,
+				LabelReplicatedBackup: backup.Name,
+				LabelReplica:          replica.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttlSecondsAfterFinished,
+			ActiveDeadlineSeconds:   &activeDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"backup.backup.example.com/job": jobName,
+					},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: jobServiceAccountName(replicaPolicy),
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					Volumes:            volumes,
+					Containers: []corev1.Container{
+						{
+							Name:                     "replicate",
+							Image:                    "restic/restic:latest",
+							Command:                  []string{"/bin/sh", "-c", replicationCommand},
+							Env:                      env,
+							VolumeMounts:             volumeMounts,
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("250m"),
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("1"),
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if policy.Namespace == backup.Namespace {
+		job.OwnerReferences = []metav1.OwnerReference{*ownerReferenceFor(policy, backup.Namespace)}
+	}
+
+	return job
+}
+
+// stagingPath is where replication Jobs keep a local copy of the snapshot when the source needs other credentials
+const stagingPath = "/staging"
+
+// sourceCredentialEnvNames are the variables restic reads S3 credentials from; replicationCommand swaps the same set
+var sourceCredentialEnvNames = []string{
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_DEFAULT_REGION",
+	"RESTIC_AWS_ASSUME_ROLE_ARN",
+	"RESTIC_AWS_ASSUME_ROLE_EXTERNAL_ID",
+}
+
+// sameCredentials reports whether both policies open their destinations with the same credentials
+func sameCredentials(a, b *backupv1alpha1.BackupPolicy) bool {
+	credentials := func(policy *backupv1alpha1.BackupPolicy) string {
+		dest := policy.Spec.Destination
+		key := dest.CredentialsSecret
+		if auth := dest.Auth; auth != nil {
+			key += "/" + string(auth.Mode) + "/" + auth.RoleARN + "/" + auth.ExternalID
+		}
+		return key
+	}
+	return credentials(a) == credentials(b)
+}
+
+// sourceCredentialsEnv returns the S3 credentials of the primary destination prefixed with SOURCE_,
+// from which the replication script opens the source repository
+func sourceCredentialsEnv(primaryEnv []corev1.EnvVar) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	for _, v := range primaryEnv {
+		if slices.Contains(sourceCredentialEnvNames, v.Name) {
+			v.Name = "SOURCE_" + v.Name
+			env = append(env, v)
+		}
+	}
+	return env
+}
+
+// replicationCommand copies the snapshots tagged with the backup name into the replica repository.
+// A new replica repository takes over the chunker parameters of the source so copied data deduplicates.
+// The staging repository shares the source password, so RESTIC_FROM_PASSWORD opens either.
+const replicationCommand = `set -euo pipefail
+echo "Copying backup $BACKUP_NAME to $RESTIC_REPOSITORY" >&2
+` + reportScript + `
+source_restic() {
+  if [ -z "${SOURCE_STAGING:-}" ]; then
+    RESTIC_PASSWORD="$RESTIC_FROM_PASSWORD" restic "$@"
+    return
+  fi
+  (
+    for var in AWS_ACCESS_KEY_ID AWS_SECRET_ACCESS_KEY AWS_DEFAULT_REGION RESTIC_AWS_ASSUME_ROLE_ARN RESTIC_AWS_ASSUME_ROLE_EXTERNAL_ID; do
+      unset "$var"
+      if value=$(printenv "SOURCE_$var"); then export "$var=$value"; fi
+    done
+    RESTIC_PASSWORD="$RESTIC_FROM_PASSWORD" restic "$@"
+  )
+}
+source_restic -r "$RESTIC_FROM_REPOSITORY" snapshots --tag "backup:$BACKUP_NAME" --json >/tmp/snapshots.json 2>/tmp/source-check || report "failed to open source repository: $(tail -n 1 /tmp/source-check)"
+grep -q '"id"' /tmp/snapshots.json || report "backup $BACKUP_NAME not found in $RESTIC_FROM_REPOSITORY"
+SOURCE_REPOSITORY="$RESTIC_FROM_REPOSITORY"
+if [ -n "${SOURCE_STAGING:-}" ]; then
+  # restic copy opens both repositories with one set of credentials, so the snapshot goes through a local repository
+  echo "Staging backup $BACKUP_NAME in $SOURCE_STAGING" >&2
+  source_restic -r "$SOURCE_STAGING" cat config >/dev/null 2>&1 || source_restic -r "$SOURCE_STAGING" init --from-repo "$RESTIC_FROM_REPOSITORY" --copy-chunker-params >/dev/null 2>/tmp/staging-init || report "failed to initialise staging repository: $(tail -n 1 /tmp/staging-init)"
+  source_restic -r "$SOURCE_STAGING" copy --from-repo "$RESTIC_FROM_REPOSITORY" --tag "backup:$BACKUP_NAME"
+  SOURCE_REPOSITORY="$SOURCE_STAGING"
+fi
+export RESTIC_INIT_ARGS="--from-repo $SOURCE_REPOSITORY --copy-chunker-params"
+` + repositoryInitScript + `
+restic -r "$RESTIC_REPOSITORY" ${RESTIC_OPTIONS:-} copy --from-repo "$SOURCE_REPOSITORY" --tag "backup:$BACKUP_NAME"
+
+` + retentionScript + `
+echo "Copied backup $BACKUP_NAME to $RESTIC_REPOSITORY" >&2
+`
@@ -0,0 +1,167 @@
+package backup
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+func TestReplicateCreatesCopyJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, batchv1.AddToScheme, backupv1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "control"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://primary/backups", CredentialsSecret: "primary-credentials"},
+			Retention:   backupv1alpha1.Retention{MaxBackups: 3},
+		},
+	}
+	replica := &backupv1alpha1.Replica{
+		Name: "offsite",
+		Destination: backupv1alpha1.Destination{
+			Type:              "s3",
+			URL:               "s3://offsite/copies",
+			Endpoint:          "https://s3.eu-west-1.amazonaws.com",
+			CredentialsSecret: "offsite-credentials",
+		},
+		Retention: &backupv1alpha1.Retention{MaxBackups: 30},
+	}
+	secrets := []*corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "primary-credentials", Namespace: "control"}, Data: map[string][]byte{ResticPasswordKey: []byte("primary")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "offsite-credentials", Namespace: "control"}, Data: map[string][]byte{ResticPasswordKey: []byte("offsite")}},
+	}
+	item := &backupv1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy-data-20250101-020000", Namespace: "target"},
+		Spec:       backupv1alpha1.BackupSpec{PVCName: "data", Strategy: "external"},
+		Status: backupv1alpha1.BackupStatus{
+			Phase:    backupv1alpha1.BackupPhaseCompleted,
+			Location: "s3:primary/backups/policy/target/data",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy, secrets[0], secrets[1]).Build()
+	strategy := &ExternalStrategy{client: fakeClient}
+	ctx := context.Background()
+
+	result, err := strategy.Replicate(ctx, item, policy, replica)
+	if err != nil {
+		t.Fatalf("Replicate returned error: %v", err)
+	}
+	if expected := "s3:https://s3.eu-west-1.amazonaws.com/offsite/copies/policy/target/data"; result.Location != expected {
+		t.Fatalf("expected replica location %q, got %q", expected, result.Location)
+	}
+
+	job := &batchv1.Job{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "target", Name: result.Name}, job); err != nil {
+		t.Fatalf("expected replication Job to be created: %v", err)
+	}
+	if job.Labels[LabelReplicatedBackup] != item.Name || job.Labels[LabelReplica] != "offsite" {
+		t.Fatalf("expected replication labels, got %v", job.Labels)
+	}
+
+	env := make(map[string]corev1.EnvVar)
+	for _, v := range job.Spec.Template.Spec.Containers[0].Env {
+		env[v.Name] = v
+	}
+	if env["RESTIC_REPOSITORY"].Value != result.Location {
+		t.Fatalf("expected the replica repository, got %q", env["RESTIC_REPOSITORY"].Value)
+	}
+	if env["RESTIC_FROM_REPOSITORY"].Value != item.Status.Location {
+		t.Fatalf("expected the recorded source repository, got %q", env["RESTIC_FROM_REPOSITORY"].Value)
+	}
+	if ref := env["RESTIC_PASSWORD"].ValueFrom.SecretKeyRef; ref.Name != "offsite-credentials" {
+		t.Fatalf("expected the replica password, got %s", ref.Name)
+	}
+	if ref := env["RESTIC_FROM_PASSWORD"].ValueFrom.SecretKeyRef; ref.Name != "primary-credentials" {
+		t.Fatalf("expected the primary password for the source, got %s", ref.Name)
+	}
+	if env["RETENTION_MAX_BACKUPS"].Value != "30" {
+		t.Fatalf("expected the replica retention, got %q", env["RETENTION_MAX_BACKUPS"].Value)
+	}
+
+	// Both destinations' Secrets are needed in the backup namespace
+	for _, name := range []string{"primary-credentials", "offsite-credentials"} {
+		if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "target", Name: name}, &corev1.Secret{}); err != nil {
+			t.Fatalf("expected Secret %s to be copied: %v", name, err)
+		}
+	}
+
+	// The source is read with the primary's own credentials through a local staging repository
+	if ref := env["SOURCE_AWS_ACCESS_KEY_ID"].ValueFrom; ref == nil || ref.SecretKeyRef.Name != "primary-credentials" {
+		t.Fatalf("expected the primary credentials for the source, got %v", env["SOURCE_AWS_ACCESS_KEY_ID"])
+	}
+	if ref := env["AWS_ACCESS_KEY_ID"].ValueFrom.SecretKeyRef; ref.Name != "offsite-credentials" {
+		t.Fatalf("expected the replica credentials, got %s", ref.Name)
+	}
+	if env["SOURCE_STAGING"].Value == "" || len(job.Spec.Template.Spec.Volumes) != 1 {
+		t.Fatalf("expected a staging repository, got %q and volumes %v", env["SOURCE_STAGING"].Value, job.Spec.Template.Spec.Volumes)
+	}
+
+	command := job.Spec.Template.Spec.Containers[0].Command[2]
+	for _, expected := range []string{"--copy-chunker-params", `copy --from-repo "$SOURCE_REPOSITORY" --tag "backup:$BACKUP_NAME"`, "forget $ARGS --prune"} {
+		if !strings.Contains(command, expected) {
+			t.Fatalf("expected command to contain %q, got:\n%s", expected, command)
+		}
+	}
+}
+
+func TestReplicateSharedCredentialsCopyDirectly(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, batchv1.AddToScheme, backupv1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://primary/backups", CredentialsSecret: "restic-credentials"},
+		},
+	}
+	replica := &backupv1alpha1.Replica{
+		Name:        "archive",
+		Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://archive/copies", CredentialsSecret: "restic-credentials"},
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "restic-credentials", Namespace: "ns"}, Data: map[string][]byte{ResticPasswordKey: []byte("secret")}}
+	item := &backupv1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy-data-20250101-020000", Namespace: "ns"},
+		Spec:       backupv1alpha1.BackupSpec{PVCName: "data", Strategy: "external"},
+		Status:     backupv1alpha1.BackupStatus{Phase: backupv1alpha1.BackupPhaseCompleted, Location: "s3:primary/backups/policy/ns/data"},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy, secret).Build()
+	strategy := &ExternalStrategy{client: fakeClient}
+	ctx := context.Background()
+
+	result, err := strategy.Replicate(ctx, item, policy, replica)
+	if err != nil {
+		t.Fatalf("Replicate returned error: %v", err)
+	}
+	job := &batchv1.Job{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "ns", Name: result.Name}, job); err != nil {
+		t.Fatalf("expected replication Job to be created: %v", err)
+	}
+	for _, v := range job.Spec.Template.Spec.Containers[0].Env {
+		if strings.HasPrefix(v.Name, "SOURCE_") {
+			t.Fatalf("expected no separate source credentials, got %s", v.Name)
+		}
+	}
+	if len(job.Spec.Template.Spec.Volumes) != 0 {
+		t.Fatalf("expected no staging volume, got %v", job.Spec.Template.Spec.Volumes)
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+// restoreCommand restores a snapshot, or the latest one of the repository, into the PVC mounted at /data.
+// Backups store the volume under /data, so restoring to / puts files back at their original paths.
+// RESTORE_SNAPSHOT stays unquoted so the "latest --tag" fallback splits into arguments, and --no-lock
+// keeps restores working while another cluster holds the repository or has read-only access to it.
+const restoreCommand = `set -euo pipefail
+echo "Restoring backup $BACKUP_NAME (snapshot ${RESTORE_SNAPSHOT}) into /data" >&2
+restic -r "$RESTIC_REPOSITORY" ${RESTIC_OPTIONS:-} --no-lock restore $RESTORE_SNAPSHOT --target / --verify
+echo "Restore of $BACKUP_NAME completed" >&2`
+
+// RestoreRequest is the value of the restore annotation set by "kubectl backup restore"
+type RestoreRequest struct {
+	// PVC to restore into; imported backups default to their source PVC
+	PVC string `json:"pvc,omitempty"`
+	// Namespace to restore into, the default one of the backup when empty
+	Namespace string `json:"namespace,omitempty"`
+	// Storage class of a new target PVC
+	StorageClass string `json:"storageClass,omitempty"`
+	// Requested size of a new target PVC
+	Size string `json:"size,omitempty"`
+	// When the restore was requested (RFC 3339)
+	RequestedAt string `json:"requestedAt,omitempty"`
+}
+
+// StoredBackupFor describes the artifact of a Backup resource
+func StoredBackupFor(item *backupv1alpha1.Backup) *backupv1alpha1.StoredBackup {
+	stored := &backupv1alpha1.StoredBackup{
+		Name:       item.Name,
+		Namespace:  item.Namespace,
+		PVCName:    item.Spec.PVCName,
+		Size:       item.Status.Size,
+		Location:   item.Status.Location,
+		Status:     item.Status.Phase,
+		Strategy:   item.Spec.Strategy,
+		SnapshotID: item.Status.SnapshotID,
+		Manifests:  item.Status.Manifests,
+	}
+	if item.Status.StartTime != nil {
+		stored.Timestamp = item.Status.StartTime
+	} else {
+		stored.Timestamp = &item.CreationTimestamp
+	}
+	return stored
+}
+
+// snapshotRef returns the restic arguments selecting the snapshot of a backup. Backups taken before snapshot
+// IDs were recorded are found by their tag.
+func snapshotRef(backupName, snapshotID string) string {
+	if snapshotID != "" {
+		return snapshotID
+	}
+	return "latest --tag backup:" + backupName
+}
+
+// RestoreJobName returns the name of the Job restoring a backup into a PVC
+func RestoreJobName(pvcName string, now time.Time) string {
+	return fmt.Sprintf("%s-restore-%s", pvcName, now.Format("20060102-150405"))
+}
+
+// Restore creates a Job running restic restore into targetPVC, which is created first when it does not exist.
+// Files already on an existing PVC are overwritten; the workload using it should be stopped.
+func (e *ExternalStrategy) Restore(ctx context.Context, backup *backupv1alpha1.StoredBackup, policy *backupv1alpha1.BackupPolicy, targetPVC *corev1.PersistentVolumeClaim) error {
+	logger := log.FromContext(ctx)
+
+	if backup.Status != backupv1alpha1.BackupPhaseCompleted {
+		return fmt.Errorf("backup %s/%s is %s, only completed backups can be restored", backup.Namespace, backup.Name, backup.Status)
+	}
+	if policy.Spec.Dump != nil {
+		// Loading a dump needs the database tools and a running server, not a volume
+		return fmt.Errorf("backup %s/%s is a %s dump; load it into the database from \"restic dump\" output", backup.Namespace, backup.Name, policy.Spec.Dump.Engine)
+	}
+	if err := e.validatePasswordSecret(ctx, policy); err != nil {
+		return err
+	}
+
+	// The repository belongs to the PVC the backup was taken from, not to the target
+	repoURL := backup.Location
+	if repoURL == "" {
+		source := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: backup.PVCName, Namespace: backup.Namespace}}
+		var err error
+		if repoURL, err = e.repositoryURL(policy, source); err != nil {
+			return err
+		}
+	}
+
+	if err := e.ensureCredentialsSecret(ctx, targetPVC.Namespace, policy); err != nil {
+		return err
+	}
+	if err := e.validateServiceAccount(ctx, targetPVC.Namespace, policy); err != nil {
+		return err
+	}
+	if err := ensureRestoreTarget(ctx, e.client, backup, targetPVC); err != nil {
+		return err
+	}
+	if backup.Manifests != "" && e.backend != nil {
+		if err := e.applyManifests(ctx, backup, policy, targetPVC); err != nil {
+			return err
+		}
+	}
+
+	snapshot := snapshotRef(backup.Name, backup.SnapshotID)
+
+	jobName := RestoreJobName(targetPVC.Name, time.Now())
+	logger.Info("Creating restore Job", "job", jobName, "backup", backup.Name, "pvc", targetPVC.Name, "namespace", targetPVC.Namespace)
+	job := e.buildRestoreJob(jobName, backup, policy, targetPVC, repoURL, snapshot)
+	if err := e.client.Create(ctx, job); err != nil {
+		return fmt.Errorf("failed to create restore Job %s/%s: %w", targetPVC.Namespace, jobName, err)
+	}
+	return nil
+}
+
+// buildRestoreJob creates the Job restoring a backup into the target PVC
+func (e *ExternalStrategy) buildRestoreJob(jobName string, backup *backupv1alpha1.StoredBackup, policy *backupv1alpha1.BackupPolicy, targetPVC *corev1.PersistentVolumeClaim, repoURL, snapshot string) *batchv1.Job {
+	backoffLimit := int32(1)
+	activeDeadlineSeconds := ActiveDeadlineSeconds(policy)
+
+	env := append(e.buildBackupEnv(policy, repoURL),
+		corev1.EnvVar{Name: "BACKUP_NAME", Value: backup.Name},
+		corev1.EnvVar{Name: "RESTORE_SNAPSHOT", Value: snapshot},
+	)
+	container := corev1.Container{
+		Name:                     "restore",
+		Image:                    "restic/restic:latest",
+		Command:                  []string{"/bin/sh", "-c", restoreCommand},
+		Env:                      env,
+		VolumeMounts:             []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}
+	applyThrottle(&container, e.throttle(policy))
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: targetPVC.Namespace,
+			Labels: map[string]string{
+				LabelPolicy:          policy.Name,
+				LabelPVC:             targetPVC.Name,
+				LabelStrategy:        "external",
+				LabelPolicyNamespace: policy.Namespace,
+				LabelRestoredBackup:  backup.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"backup.backup.example.com/job": jobName,
+					},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: jobServiceAccountName(policy),
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers:         []corev1.Container{container},
+					Volumes: []corev1.Volume{{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: targetPVC.Name},
+						},
+					}},
+				},
+			},
+		},
+	}
+	return job
+}
+
+// ensureRestoreTarget creates the target PVC unless it already exists
+func ensureRestoreTarget(ctx context.Context, c client.Client, backup *backupv1alpha1.StoredBackup, targetPVC *corev1.PersistentVolumeClaim) error {
+	existing := &corev1.PersistentVolumeClaim{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: targetPVC.Namespace, Name: targetPVC.Name}, existing)
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to check PVC %s/%s: %w", targetPVC.Namespace, targetPVC.Name, err)
+	}
+	return createRestoredPVC(ctx, c, backup, targetPVC)
+}
+
+// createRestoredPVC creates the target PVC labelled with the backup it is restored from
+func createRestoredPVC(ctx context.Context, c client.Client, backup *backupv1alpha1.StoredBackup, targetPVC *corev1.PersistentVolumeClaim) error {
+	if targetPVC.Labels == nil {
+		targetPVC.Labels = map[string]string{}
+	}
+	targetPVC.Labels[LabelRestoredBackup] = backup.Name
+	if err := c.Create(ctx, targetPVC); err != nil {
+		return fmt.Errorf("failed to create PVC %s/%s: %w", targetPVC.Namespace, targetPVC.Name, err)
+	}
+	return nil
+}
+
+// Restore creates targetPVC from the VolumeSnapshot of the backup. Volumes can only be provisioned from
+// snapshots in their own namespace, so the target must be a new PVC next to the snapshot.
+func (s *SnapshotStrategy) Restore(ctx context.Context, backup *backupv1alpha1.StoredBackup, policy *backupv1alpha1.BackupPolicy, targetPVC *corev1.PersistentVolumeClaim) error {
+	namespace, name, found := strings.Cut(backup.Location, "/")
+	if !found {
+		namespace, name = backup.Namespace, backup.Name
+	}
+	if targetPVC.Namespace != namespace {
+		return fmt.Errorf("VolumeSnapshot %s/%s can only be restored into namespace %s", namespace, name, namespace)
+	}
+
+	existing := &corev1.PersistentVolumeClaim{}
+	err := s.client.Get(ctx, types.NamespacedName{Namespace: targetPVC.Namespace, Name: targetPVC.Name}, existing)
+	if err == nil {
+		return fmt.Errorf("PVC %s/%s already exists; snapshots can only be restored into new PVCs", targetPVC.Namespace, targetPVC.Name)
+	}
+	if !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to check PVC %s/%s: %w", targetPVC.Namespace, targetPVC.Name, err)
+	}
+
+	apiGroup := volumeSnapshotGVK.Group
+	targetPVC.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: &apiGroup,
+		Kind:     volumeSnapshotGVK.Kind,
+		Name:     name,
+	}
+	log.FromContext(ctx).Info("Restoring VolumeSnapshot", "snapshot", name, "pvc", targetPVC.Name, "namespace", targetPVC.Namespace)
+	return createRestoredPVC(ctx, s.client, backup, targetPVC)
+}
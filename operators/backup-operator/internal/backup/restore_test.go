@@ -0,0 +1,150 @@
+package backup
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+func newRestoreScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, batchv1.AddToScheme, backupv1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+	return scheme
+}
+
+func restoreTargetPVC(name string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+}
+
+func TestExternalRestoreCreatesTargetAndJob(t *testing.T) {
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "apps"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://bucket/backups", CredentialsSecret: "creds"},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "apps"},
+		Data:       map[string][]byte{ResticPasswordKey: []byte("secret")},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(newRestoreScheme(t)).WithObjects(policy, secret).Build()
+	strategy := &ExternalStrategy{client: fakeClient}
+	stored := &backupv1alpha1.StoredBackup{
+		Name:       "policy-data-20250101-020000",
+		Namespace:  "apps",
+		PVCName:    "data",
+		Location:   "s3:s3.amazonaws.com/bucket/backups/policy/apps/data",
+		Status:     backupv1alpha1.BackupPhaseCompleted,
+		SnapshotID: "4f2a9c1e",
+	}
+
+	ctx := context.Background()
+	if err := strategy.Restore(ctx, stored, policy, restoreTargetPVC("data-restored")); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "apps", Name: "data-restored"}, pvc); err != nil {
+		t.Fatalf("expected target PVC to be created: %v", err)
+	}
+	if pvc.Labels[LabelRestoredBackup] != stored.Name {
+		t.Fatalf("expected restored backup label on PVC, got %v", pvc.Labels)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := fakeClient.List(ctx, jobs); err != nil {
+		t.Fatalf("failed to list Jobs: %v", err)
+	}
+	if len(jobs.Items) != 1 {
+		t.Fatalf("expected one restore Job, got %d", len(jobs.Items))
+	}
+	job := jobs.Items[0]
+	if job.Labels[LabelRestoredBackup] != stored.Name || job.Labels[LabelPVC] != "data-restored" {
+		t.Fatalf("unexpected restore Job labels %v", job.Labels)
+	}
+	if claim := job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName; claim != "data-restored" {
+		t.Fatalf("expected Job to mount the target PVC, got %q", claim)
+	}
+	env := map[string]string{}
+	for _, e := range job.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if env["RESTIC_REPOSITORY"] != stored.Location {
+		t.Fatalf("expected the repository of the source PVC, got %q", env["RESTIC_REPOSITORY"])
+	}
+	if env["RESTORE_SNAPSHOT"] != stored.SnapshotID {
+		t.Fatalf("expected snapshot %q, got %q", stored.SnapshotID, env["RESTORE_SNAPSHOT"])
+	}
+	if script := job.Spec.Template.Spec.Containers[0].Command[2]; !strings.Contains(script, "--no-lock restore $RESTORE_SNAPSHOT ") {
+		t.Fatalf("expected an unlocked restore of the unquoted snapshot, got %q", script)
+	}
+}
+
+func TestExternalRestoreRejectsIncompleteBackup(t *testing.T) {
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "apps"}}
+	fakeClient := fake.NewClientBuilder().WithScheme(newRestoreScheme(t)).WithObjects(policy).Build()
+	strategy := &ExternalStrategy{client: fakeClient}
+	stored := &backupv1alpha1.StoredBackup{Name: "running", Namespace: "apps", Status: backupv1alpha1.BackupPhaseRunning}
+
+	err := strategy.Restore(context.Background(), stored, policy, restoreTargetPVC("data-restored"))
+	if err == nil || !strings.Contains(err.Error(), "only completed backups") {
+		t.Fatalf("expected incomplete backup to be rejected, got %v", err)
+	}
+}
+
+func TestSnapshotRestoreCreatesPVCFromSnapshot(t *testing.T) {
+	existing := restoreTargetPVC("data")
+	fakeClient := fake.NewClientBuilder().WithScheme(newRestoreScheme(t)).WithObjects(existing).Build()
+	strategy := &SnapshotStrategy{client: fakeClient}
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "apps"}}
+	stored := &backupv1alpha1.StoredBackup{
+		Name:      "data-snapshot-20250101-020000",
+		Namespace: "apps",
+		Location:  "apps/data-snapshot-20250101-020000",
+	}
+
+	ctx := context.Background()
+	if err := strategy.Restore(ctx, stored, policy, restoreTargetPVC("data")); err == nil {
+		t.Fatal("expected restoring into an existing PVC to fail")
+	}
+	other := restoreTargetPVC("data-restored")
+	other.Namespace = "other"
+	if err := strategy.Restore(ctx, stored, policy, other); err == nil {
+		t.Fatal("expected restoring into another namespace to fail")
+	}
+
+	if err := strategy.Restore(ctx, stored, policy, restoreTargetPVC("data-restored")); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "apps", Name: "data-restored"}, pvc); err != nil {
+		t.Fatalf("expected target PVC to be created: %v", err)
+	}
+	source := pvc.Spec.DataSource
+	if source == nil || source.Kind != "VolumeSnapshot" || source.Name != stored.Name {
+		t.Fatalf("expected PVC data source to be the snapshot, got %+v", source)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetentionCutoff returns the time before which backups exceed the retention maxAge. maxAge uses the
+// duration format of restic forget --keep-within, e.g. "7d" or "1y6m", so the operator and the restic
+// retention of the external strategy agree: y(ears), m(onths), d(ays) and h(ours).
+func RetentionCutoff(maxAge string, now time.Time) (time.Time, error) {
+	if maxAge == "" {
+		return time.Time{}, fmt.Errorf("empty duration")
+	}
+	var years, months, days, hours int
+	number := -1
+	for _, c := range maxAge {
+		if c >= '0' && c <= '9' {
+			if number < 0 {
+				number = 0
+			}
+			number = number*10 + int(c-'0')
+			continue
+		}
+		if number < 0 {
+			return time.Time{}, fmt.Errorf("invalid duration %q: expected a number before %q", maxAge, c)
+		}
+		switch c {
+		case 'y':
+			years += number
+		case 'm':
+			months += number
+		case 'd':
+			days += number
+		case 'h':
+			hours += number
+		default:
+			return time.Time{}, fmt.Errorf("invalid duration %q: unknown unit %q, use y, m, d or h", maxAge, c)
+		}
+		number = -1
+	}
+	if number >= 0 {
+		return time.Time{}, fmt.Errorf("invalid duration %q: missing unit after %d", maxAge, number)
+	}
+	return now.AddDate(-years, -months, -days).Add(-time.Duration(hours) * time.Hour), nil
+}
@@ -0,0 +1,31 @@
+package backup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionCutoffUsesResticDurations(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	for maxAge, expected := range map[string]time.Time{
+		"7d":    time.Date(2025, 3, 24, 12, 0, 0, 0, time.UTC),
+		"168h":  time.Date(2025, 3, 24, 12, 0, 0, 0, time.UTC),
+		"1y2m":  time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC),
+		"1d12h": time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC),
+	} {
+		cutoff, err := RetentionCutoff(maxAge, now)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", maxAge, err)
+			continue
+		}
+		if !cutoff.Equal(expected) {
+			t.Errorf("%s: expected cutoff %s, got %s", maxAge, expected, cutoff)
+		}
+	}
+
+	for _, invalid := range []string{"7", "d", "1.5h", "30s", "2w"} {
+		if _, err := RetentionCutoff(invalid, now); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
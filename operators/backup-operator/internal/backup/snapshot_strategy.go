@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -28,10 +29,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/metrics"
 )
 
 var (
@@ -49,12 +53,13 @@ var (
 
 // SnapshotStrategy implements backup using Kubernetes VolumeSnapshots
 type SnapshotStrategy struct {
-	client client.Client
+	client   client.Client
+	recorder record.EventRecorder
 }
 
 // NewSnapshotStrategy creates a new snapshot-based backup strategy
-func NewSnapshotStrategy(c client.Client) Strategy {
-	return &SnapshotStrategy{client: c}
+func NewSnapshotStrategy(c client.Client, recorder record.EventRecorder) Strategy {
+	return &SnapshotStrategy{client: c, recorder: recorder}
 }
 
 // Backup creates a VolumeSnapshot for the given PVC
@@ -151,8 +156,8 @@ func (s *SnapshotStrategy) Cleanup(ctx context.Context, pvc *corev1.PersistentVo
 		exceedMaxAge := false
 
 		if retention.MaxAge != "" && backupInfo.Timestamp != nil {
-			if maxAge, parseErr := time.ParseDuration(retention.MaxAge); parseErr == nil {
-				if now.Sub(backupInfo.Timestamp.Time) > maxAge {
+			if cutoff, parseErr := RetentionCutoff(retention.MaxAge, now); parseErr == nil {
+				if backupInfo.Timestamp.Time.Before(cutoff) {
 					exceedMaxAge = true
 				}
 			} else {
@@ -165,10 +170,18 @@ func (s *SnapshotStrategy) Cleanup(ctx context.Context, pvc *corev1.PersistentVo
 		}
 	}
 
+	deleted := 0
 	for _, backupInfo := range toDelete {
 		if err := s.DeleteBackup(ctx, &backupInfo, policy); err != nil {
 			logger.Error(err, "Failed to delete expired snapshot", "snapshot", backupInfo.Name, "namespace", backupInfo.Namespace)
+			continue
 		}
+		deleted++
+	}
+	metrics.RecordRetentionDeletions(policy.Namespace, policy.Name, "snapshot", deleted)
+	if deleted > 0 {
+		RecordEvent(s.recorder, corev1.EventTypeNormal, EventReasonRetentionDeleted,
+			fmt.Sprintf("Deleted %d expired snapshot(s) of PVC %s/%s", deleted, pvc.Namespace, pvc.Name), policy, pvc)
 	}
 
 	if len(toDelete) > 0 {
@@ -177,9 +190,78 @@ func (s *SnapshotStrategy) Cleanup(ctx context.Context, pvc *corev1.PersistentVo
 	return nil
 }
 
-// Restore restores a VolumeSnapshot to a new PVC (not yet implemented)
-func (s *SnapshotStrategy) Restore(ctx context.Context, backup *backupv1alpha1.StoredBackup, targetPVC *corev1.PersistentVolumeClaim) error {
-	return fmt.Errorf("snapshot restore not yet implemented")
+// Detach removes the policy's owner reference from its VolumeSnapshots so they survive the policy's deletion
+func (s *SnapshotStrategy) Detach(ctx context.Context, policy *backupv1alpha1.BackupPolicy, namespaces []string) error {
+	for _, ns := range namespaces {
+		snapshotList := &unstructured.UnstructuredList{}
+		snapshotList.SetGroupVersionKind(volumeSnapshotListGVK)
+		if err := s.client.List(ctx, snapshotList, client.InNamespace(ns), client.MatchingLabels{LabelPolicy: policy.Name}); err != nil {
+			return fmt.Errorf("failed to list VolumeSnapshots in namespace %s: %w", ns, err)
+		}
+
+		for i := range snapshotList.Items {
+			snapshot := &snapshotList.Items[i]
+			owners := snapshot.GetOwnerReferences()
+			kept := owners[:0]
+			for _, owner := range owners {
+				if owner.UID != policy.UID {
+					kept = append(kept, owner)
+				}
+			}
+			if len(kept) == len(owners) {
+				continue
+			}
+			snapshot.SetOwnerReferences(kept)
+			if err := s.client.Update(ctx, snapshot); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to detach VolumeSnapshot %s/%s: %w", ns, snapshot.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// SnapshotState is the progress of the VolumeSnapshot of a Backup as reported by the snapshot controller
+type SnapshotState struct {
+	// Backup phase: Running until the snapshot is ready to use, Failed when it reports an error or is gone
+	Phase string
+	// Error reported by the snapshot controller
+	Message string
+	// Restore size of the snapshot, empty until it is known
+	Size string
+	// When the storage system took the snapshot
+	CreationTime *metav1.Time
+}
+
+// GetSnapshotState reads the VolumeSnapshot of a snapshot Backup and maps its status onto a Backup phase
+func GetSnapshotState(ctx context.Context, c client.Reader, item *backupv1alpha1.Backup) (*SnapshotState, error) {
+	namespace, name, found := strings.Cut(item.Status.Location, "/")
+	if !found {
+		namespace, name = item.Namespace, item.Name
+	}
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, snapshot); err != nil {
+		if errors.IsNotFound(err) {
+			return &SnapshotState{
+				Phase:   backupv1alpha1.BackupPhaseFailed,
+				Message: fmt.Sprintf("VolumeSnapshot %s/%s no longer exists", namespace, name),
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to get VolumeSnapshot %s/%s: %w", namespace, name, err)
+	}
+
+	stored := snapshotFromUnstructured(*snapshot, &corev1.PersistentVolumeClaim{})
+	state := &SnapshotState{Phase: backupv1alpha1.BackupPhaseRunning, Size: stored.Size, CreationTime: stored.Timestamp}
+	message, failed, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message")
+	switch {
+	case stored.Status == backupv1alpha1.BackupPhaseCompleted:
+		state.Phase = backupv1alpha1.BackupPhaseCompleted
+	case failed:
+		state.Phase = backupv1alpha1.BackupPhaseFailed
+		state.Message = message
+	}
+	return state, nil
 }
 
 func newVolumeSnapshot(name string, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy, owner *metav1.OwnerReference) *unstructured.Unstructured {
@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+func TestSnapshotDetachRemovesPolicyOwner(t *testing.T) {
+	policy := &backupv1alpha1.BackupPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: backupv1alpha1.GroupVersion.String(), Kind: "BackupPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns", UID: "policy-uid"},
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "ns"}}
+	snapshot := newVolumeSnapshot("policy-data-1", pvc, policy, ownerReferenceFor(policy, pvc.Namespace))
+
+	scheme := runtime.NewScheme()
+	if err := backupv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(snapshot).Build()
+	s := &SnapshotStrategy{client: c}
+
+	if err := s.Detach(context.Background(), policy, []string{"ns", "other"}); err != nil {
+		t.Fatalf("Detach returned error: %v", err)
+	}
+
+	got := &unstructured.Unstructured{}
+	got.SetGroupVersionKind(volumeSnapshotGVK)
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(snapshot), got); err != nil {
+		t.Fatalf("failed to get VolumeSnapshot: %v", err)
+	}
+	if owners := got.GetOwnerReferences(); len(owners) != 0 {
+		t.Fatalf("expected owner references to be removed, got %v", owners)
+	}
+}
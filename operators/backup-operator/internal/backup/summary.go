@@ -0,0 +1,68 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+// ResticSummary is the summary message of restic backup --json that backup Jobs write to their termination log
+type ResticSummary struct {
+	MessageType         string  `json:"message_type"`
+	FilesNew            int64   `json:"files_new"`
+	FilesChanged        int64   `json:"files_changed"`
+	FilesUnmodified     int64   `json:"files_unmodified"`
+	DataAdded           int64   `json:"data_added"`
+	DataAddedPacked     int64   `json:"data_added_packed"`
+	TotalBytesProcessed int64   `json:"total_bytes_processed"`
+	TotalDuration       float64 `json:"total_duration"`
+	SnapshotID          string  `json:"snapshot_id"`
+}
+
+// ParseResticSummary parses the termination message of a successful backup Job
+func ParseResticSummary(message string) (*ResticSummary, error) {
+	summary := &ResticSummary{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(message)), summary); err != nil {
+		return nil, fmt.Errorf("failed to parse restic summary: %w", err)
+	}
+	if summary.MessageType != "summary" || summary.SnapshotID == "" {
+		return nil, fmt.Errorf("termination message is not a restic backup summary")
+	}
+	return summary, nil
+}
+
+// Apply records the snapshot ID, the size read from the PVC and the transfer statistics in the Backup status
+func (s *ResticSummary) Apply(status *backupv1alpha1.BackupStatus) {
+	status.SnapshotID = s.SnapshotID
+	status.Size = humanReadableQuantity(*resource.NewQuantity(s.TotalBytesProcessed, resource.BinarySI))
+	status.Stats = &backupv1alpha1.BackupStats{
+		FilesNew:        s.FilesNew,
+		FilesChanged:    s.FilesChanged,
+		FilesUnmodified: s.FilesUnmodified,
+		BytesProcessed:  s.TotalBytesProcessed,
+		BytesAdded:      s.DataAdded,
+		BytesStored:     s.DataAddedPacked,
+		Duration:        time.Duration(s.TotalDuration * float64(time.Second)).Round(time.Second).String(),
+	}
+}
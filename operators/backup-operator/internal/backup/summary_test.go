@@ -0,0 +1,15 @@
+package backup
+
+import "testing"
+
+func TestParseResticSummaryRejectsOtherMessages(t *testing.T) {
+	for _, message := range []string{
+		"",
+		"wrong restic password for repository s3:bucket/repo",
+		`{"message_type":"status","percent_done":0.5}`,
+	} {
+		if _, err := ParseResticSummary(message); err == nil {
+			t.Errorf("expected %q not to parse as a summary", message)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+// ioniceScript lowers the I/O priority of the backup shell and, through inheritance, of restic.
+// ionice needs a kernel I/O scheduler honouring priorities (BFQ); without it the backup runs unthrottled.
+const ioniceScript = `if [ -n "${IONICE_ARGS:-}" ]; then
+  ionice $IONICE_ARGS -p $$ || echo "ionice is not supported, reading the PVC without I/O priority" >&2
+fi`
+
+// throttle returns the policy throttle with unset fields taken from the operator defaults, or nil
+func (e *ExternalStrategy) throttle(policy *backupv1alpha1.BackupPolicy) *backupv1alpha1.Throttle {
+	if policy.Spec.Throttle == nil && e.defaultThrottle == nil {
+		return nil
+	}
+	merged := &backupv1alpha1.Throttle{}
+	if policy.Spec.Throttle != nil {
+		merged = policy.Spec.Throttle.DeepCopy()
+	}
+	defaults := e.defaultThrottle
+	if defaults == nil {
+		return merged
+	}
+	if merged.UploadLimit == nil {
+		merged.UploadLimit = defaults.UploadLimit
+	}
+	if merged.DownloadLimit == nil {
+		merged.DownloadLimit = defaults.DownloadLimit
+	}
+	if merged.IOClass == "" {
+		merged.IOClass = defaults.IOClass
+		if merged.IOPriority == nil {
+			merged.IOPriority = defaults.IOPriority
+		}
+	}
+	if merged.CPULimit == nil {
+		merged.CPULimit = defaults.CPULimit
+	}
+	return merged
+}
+
+// ioniceArgs returns the ionice arguments for the throttle's I/O class
+func ioniceArgs(throttle *backupv1alpha1.Throttle) string {
+	switch throttle.IOClass {
+	case backupv1alpha1.IOClassIdle:
+		return "-c 3"
+	case backupv1alpha1.IOClassBestEffort:
+		if throttle.IOPriority != nil {
+			return fmt.Sprintf("-c 2 -n %d", *throttle.IOPriority)
+		}
+		return "-c 2"
+	default:
+		return ""
+	}
+}
+
+// applyThrottle sets the I/O class and CPU limit of the throttle on a backup container
+func applyThrottle(container *corev1.Container, throttle *backupv1alpha1.Throttle) {
+	if throttle == nil {
+		return
+	}
+	if args := ioniceArgs(throttle); args != "" {
+		container.Env = append(container.Env, corev1.EnvVar{Name: "IONICE_ARGS", Value: args})
+	}
+	if throttle.CPULimit == nil || throttle.CPULimit.IsZero() {
+		return
+	}
+
+	limit := throttle.CPULimit.DeepCopy()
+	container.Resources.Limits[corev1.ResourceCPU] = limit
+	if request := container.Resources.Requests[corev1.ResourceCPU]; request.Cmp(limit) > 0 {
+		container.Resources.Requests[corev1.ResourceCPU] = limit
+	}
+	// restic would otherwise schedule as many threads as the node has cores and be throttled by the quota
+	procs := max((limit.MilliValue()+999)/1000, 1)
+	container.Env = append(container.Env, corev1.EnvVar{Name: "GOMAXPROCS", Value: strconv.FormatInt(procs, 10)})
+}
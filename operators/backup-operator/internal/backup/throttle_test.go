@@ -0,0 +1,68 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+func TestBuildBackupJobAppliesThrottle(t *testing.T) {
+	upload := resource.MustParse("5Mi")
+	cpu := resource.MustParse("200m")
+	strategy := &ExternalStrategy{defaultThrottle: &backupv1alpha1.Throttle{
+		UploadLimit: &upload,
+		IOClass:     backupv1alpha1.IOClassBestEffort,
+	}}
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "control"}}
+	policy.Spec.Destination.Type = "s3"
+	policy.Spec.Throttle = &backupv1alpha1.Throttle{IOClass: backupv1alpha1.IOClassIdle, CPULimit: &cpu}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "target"}}
+
+	job := strategy.buildBackupJob("backup", pvc, policy, "s3:bucket/repo")
+	container := job.Spec.Template.Spec.Containers[0]
+
+	env := make(map[string]string)
+	for _, v := range container.Env {
+		env[v.Name] = v.Value
+	}
+	if env["IONICE_ARGS"] != "-c 3" {
+		t.Errorf("expected the policy I/O class to override the default, got %q", env["IONICE_ARGS"])
+	}
+	if env["RESTIC_OPTIONS"] != "--limit-upload 5120" {
+		t.Errorf("expected the default upload limit, got %q", env["RESTIC_OPTIONS"])
+	}
+	if env["GOMAXPROCS"] != "1" {
+		t.Errorf("expected GOMAXPROCS to follow the CPU limit, got %q", env["GOMAXPROCS"])
+	}
+	if limit := container.Resources.Limits[corev1.ResourceCPU]; limit.String() != "200m" {
+		t.Errorf("expected CPU limit 200m, got %s", limit.String())
+	}
+	if request := container.Resources.Requests[corev1.ResourceCPU]; request.String() != "200m" {
+		t.Errorf("expected the CPU request lowered to the limit, got %s", request.String())
+	}
+	if command := container.Command[2]; !strings.Contains(command, `ionice $IONICE_ARGS -p $$`) {
+		t.Errorf("expected the backup command to apply the I/O class, got:\n%s", command)
+	}
+}
+
+func TestIoniceArgs(t *testing.T) {
+	priority := int32(7)
+	for _, tc := range []struct {
+		throttle backupv1alpha1.Throttle
+		want     string
+	}{
+		{backupv1alpha1.Throttle{}, ""},
+		{backupv1alpha1.Throttle{IOClass: backupv1alpha1.IOClassIdle}, "-c 3"},
+		{backupv1alpha1.Throttle{IOClass: backupv1alpha1.IOClassBestEffort}, "-c 2"},
+		{backupv1alpha1.Throttle{IOClass: backupv1alpha1.IOClassBestEffort, IOPriority: &priority}, "-c 2 -n 7"},
+	} {
+		if got := ioniceArgs(&tc.throttle); got != tc.want {
+			t.Errorf("ioniceArgs(%+v) = %q, want %q", tc.throttle, got, tc.want)
+		}
+	}
+}
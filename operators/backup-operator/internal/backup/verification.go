@@ -0,0 +1,231 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+const (
+	// ConditionVerified is set on a Backup once a verification Job has finished
+	ConditionVerified = "Verified"
+
+	// Maximum number of restored files compared against the repository during test restores
+	verifySampleFiles = 100
+)
+
+// Verify creates a Job that checks the restic repository of the PVC and optionally test-restores the snapshot of the backup
+func (e *ExternalStrategy) Verify(ctx context.Context, backup *backupv1alpha1.Backup, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy) (string, error) {
+	logger := log.FromContext(ctx)
+
+	if policy.Spec.Verification == nil {
+		return "", fmt.Errorf("verification is not configured for policy %s/%s", policy.Namespace, policy.Name)
+	}
+
+	if err := e.ensureCredentialsSecret(ctx, pvc.Namespace, policy); err != nil {
+		return "", err
+	}
+
+	repoURL, err := e.repositoryURL(policy, pvc)
+	if err != nil {
+		return "", err
+	}
+
+	jobName := fmt.Sprintf("%s-%s-verify-%s", policy.Name, pvc.Name, time.Now().Format("20060102-150405"))
+	logger.Info("Creating verification Job", "job", jobName, "backup", backup.Name, "pvc", pvc.Name, "namespace", pvc.Namespace)
+
+	job := e.buildVerificationJob(jobName, backup, pvc, policy, repoURL)
+	if err := e.client.Create(ctx, job); err != nil {
+		return "", fmt.Errorf("failed to create verification Job %s/%s: %w", pvc.Namespace, jobName, err)
+	}
+
+	return jobName, nil
+}
+
+// buildVerificationJob creates a Kubernetes Job running restic check and an optional test restore
+func (e *ExternalStrategy) buildVerificationJob(jobName string, backup *backupv1alpha1.Backup, pvc *corev1.PersistentVolumeClaim, policy *backupv1alpha1.BackupPolicy, repoURL string) *batchv1.Job {
+	verification := policy.Spec.Verification
+	// Verification failures are reported, not retried
+	backoffLimit := int32(0)
+	ttlSecondsAfterFinished := int32(3600)
+	activeDeadlineSeconds := int64(3600)
+
+	env := e.buildBackupEnv(policy, repoURL)
+	env = append(env, corev1.EnvVar{Name: "VERIFY_SNAPSHOT", Value: snapshotRef(backup.Name, backup.Status.SnapshotID)})
+	if verification.ReadDataSubset != "" {
+		env = append(env, corev1.EnvVar{Name: "READ_DATA_SUBSET", Value: verification.ReadDataSubset})
+	}
+
+	container := corev1.Container{
+		Name:                     "verify",
+		Image:                    "restic/restic:latest",
+		Command:                  []string{"/bin/sh", "-c", buildVerificationCommand(backup, policy)},
+		Env:                      env,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+	}
+
+	var volumes []corev1.Volume
+	if verification.TestRestore {
+		container.VolumeMounts = []corev1.VolumeMount{{Name: "scratch", MountPath: "/scratch"}}
+		volumes = append(volumes, scratchVolume(pvc, verification))
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: pvc.Namespace,
+			Labels: map[string]string{
+				LabelPolicy:          policy.Name,
+				LabelPVC:             pvc.Name,
+				LabelStrategy:        "external",
+				LabelPolicyNamespace: policy.Namespace,
+				LabelVerifiedBackup:  backup.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttlSecondsAfterFinished,
+			ActiveDeadlineSeconds:   &activeDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"backup.backup.example.com/job": jobName,
+					},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: jobServiceAccountName(policy),
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers:         []corev1.Container{container},
+					Volumes:            volumes,
+				},
+			},
+		},
+	}
+
+	if policy.Namespace == pvc.Namespace {
+		job.OwnerReferences = []metav1.OwnerReference{*ownerReferenceFor(policy, pvc.Namespace)}
+	}
+
+	return job
+}
+
+// scratchVolume returns a generic ephemeral volume sized like the source PVC, deleted together with the pod
+func scratchVolume(pvc *corev1.PersistentVolumeClaim, verification *backupv1alpha1.Verification) corev1.Volume {
+	size := pvc.Status.Capacity[corev1.ResourceStorage]
+	if size.IsZero() {
+		size = pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	}
+	if size.IsZero() {
+		size = resource.MustParse("1Gi")
+	}
+
+	claimSpec := corev1.PersistentVolumeClaimSpec{
+		AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		Resources: corev1.VolumeResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+		},
+	}
+	if verification.ScratchStorageClassName != "" {
+		storageClass := verification.ScratchStorageClassName
+		claimSpec.StorageClassName = &storageClass
+	}
+
+	return corev1.Volume{
+		Name: "scratch",
+		VolumeSource: corev1.VolumeSource{
+			Ephemeral: &corev1.EphemeralVolumeSource{
+				VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+					Spec: claimSpec,
+				},
+			},
+		},
+	}
+}
+
+// buildVerificationCommand generates the verification script executed inside the Job pod. The test restore
+// reads the snapshot of the verified backup from $VERIFY_SNAPSHOT; files are only compared for volume
+// backups since dumps have no /data.
+func buildVerificationCommand(backup *backupv1alpha1.Backup, policy *backupv1alpha1.BackupPolicy) string {
+	verification := policy.Spec.Verification
+	script := fmt.Sprintf(`set -euo pipefail
+echo "Verifying backup %s" >&2
+if [ -n "${READ_DATA_SUBSET:-}" ]; then
+  restic -r "$RESTIC_REPOSITORY" check --read-data-subset "$READ_DATA_SUBSET"
+else
+  restic -r "$RESTIC_REPOSITORY" check
+fi
+`, backup.Name)
+
+	if !verification.TestRestore {
+		return script
+	}
+
+	if policy.Spec.Dump != nil {
+		return script + fmt.Sprintf(`
+echo "Test-restoring snapshot ${VERIFY_SNAPSHOT} into scratch volume" >&2
+restic -r "$RESTIC_REPOSITORY" restore $VERIFY_SNAPSHOT --target /scratch --verify
+echo "Verification of %s completed" >&2
+`, backup.Name)
+	}
+
+	include := ""
+	samplePath := "/data"
+	if verification.SamplePath != "" {
+		samplePath = path.Join("/data", verification.SamplePath)
+		include = fmt.Sprintf(" --include %q", samplePath)
+	}
+
+	return script + fmt.Sprintf(`
+echo "Test-restoring snapshot ${VERIFY_SNAPSHOT} into scratch volume" >&2
+restic -r "$RESTIC_REPOSITORY" restore $VERIFY_SNAPSHOT --target /scratch --verify%s
+if [ ! -e "/scratch%s" ]; then
+  echo "restored snapshot does not contain %s" >&2
+  exit 1
+fi
+cd /scratch
+find ".%s" -type f | head -n %d | while read -r f; do
+  restored=$(sha256sum "$f" | cut -d' ' -f1)
+  expected=$(restic -r "$RESTIC_REPOSITORY" dump $VERIFY_SNAPSHOT "${f#.}" | sha256sum | cut -d' ' -f1)
+  if [ "$restored" != "$expected" ]; then
+    echo "checksum mismatch for ${f#.}" >&2
+    exit 1
+  fi
+done
+echo "Verification of %s completed" >&2
+`, include, samplePath, samplePath, samplePath, verifySampleFiles, backup.Name)
+}
@@ -0,0 +1,88 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+func TestBuildVerificationJobWithTestRestore(t *testing.T) {
+	strategy := &ExternalStrategy{}
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "control"}}
+	policy.Spec.Destination.Type = "s3"
+	policy.Spec.Verification = &backupv1alpha1.Verification{
+		Schedule:                "0 4 * * 0",
+		ReadDataSubset:          "10%",
+		TestRestore:             true,
+		SamplePath:              "db",
+		ScratchStorageClassName: "fast",
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "target"}}
+	pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")}
+	backup := &backupv1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "policy-data-20250101-020000", Namespace: "target"}}
+	backup.Status.SnapshotID = "4c5d1a2b"
+
+	job := strategy.buildVerificationJob("verify", backup, pvc, policy, "s3:bucket/policy/target/data")
+
+	if job.Labels[LabelVerifiedBackup] != backup.Name {
+		t.Fatalf("expected verified backup label %q, got %v", backup.Name, job.Labels)
+	}
+	if len(job.OwnerReferences) != 0 {
+		t.Fatalf("cross-namespace verification Job must not have owner references")
+	}
+
+	volumes := job.Spec.Template.Spec.Volumes
+	if len(volumes) != 1 || volumes[0].Ephemeral == nil {
+		t.Fatalf("expected an ephemeral scratch volume, got %v", volumes)
+	}
+	claim := volumes[0].Ephemeral.VolumeClaimTemplate.Spec
+	if size := claim.Resources.Requests[corev1.ResourceStorage]; size.String() != "5Gi" {
+		t.Fatalf("expected scratch volume sized like the source PVC, got %s", size.String())
+	}
+	if claim.StorageClassName == nil || *claim.StorageClassName != "fast" {
+		t.Fatalf("expected scratch storage class to be set")
+	}
+
+	command := job.Spec.Template.Spec.Containers[0].Command[2]
+	for _, expected := range []string{"--read-data-subset", "restore $VERIFY_SNAPSHOT --target /scratch --verify --include \"/data/db\"", "dump $VERIFY_SNAPSHOT", "sha256sum"} {
+		if !strings.Contains(command, expected) {
+			t.Fatalf("expected command to contain %q, got:\n%s", expected, command)
+		}
+	}
+
+	env := map[string]string{}
+	for _, e := range job.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if env["READ_DATA_SUBSET"] != "10%" {
+		t.Fatalf("expected READ_DATA_SUBSET env var")
+	}
+	if env["VERIFY_SNAPSHOT"] != "4c5d1a2b" {
+		t.Fatalf("expected the snapshot of the verified backup, got %q", env["VERIFY_SNAPSHOT"])
+	}
+}
+
+func TestBuildVerificationCommandCheckOnly(t *testing.T) {
+	policy := &backupv1alpha1.BackupPolicy{}
+	policy.Spec.Verification = &backupv1alpha1.Verification{Schedule: "0 4 * * 0"}
+	command := buildVerificationCommand(&backupv1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "b"}}, policy)
+	if strings.Contains(command, "restore") {
+		t.Fatalf("expected no test restore in check-only verification, got:\n%s", command)
+	}
+}
+
+func TestBuildVerificationCommandForDumps(t *testing.T) {
+	policy := &backupv1alpha1.BackupPolicy{}
+	policy.Spec.Verification = &backupv1alpha1.Verification{Schedule: "0 4 * * 0", TestRestore: true}
+	policy.Spec.Dump = &backupv1alpha1.Dump{Engine: "postgres"}
+	command := buildVerificationCommand(&backupv1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "b"}}, policy)
+	if !strings.Contains(command, "restore $VERIFY_SNAPSHOT") || strings.Contains(command, "/data") || strings.Contains(command, "sha256sum") {
+		t.Fatalf("expected a test restore without file comparison for dumps, got:\n%s", command)
+	}
+}
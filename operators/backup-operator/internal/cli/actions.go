@@ -0,0 +1,125 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func newTriggerCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "trigger POLICY",
+		Short: "Back up all PVCs of a BackupPolicy now, outside its schedule",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, namespace, err := o.connect()
+			if err != nil {
+				return err
+			}
+			policy, err := getPolicy(cmd.Context(), c, namespace, args[0])
+			if err != nil {
+				return err
+			}
+			if err := requestNow(cmd.Context(), c, policy, backup.AnnotationBackupNow); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "backuppolicy/%s backup requested\n", policy.Name)
+			return nil
+		},
+	}
+}
+
+// newSuspendCommand returns the suspend command, or the resume command when suspend is false
+func newSuspendCommand(o *options, suspend bool) *cobra.Command {
+	use, short, done := "suspend POLICY", "Stop scheduled backups, verifications and replication of a BackupPolicy", "suspended"
+	if !suspend {
+		use, short, done = "resume POLICY", "Resume scheduled backups of a suspended BackupPolicy", "resumed"
+	}
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, namespace, err := o.connect()
+			if err != nil {
+				return err
+			}
+			policy, err := getPolicy(cmd.Context(), c, namespace, args[0])
+			if err != nil {
+				return err
+			}
+			if policy.Spec.Suspend == suspend {
+				fmt.Fprintf(cmd.OutOrStdout(), "backuppolicy/%s already %s\n", policy.Name, done)
+				return nil
+			}
+			base := policy.DeepCopy()
+			policy.Spec.Suspend = suspend
+			if err := c.Patch(cmd.Context(), policy, client.MergeFrom(base)); err != nil {
+				return fmt.Errorf("failed to update BackupPolicy %s/%s: %w", policy.Namespace, policy.Name, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "backuppolicy/%s %s\n", policy.Name, done)
+			return nil
+		},
+	}
+}
+
+func newVerifyCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify POLICY",
+		Short: "Verify the latest backup of each PVC of a BackupPolicy now",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, namespace, err := o.connect()
+			if err != nil {
+				return err
+			}
+			policy, err := getPolicy(cmd.Context(), c, namespace, args[0])
+			if err != nil {
+				return err
+			}
+			if policy.Spec.Verification == nil {
+				return fmt.Errorf("BackupPolicy %s/%s has no verification configured", policy.Namespace, policy.Name)
+			}
+			if err := requestNow(cmd.Context(), c, policy, backup.AnnotationVerifyNow); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "backuppolicy/%s verification requested\n", policy.Name)
+			return nil
+		},
+	}
+}
+
+// requestNow sets a request annotation to the current time; the operator removes it once the request runs
+func requestNow(ctx context.Context, c client.Client, policy *backupv1alpha1.BackupPolicy, annotation string) error {
+	base := policy.DeepCopy()
+	if policy.Annotations == nil {
+		policy.Annotations = map[string]string{}
+	}
+	policy.Annotations[annotation] = time.Now().UTC().Format(time.RFC3339)
+	if err := c.Patch(ctx, policy, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to annotate BackupPolicy %s/%s: %w", policy.Namespace, policy.Name, err)
+	}
+	return nil
+}
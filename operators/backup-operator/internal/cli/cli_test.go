@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func newTestPolicy() *backupv1alpha1.BackupPolicy {
+	return &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "apps"},
+		Spec:       backupv1alpha1.BackupPolicySpec{Schedule: "0 2 * * *", Strategy: "snapshot"},
+	}
+}
+
+func newTestBackup(name, namespace string, started time.Time) *backupv1alpha1.Backup {
+	return &backupv1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				backup.LabelPolicy:          "nightly",
+				backup.LabelPolicyNamespace: "apps",
+			},
+		},
+		Spec: backupv1alpha1.BackupSpec{
+			PolicyRef: backupv1alpha1.PolicyReference{Name: "nightly", Namespace: "apps"},
+			PVCName:   "data",
+			Strategy:  "snapshot",
+		},
+		Status: backupv1alpha1.BackupStatus{
+			Phase:     backupv1alpha1.BackupPhaseCompleted,
+			Location:  namespace + "/" + name,
+			StartTime: &metav1.Time{Time: started},
+		},
+	}
+}
+
+// run executes a kubectl-backup command line against c and returns its output
+func run(t *testing.T, c client.Client, args ...string) (string, error) {
+	t.Helper()
+	cmd := newRootCommand(&options{client: c})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestListPrintsPolicyBackupsNewestFirst(t *testing.T) {
+	now := time.Now()
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newTestPolicy(),
+		newTestBackup("data-old", "apps", now.Add(-2*time.Hour)),
+		newTestBackup("data-new", "team", now.Add(-time.Hour)),
+	).Build()
+
+	out, err := run(t, fakeClient, "list", "nightly", "-n", "apps")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "NAMESPACE") {
+		t.Fatalf("expected a header and two backups, got:\n%s", out)
+	}
+	if !strings.Contains(lines[1], "data-new") || !strings.Contains(lines[2], "data-old") {
+		t.Fatalf("expected backups of all namespaces newest first, got:\n%s", out)
+	}
+
+	out, err = run(t, fakeClient, "list", "-n", "apps", "-o", "json")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	var stored []backupv1alpha1.StoredBackup
+	if err := json.Unmarshal([]byte(out), &stored); err != nil {
+		t.Fatalf("expected JSON output: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Name != "data-old" || stored[0].Status != backupv1alpha1.BackupPhaseCompleted {
+		t.Fatalf("expected only the backup in namespace apps, got %+v", stored)
+	}
+}
+
+func TestTriggerSuspendAndVerifyUpdatePolicy(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newTestPolicy()).Build()
+	key := types.NamespacedName{Namespace: "apps", Name: "nightly"}
+	ctx := context.Background()
+
+	for _, args := range [][]string{{"trigger", "nightly", "-n", "apps"}, {"suspend", "nightly", "-n", "apps"}} {
+		if _, err := run(t, fakeClient, args...); err != nil {
+			t.Fatalf("%s failed: %v", args[0], err)
+		}
+	}
+	policy := &backupv1alpha1.BackupPolicy{}
+	if err := fakeClient.Get(ctx, key, policy); err != nil {
+		t.Fatalf("failed to get BackupPolicy: %v", err)
+	}
+	if _, err := time.Parse(time.RFC3339, policy.Annotations[backup.AnnotationBackupNow]); err != nil {
+		t.Fatalf("expected a backup request timestamp, got %v", policy.Annotations)
+	}
+	if !policy.Spec.Suspend {
+		t.Fatal("expected the policy to be suspended")
+	}
+
+	if _, err := run(t, fakeClient, "resume", "nightly", "-n", "apps"); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if err := fakeClient.Get(ctx, key, policy); err != nil {
+		t.Fatalf("failed to get BackupPolicy: %v", err)
+	}
+	if policy.Spec.Suspend {
+		t.Fatal("expected the policy to be resumed")
+	}
+
+	if _, err := run(t, fakeClient, "verify", "nightly", "-n", "apps"); err == nil || !strings.Contains(err.Error(), "no verification") {
+		t.Fatalf("expected verify to fail without verification configured, got %v", err)
+	}
+}
+
+func TestRestoreRequestsRestoreFromOperator(t *testing.T) {
+	item := newTestBackup("data-20250101", "apps", time.Now())
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newTestPolicy(), item).Build()
+	ctx := context.Background()
+
+	if _, err := run(t, fakeClient, "restore", item.Name, "-n", "apps"); err == nil {
+		t.Fatal("expected a restore without --to to fail")
+	}
+	if _, err := run(t, fakeClient, "restore", item.Name, "--to", "data-restored", "--target-namespace", "other", "-n", "apps"); err == nil {
+		t.Fatal("expected restoring a snapshot into another namespace to fail")
+	}
+	out, err := run(t, fakeClient, "restore", item.Name, "--to", "data-restored", "--size", "10Gi", "-n", "apps")
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if !strings.Contains(out, "restore requested") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(item), item); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	request := backup.RestoreRequest{}
+	if err := json.Unmarshal([]byte(item.Annotations[backup.AnnotationRestore]), &request); err != nil {
+		t.Fatalf("expected a restore request annotation, got %v: %v", item.Annotations, err)
+	}
+	if request.PVC != "data-restored" || request.Size != "10Gi" || request.RequestedAt == "" {
+		t.Fatalf("unexpected restore request %+v", request)
+	}
+	// The CLI only asks; the operator creates the PVC
+	if err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "apps", Name: "data-restored"}, &corev1.PersistentVolumeClaim{}); err == nil {
+		t.Fatal("expected the CLI not to create the target PVC")
+	}
+}
+
+func TestRestoreRejectsImportedDumps(t *testing.T) {
+	item := newTestBackup("nightly-data-20250101", "dr", time.Now())
+	item.Spec.Strategy = "external"
+	item.Spec.Imported = &backupv1alpha1.ImportSource{Repository: "prod", Namespace: "apps", DumpFile: "/dump/db.sql"}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(item).Build()
+
+	if _, err := run(t, fakeClient, "restore", item.Name, "--to", "db", "--size", "5Gi", "-n", "dr"); err == nil {
+		t.Fatal("expected restoring an imported dump to fail")
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+func newListCommand(o *options) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "list [POLICY]",
+		Short: "List backups, optionally only those of one BackupPolicy",
+		Example: `  # Backups of the nightly policy, wherever its PVCs live
+  kubectl backup list nightly -n apps
+
+  # All backups in the cluster as JSON
+  kubectl backup list -A -o json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != outputTable && output != outputJSON {
+				return fmt.Errorf("unsupported output format %q, use %s or %s", output, outputTable, outputJSON)
+			}
+			c, namespace, err := o.connect()
+			if err != nil {
+				return err
+			}
+
+			var stored []backupv1alpha1.StoredBackup
+			if len(args) == 1 {
+				policy, err := getPolicy(cmd.Context(), c, namespace, args[0])
+				if err != nil {
+					return err
+				}
+				stored, err = policyBackups(cmd.Context(), c, policy)
+				if err != nil {
+					return err
+				}
+			} else {
+				opts := []client.ListOption{}
+				if !o.allNamespaces {
+					opts = append(opts, client.InNamespace(namespace))
+				}
+				stored, err = listBackups(cmd.Context(), c, opts...)
+				if err != nil {
+					return err
+				}
+			}
+
+			if output == outputJSON {
+				return printJSON(cmd.OutOrStdout(), stored)
+			}
+			if len(stored) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No backups found")
+				return nil
+			}
+			return printBackups(cmd.OutOrStdout(), stored)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", outputTable, "Output format: table or json")
+	return cmd
+}
+
+func newDescribeCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe POLICY",
+		Short: "Show the schedule, status and backups of a BackupPolicy",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, namespace, err := o.connect()
+			if err != nil {
+				return err
+			}
+			policy, err := getPolicy(cmd.Context(), c, namespace, args[0])
+			if err != nil {
+				return err
+			}
+			stored, err := policyBackups(cmd.Context(), c, policy)
+			if err != nil {
+				return err
+			}
+			return printPolicy(cmd.OutOrStdout(), policy, stored)
+		},
+	}
+}
+
+// getPolicy reads a BackupPolicy
+func getPolicy(ctx context.Context, c client.Client, namespace, name string) (*backupv1alpha1.BackupPolicy, error) {
+	policy := &backupv1alpha1.BackupPolicy{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, policy); err != nil {
+		return nil, fmt.Errorf("failed to get BackupPolicy %s/%s: %w", namespace, name, err)
+	}
+	return policy, nil
+}
+
+// policyBackups returns the backups of a policy in all namespaces it targets
+func policyBackups(ctx context.Context, c client.Client, policy *backupv1alpha1.BackupPolicy) ([]backupv1alpha1.StoredBackup, error) {
+	return listBackups(ctx, c, client.MatchingLabels{
+		backup.LabelPolicy:          policy.Name,
+		backup.LabelPolicyNamespace: policy.Namespace,
+	})
+}
+
+// listBackups returns the matching Backups as StoredBackups, newest first
+func listBackups(ctx context.Context, c client.Client, opts ...client.ListOption) ([]backupv1alpha1.StoredBackup, error) {
+	list := &backupv1alpha1.BackupList{}
+	if err := c.List(ctx, list, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list Backups: %w", err)
+	}
+
+	stored := make([]backupv1alpha1.StoredBackup, 0, len(list.Items))
+	for i := range list.Items {
+		stored = append(stored, *backup.StoredBackupFor(&list.Items[i]))
+	}
+	sort.SliceStable(stored, func(i, j int) bool {
+		if stored[i].Timestamp.Equal(stored[j].Timestamp) {
+			return stored[i].Namespace+"/"+stored[i].Name > stored[j].Namespace+"/"+stored[j].Name
+		}
+		return stored[j].Timestamp.Before(stored[i].Timestamp)
+	})
+	return stored, nil
+}
+
+// printBackups writes backups as a kubectl style table
+func printBackups(out io.Writer, stored []backupv1alpha1.StoredBackup) error {
+	w := tabwriter.NewWriter(out, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tPVC\tSTRATEGY\tSTATUS\tSIZE\tSNAPSHOT\tAGE")
+	for _, s := range stored {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Namespace, s.Name, s.PVCName, s.Strategy, valueOrNone(s.Status), valueOrNone(s.Size),
+			valueOrNone(shortSnapshotID(s.SnapshotID)), age(s.Timestamp))
+	}
+	return w.Flush()
+}
+
+// printPolicy writes a human readable description of a policy and its backups
+func printPolicy(out io.Writer, policy *backupv1alpha1.BackupPolicy, stored []backupv1alpha1.StoredBackup) error {
+	strategy := policy.Spec.Strategy
+	if strategy == "" {
+		strategy = "snapshot"
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", policy.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", policy.Namespace)
+	fmt.Fprintf(w, "Schedule:\t%s\n", policy.Spec.Schedule)
+	fmt.Fprintf(w, "Suspended:\t%t\n", policy.Spec.Suspend)
+	fmt.Fprintf(w, "Strategy:\t%s\n", strategy)
+	if strategy == "external" {
+		fmt.Fprintf(w, "Destination:\t%s %s\n", policy.Spec.Destination.Type, policy.Spec.Destination.URL)
+	}
+	if v := policy.Spec.Verification; v != nil {
+		fmt.Fprintf(w, "Verification:\t%s\n", v.Schedule)
+	}
+	fmt.Fprintf(w, "Phase:\t%s\n", valueOrNone(policy.Status.Phase))
+	fmt.Fprintf(w, "Last Backup:\t%s\n", timestamp(policy.Status.LastBackupTime))
+	fmt.Fprintf(w, "Next Backup:\t%s\n", timestamp(policy.Status.NextRunTime))
+	if policy.Spec.Verification != nil {
+		fmt.Fprintf(w, "Last Verification:\t%s\n", timestamp(policy.Status.LastVerificationTime))
+		fmt.Fprintf(w, "Next Verification:\t%s\n", timestamp(policy.Status.NextVerificationTime))
+	}
+	fmt.Fprintf(w, "Backups:\t%d (%d completed, %d failed)\n",
+		policy.Status.BackupCount, policy.Status.CompletedBackups, policy.Status.FailedBackups)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(policy.Status.Conditions) > 0 {
+		fmt.Fprintln(out, "\nConditions:")
+		w = tabwriter.NewWriter(out, 0, 4, 3, ' ', 0)
+		fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tMESSAGE")
+		for _, c := range policy.Status.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, c.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(out, "\nBackups:")
+	if len(stored) == 0 {
+		fmt.Fprintln(out, "  <none>")
+		return nil
+	}
+	return printBackups(out, stored)
+}
+
+// printJSON writes v as indented JSON
+func printJSON(out io.Writer, v any) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func age(t *metav1.Time) string {
+	if t == nil || t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t.Time))
+}
+
+func timestamp(t *metav1.Time) string {
+	if t == nil {
+		return "<none>"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// shortSnapshotID shortens a restic snapshot ID the way restic prints it
+func shortSnapshotID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
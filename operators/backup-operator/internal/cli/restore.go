@@ -0,0 +1,137 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// restoreOptions are the flags of the restore command
+type restoreOptions struct {
+	target          string
+	targetNamespace string
+	storageClass    string
+	size            string
+}
+
+func newRestoreCommand(o *options) *cobra.Command {
+	ro := &restoreOptions{}
+	cmd := &cobra.Command{
+		Use:   "restore BACKUP --to PVC",
+		Short: "Restore a backup into a PVC in the namespace of the backup",
+		Long: `Restore a backup into a PVC in the namespace of the backup.
+
+The operator carries out the restore with its own permissions: the command annotates the Backup and
+returns, and the outcome is recorded in the Backup's status.lastRestore and events.
+
+External backups are restored by a Job into the target PVC, which is created when it does not exist.
+Files on an existing PVC are overwritten, so stop the workload using it first.
+Snapshot backups can only be restored into a new PVC.
+
+New PVCs copy the access modes, storage class and size of the PVC the backup was taken from.
+
+External backups can be restored into another namespace with --target-namespace. Backups imported by a
+BackupRepository are restored into the namespace mapped from their source namespace and, without --to,
+into a PVC named like the source PVC; their size has to be set with --size.`,
+		Example: `  # Restore into a new PVC next to the original
+  kubectl backup restore data-db-0-20250101-020000 --to data-db-0-restored -n apps
+
+  # Restore a backup imported from the lost cluster into its mapped namespace
+  kubectl backup restore data-db-0-20250101-020000 --size 20Gi -n backup-system`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, namespace, err := o.connect()
+			if err != nil {
+				return err
+			}
+			return runRestore(cmd, c, namespace, args[0], ro)
+		},
+	}
+	cmd.Flags().StringVar(&ro.target, "to", "", "Name of the PVC to restore into; the source PVC name for imported backups")
+	cmd.Flags().StringVar(&ro.targetNamespace, "target-namespace", "", "Namespace to restore into (external backups only)")
+	cmd.Flags().StringVar(&ro.storageClass, "storage-class", "", "Storage class of a new target PVC")
+	cmd.Flags().StringVar(&ro.size, "size", "", "Requested size of a new target PVC, e.g. 20Gi")
+	return cmd
+}
+
+func runRestore(cmd *cobra.Command, c client.Client, namespace, name string, ro *restoreOptions) error {
+	ctx := cmd.Context()
+
+	item := &backupv1alpha1.Backup{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, item); err != nil {
+		return fmt.Errorf("failed to get Backup %s/%s: %w", namespace, name, err)
+	}
+	if item.Status.Phase != backupv1alpha1.BackupPhaseCompleted {
+		return fmt.Errorf("backup %s/%s is %s, only completed backups can be restored", item.Namespace, item.Name, item.Status.Phase)
+	}
+	if imported := item.Spec.Imported; imported != nil && imported.DumpFile != "" {
+		return fmt.Errorf("backup %s/%s holds the database dump %s; load it from \"restic dump\" output", item.Namespace, item.Name, imported.DumpFile)
+	}
+	if ro.targetNamespace != "" && item.Spec.Strategy == "snapshot" && ro.targetNamespace != item.Namespace {
+		return fmt.Errorf("snapshot backups can only be restored into the namespace of their VolumeSnapshot")
+	}
+	if ro.target == "" && item.Spec.Imported == nil {
+		return fmt.Errorf("set the PVC to restore into with --to")
+	}
+	if ro.size != "" {
+		if _, err := resource.ParseQuantity(ro.size); err != nil {
+			return fmt.Errorf("invalid --size %q: %w", ro.size, err)
+		}
+	}
+
+	request, err := json.Marshal(backup.RestoreRequest{
+		PVC:          ro.target,
+		Namespace:    ro.targetNamespace,
+		StorageClass: ro.storageClass,
+		Size:         ro.size,
+		RequestedAt:  time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	base := item.DeepCopy()
+	if item.Annotations == nil {
+		item.Annotations = map[string]string{}
+	}
+	item.Annotations[backup.AnnotationRestore] = string(request)
+	if err := c.Patch(ctx, item, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to annotate Backup %s/%s: %w", item.Namespace, item.Name, err)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "backup/%s restore requested\n", item.Name)
+	fmt.Fprintf(out, "Check its outcome with: kubectl get backup %s -n %s -o jsonpath='{.status.lastRestore}'\n", item.Name, item.Namespace)
+	if item.Spec.Strategy == "snapshot" {
+		return nil
+	}
+	if item.Status.Manifests != "" {
+		fmt.Fprintf(out, "Missing objects are re-created from the backup manifests; Deployments and StatefulSets start with 0 replicas.\n")
+		fmt.Fprintf(out, "Scale them to the count in their %s annotation once the restore Job completed.\n", backup.AnnotationRestoredReplicas)
+	}
+	fmt.Fprintf(out, "Follow the restore Job with: kubectl get jobs -A -l %s=%s\n", backup.LabelRestoredBackup, item.Name)
+	return nil
+}
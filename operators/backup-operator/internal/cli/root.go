@@ -0,0 +1,112 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cli implements the kubectl-backup plugin commands
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(backupv1alpha1.AddToScheme(scheme))
+}
+
+// options holds the cluster connection flags shared by all commands
+type options struct {
+	kubeconfig    string
+	context       string
+	namespace     string
+	allNamespaces bool
+
+	// client is set by tests; otherwise it is built from the kubeconfig
+	client client.Client
+}
+
+// NewRootCommand returns the kubectl-backup command with all subcommands
+func NewRootCommand() *cobra.Command {
+	return newRootCommand(&options{})
+}
+
+func newRootCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "kubectl-backup",
+		Short:        "Inspect and operate BackupPolicies and their backups",
+		SilenceUsage: true,
+	}
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	flags.StringVar(&o.context, "context", "", "Name of the kubeconfig context to use")
+	flags.StringVarP(&o.namespace, "namespace", "n", "", "Namespace of the BackupPolicy or Backup (defaults to the context namespace)")
+	flags.BoolVarP(&o.allNamespaces, "all-namespaces", "A", false, "List backups in all namespaces")
+
+	cmd.AddCommand(
+		newListCommand(o),
+		newDescribeCommand(o),
+		newTriggerCommand(o),
+		newSuspendCommand(o, true),
+		newSuspendCommand(o, false),
+		newVerifyCommand(o),
+		newRestoreCommand(o),
+	)
+	return cmd
+}
+
+// connect returns the client and the namespace commands operate in
+func (o *options) connect() (client.Client, string, error) {
+	if o.client != nil {
+		namespace := o.namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		return o.client, namespace, nil
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = o.kubeconfig
+	config := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{
+		CurrentContext: o.context,
+		Context:        clientcmdapi.Context{Namespace: o.namespace},
+	})
+
+	namespace, _, err := config.Namespace()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to determine namespace: %w", err)
+	}
+	restConfig, err := config.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create client: %w", err)
+	}
+	return c, namespace, nil
+}
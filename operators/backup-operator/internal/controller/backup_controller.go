@@ -0,0 +1,187 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// Requeue interval while an artifact deletion or the backup itself is still running
+const requeueWhileDeleting = 30 * time.Second
+
+const (
+	// maxDeletionAttempts is how often the artifact of a Backup is deleted before the operator gives up
+	maxDeletionAttempts = 4
+	// deletionRetryBackoff is the delay before the first retry of a failed deletion; it doubles after every attempt
+	deletionRetryBackoff = time.Minute
+)
+
+// BackupReconciler restores Backups on request and deletes their storage artifact before the Backup object
+// goes away
+type BackupReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backups,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backups/finalizers,verbs=update
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims;configmaps;services,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create
+
+// Reconcile adds the artifact finalizer to Backups, carries out restores requested with the restore
+// annotation and runs the strategy's DeleteBackup when Backups are deleted
+func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	item := &backupv1alpha1.Backup{}
+	if err := r.Get(ctx, req.NamespacedName, item); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if _, requested := item.Annotations[backup.AnnotationRestore]; requested && item.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.reconcileRestore(ctx, item)
+	}
+
+	// Imported backups are read-only, their snapshots belong to the cluster that wrote them
+	if item.Spec.Imported != nil {
+		if controllerutil.ContainsFinalizer(item, backup.BackupFinalizer) {
+			return ctrl.Result{}, r.removeFinalizer(ctx, item)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if item.DeletionTimestamp.IsZero() {
+		// Backups created before the finalizer existed get it on their first reconcile
+		if controllerutil.AddFinalizer(item, backup.BackupFinalizer) {
+			return ctrl.Result{}, r.Update(ctx, item)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(item, backup.BackupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// A running backup would write its snapshot after the deletion, so wait for it to finish
+	if runsInJob(item) {
+		logger.Info("Backup is still running, postponing deletion", "backup", item.Name)
+		return ctrl.Result{RequeueAfter: requeueWhileDeleting}, nil
+	}
+
+	if deletion := item.Status.Deletion; deletion != nil && deletion.Attempts > 0 {
+		if deletion.Attempts >= maxDeletionAttempts {
+			return ctrl.Result{}, nil
+		}
+		if wait := time.Until(deletion.LastFailureTime.Add(deletionRetryBackoff << (deletion.Attempts - 1))); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	policy := &backupv1alpha1.BackupPolicy{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: item.Spec.PolicyRef.Namespace, Name: item.Spec.PolicyRef.Name}, policy)
+	switch {
+	case apierrors.IsNotFound(err):
+		// Without the policy the destination and credentials are unknown
+		backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonDeleteFailed,
+			fmt.Sprintf("BackupPolicy %s/%s no longer exists, leaving the artifact at %s in place",
+				item.Spec.PolicyRef.Namespace, item.Spec.PolicyRef.Name, item.Status.Location), item)
+		return ctrl.Result{}, r.removeFinalizer(ctx, item)
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+
+	strategy, err := newBackupStrategy(ctx, r.Client, r.Recorder, item.Spec.Strategy, policy, nil)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := strategy.DeleteBackup(ctx, backup.StoredBackupFor(item), policy); err != nil {
+		if errors.Is(err, backup.ErrDeletionInProgress) {
+			logger.Info("Waiting for backup artifact deletion", "backup", item.Name)
+			return ctrl.Result{RequeueAfter: requeueWhileDeleting}, nil
+		}
+		if errors.Is(err, backup.ErrDeletionFailed) {
+			return r.recordDeletionFailure(ctx, item, err)
+		}
+		backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonDeleteFailed, fmt.Sprintf("Failed to delete backup artifact: %v", err), item)
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("Deleted backup artifact", "backup", item.Name, "location", item.Status.Location)
+	backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonBackupDeleted,
+		fmt.Sprintf("Deleted backup %s/%s from %s storage", item.Namespace, item.Name, item.Spec.Strategy), policy)
+	return ctrl.Result{}, r.removeFinalizer(ctx, item)
+}
+
+// recordDeletionFailure counts a failed deletion attempt in the status and schedules the next one
+func (r *BackupReconciler) recordDeletionFailure(ctx context.Context, item *backupv1alpha1.Backup, cause error) (ctrl.Result, error) {
+	now := metav1.Now()
+	deletion := item.Status.Deletion
+	if deletion == nil {
+		deletion = &backupv1alpha1.DeletionStatus{}
+		item.Status.Deletion = deletion
+	}
+	deletion.Attempts++
+	deletion.LastFailureTime = &now
+	deletion.Message = cause.Error()
+	if err := r.Status().Update(ctx, item); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if deletion.Attempts >= maxDeletionAttempts {
+		backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonDeleteFailed,
+			fmt.Sprintf("Giving up deleting the backup artifact after %d attempts: %v. Remove status.deletion to retry "+
+				"or the %s finalizer to leave the artifact in place", deletion.Attempts, cause, backup.BackupFinalizer), item)
+		return ctrl.Result{}, nil
+	}
+	retryAfter := deletionRetryBackoff << (deletion.Attempts - 1)
+	backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonDeleteFailed,
+		fmt.Sprintf("Failed to delete backup artifact, retrying in %s: %v", retryAfter, cause), item)
+	return ctrl.Result{RequeueAfter: retryAfter}, nil
+}
+
+func (r *BackupReconciler) removeFinalizer(ctx context.Context, item *backupv1alpha1.Backup) error {
+	controllerutil.RemoveFinalizer(item, backup.BackupFinalizer)
+	return client.IgnoreNotFound(r.Update(ctx, item))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1alpha1.Backup{}).
+		Named("backup").
+		Complete(r)
+}
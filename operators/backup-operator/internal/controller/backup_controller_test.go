@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func newBackupReconciler(t *testing.T, objects ...client.Object) *BackupReconciler {
+	t.Helper()
+	scheme := newTestScheme(t)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&backupv1alpha1.Backup{}).Build()
+	return &BackupReconciler{Client: fakeClient, Scheme: scheme}
+}
+
+func deletingBackup(phase string) *backupv1alpha1.Backup {
+	item := newBackupRecord("policy-data-1", "data", phase, time.Now())
+	item.Finalizers = []string{backup.BackupFinalizer}
+	now := metav1.Now()
+	item.DeletionTimestamp = &now
+	return item
+}
+
+func TestBackupReconcilerAddsFinalizer(t *testing.T) {
+	item := newBackupRecord("policy-data-1", "data", backupv1alpha1.BackupPhaseCompleted, time.Now())
+	r := newBackupReconciler(t, item)
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(item)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(item), item); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if len(item.Finalizers) != 1 || item.Finalizers[0] != backup.BackupFinalizer {
+		t.Fatalf("expected artifact finalizer, got %v", item.Finalizers)
+	}
+}
+
+func TestBackupReconcilerWaitsForRunningBackup(t *testing.T) {
+	item := deletingBackup(backupv1alpha1.BackupPhaseRunning)
+	r := newBackupReconciler(t, item)
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(item)})
+	if err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Fatalf("expected a requeue while the backup is running")
+	}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(item), item); err != nil {
+		t.Fatalf("expected Backup to be kept while running: %v", err)
+	}
+}
+
+func TestBackupReconcilerReleasesBackupWithoutPolicy(t *testing.T) {
+	item := deletingBackup(backupv1alpha1.BackupPhaseCompleted)
+	r := newBackupReconciler(t, item)
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(item)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(item), item); err == nil {
+		t.Fatalf("expected Backup to be gone once its finalizer was removed, finalizers %v", item.Finalizers)
+	}
+}
+
+func TestBackupReconcilerLeavesImportedBackups(t *testing.T) {
+	item := newBackupRecord("policy-data-1", "data", backupv1alpha1.BackupPhaseCompleted, time.Now())
+	item.Spec.Imported = &backupv1alpha1.ImportSource{Repository: "prod", Namespace: "apps"}
+	r := newBackupReconciler(t, item)
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(item)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(item), item); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if len(item.Finalizers) != 0 {
+		t.Fatalf("expected no finalizer on an imported Backup, got %v", item.Finalizers)
+	}
+}
+
+func TestBackupReconcilerRetriesFailedDeletionWithLimit(t *testing.T) {
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Strategy:    "external",
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://bucket/backups"},
+		},
+	}
+	item := deletingBackup(backupv1alpha1.BackupPhaseCompleted)
+	failed := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: item.Name + "-forget", Namespace: "ns"},
+		Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}},
+	}
+	r := newBackupReconciler(t, policy, item, failed)
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(item)}
+
+	result, err := r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if result.RequeueAfter != deletionRetryBackoff {
+		t.Fatalf("expected a retry after %s, got %s", deletionRetryBackoff, result.RequeueAfter)
+	}
+	if err := r.Get(ctx, req.NamespacedName, item); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if item.Status.Deletion == nil || item.Status.Deletion.Attempts != 1 {
+		t.Fatalf("expected the failed attempt to be counted, got %+v", item.Status.Deletion)
+	}
+
+	// No new forget Job is started during the backoff
+	if result, err = r.Reconcile(ctx, req); err != nil || result.RequeueAfter == 0 {
+		t.Fatalf("expected to wait for the backoff, got %v %v", result, err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(failed), &batchv1.Job{}); err == nil {
+		t.Fatal("expected no forget Job during the backoff")
+	}
+
+	// After the last attempt the Backup is left for the user
+	item.Status.Deletion.Attempts = maxDeletionAttempts
+	if err := r.Status().Update(ctx, item); err != nil {
+		t.Fatalf("failed to update Backup status: %v", err)
+	}
+	if result, err = r.Reconcile(ctx, req); err != nil || result.RequeueAfter != 0 {
+		t.Fatalf("expected no more retries, got %v %v", result, err)
+	}
+	if err := r.Get(ctx, req.NamespacedName, item); err != nil {
+		t.Fatalf("expected the Backup to keep its finalizer: %v", err)
+	}
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -27,15 +29,24 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
 	"github.com/example/backup-operator/internal/backup"
+	"github.com/example/backup-operator/internal/metrics"
+	"github.com/example/backup-operator/internal/notify"
 	"github.com/example/backup-operator/internal/storage"
 )
 
@@ -44,24 +55,46 @@ const (
 	defaultStrategy = "snapshot"
 
 	// Requeue intervals
-	requeueAfterError     = 1 * time.Minute
-	requeueAfterSuccess   = 5 * time.Minute
-	requeueWhileJobActive = 1 * time.Minute
+	requeueAfterError   = 1 * time.Minute
+	requeueAfterSuccess = 5 * time.Minute
+	// Finished Jobs enqueue their policy through the Job watch; this only bounds missed events and stuck Jobs
+	requeueWhileJobActive = 5 * time.Minute
+	// VolumeSnapshots are not watched since their CRDs may be missing, so pending ones are polled
+	requeueWhileSnapshotPending = 30 * time.Second
+
+	// Job history kept when the policy does not set its limits, as for CronJobs
+	defaultSuccessfulJobsHistoryLimit = 3
+	defaultFailedJobsHistoryLimit     = 1
+	// Time a Job may run past its deadline before it is deleted as stuck
+	stuckJobGracePeriod = 5 * time.Minute
 )
 
 // BackupPolicyReconciler reconciles a BackupPolicy object
 type BackupPolicyReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Notifier *notify.Notifier
+	// Throttle applied to backup Jobs of policies that leave spec.throttle fields unset
+	DefaultThrottle *backupv1alpha1.Throttle
 }
 
 // +kubebuilder:rbac:groups=backup.backup.example.com,resources=backuppolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=backup.backup.example.com,resources=backuppolicies/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=backup.backup.example.com,resources=backuppolicies/finalizers,verbs=update
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backups/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
-// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop
 func (r *BackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -72,18 +105,73 @@ func (r *BackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
 		if errors.IsNotFound(err) {
 			logger.Info("BackupPolicy not found, ignoring")
+			metrics.DeletePolicy(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "Failed to get BackupPolicy")
 		return ctrl.Result{}, err
 	}
 
+	// Status changes of the whole reconcile are written in one patch so partial updates cannot conflict
+	original := policy.Status.DeepCopy()
+	var result ctrl.Result
+	var err error
+	if !policy.DeletionTimestamp.IsZero() {
+		result, err = r.finalizePolicy(ctx, policy)
+	} else {
+		result, err = r.reconcilePolicy(ctx, policy)
+		result = r.requeueForBackups(ctx, policy, result)
+	}
+	if patchErr := r.patchStatus(ctx, policy, original); patchErr != nil {
+		logger.Error(patchErr, "Failed to patch BackupPolicy status")
+		if err == nil {
+			return ctrl.Result{}, patchErr
+		}
+	}
+	return result, err
+}
+
+// requeueForBackups shortens the requeue while snapshot Backups of the policy wait for their VolumeSnapshot
+// or a failed replica copy is due for a retry
+func (r *BackupPolicyReconciler) requeueForBackups(ctx context.Context, policy *backupv1alpha1.BackupPolicy, result ctrl.Result) ctrl.Result {
+	items, err := r.listBackups(ctx, policy)
+	if err != nil {
+		return result
+	}
+	shorten := func(delay time.Duration) {
+		if result.RequeueAfter <= 0 || delay < result.RequeueAfter {
+			result.RequeueAfter = delay
+		}
+	}
+	if hasPendingSnapshots(items) {
+		shorten(requeueWhileSnapshotPending)
+	}
+	if retryAt, ok := nextReplicaRetry(policy, items); ok {
+		shorten(max(time.Until(retryAt), time.Second))
+	}
+	return result
+}
+
+// reconcilePolicy schedules the backups of a policy that is not being deleted; status changes are left to the caller
+func (r *BackupPolicyReconciler) reconcilePolicy(ctx context.Context, policy *backupv1alpha1.BackupPolicy) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if controllerutil.AddFinalizer(policy, backup.PolicyFinalizer) {
+		if err := r.updatePolicy(ctx, policy); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	logger.Info("Reconciling BackupPolicy",
 		"name", policy.Name,
 		"namespace", policy.Namespace,
 		"strategy", policy.Spec.Strategy)
 
-	// Check and update status of running backup Jobs
+	if len(policy.Status.StoredBackups) > 0 {
+		r.migrateStoredBackups(ctx, policy)
+	}
+
+	// Check and update status of running backup Jobs and VolumeSnapshots
 	if err := r.handleJobCompletion(ctx, policy); err != nil {
 		logger.Error(err, "Failed to handle Job completion")
 		// Continue with reconciliation even if this fails
@@ -103,9 +191,10 @@ func (r *BackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	// Get backup strategy implementation
 	backupStrategy, err := r.getBackupStrategy(ctx, strategy, policy)
+	r.checkDestination(ctx, policy, backupStrategy, err)
 	if err != nil {
 		logger.Error(err, "Failed to get backup strategy", "strategy", strategy)
-		r.updateStatus(ctx, policy, "Error", err.Error())
+		r.setPhase(policy, "Error", err.Error())
 		return ctrl.Result{RequeueAfter: requeueAfterError}, err
 	}
 
@@ -113,42 +202,82 @@ func (r *BackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	pvcs, err := r.findTargetPVCs(ctx, policy)
 	if err != nil {
 		logger.Error(err, "Failed to find target PVCs")
-		r.updateStatus(ctx, policy, "Error", err.Error())
+		r.setPhase(policy, "Error", err.Error())
 		return ctrl.Result{RequeueAfter: requeueAfterError}, err
 	}
 
+	// Keep credential copies in PVC namespaces in sync and remove those no longer needed
+	if err := r.reconcileCredentialCopies(ctx, policy, pvcs); err != nil {
+		logger.Error(err, "Failed to reconcile copied credential Secrets")
+		// Continue with reconciliation even if this fails
+	}
+
+	// Progress any requested encryption key rotation before scheduling new work
+	if err := r.reconcileKeyRotation(ctx, policy, backupStrategy, pvcs); err != nil {
+		logger.Error(err, "Failed to reconcile encryption key rotation")
+		// Continue with reconciliation even if this fails
+	}
+
+	// Copy completed backups to the replica destinations
+	if !policy.Spec.Suspend {
+		if err := r.runReplication(ctx, policy, backupStrategy); err != nil {
+			logger.Error(err, "Failed to run backup replication")
+			// Continue with reconciliation even if this fails
+		}
+	}
+
 	if len(pvcs) == 0 {
 		logger.Info("No PVCs found matching selector")
-		r.updateStatus(ctx, policy, "Active", "No PVCs found")
+		r.summarizeTargets(ctx, policy, pvcs)
+		r.setPhase(policy, "Active", "No PVCs found")
 		return ctrl.Result{RequeueAfter: requeueAfterSuccess}, nil
 	}
 
 	logger.Info("Found target PVCs", "count", len(pvcs))
 
+	// Start verification Jobs if the verification schedule is due
+	if err := r.runVerification(ctx, policy, backupStrategy, pvcs); err != nil {
+		logger.Error(err, "Failed to run backup verification")
+		// Continue with reconciliation even if this fails
+	}
+
+	// A backup-now request runs outside the schedule, even while the policy is suspended
+	_, triggered := policy.Annotations[backup.AnnotationBackupNow]
+	if policy.Spec.Suspend && !triggered {
+		// Keep the next slot in the future so resuming does not start the missed runs at once
+		nextRun, _ := r.nextRun(policy, time.Now())
+		policy.Status.NextRunTime = &metav1.Time{Time: nextRun}
+		r.setPhase(policy, "Suspended", "Scheduled backups are suspended")
+		return ctrl.Result{RequeueAfter: requeueAfterSuccess}, nil
+	}
+
 	// Check if it's time to backup (based on schedule)
 	shouldBackup, nextRun := r.shouldBackupNow(policy)
+	if triggered {
+		shouldBackup, nextRun = true, time.Now()
+	}
 	if !shouldBackup {
 		logger.Info("Not time to backup yet", "nextRun", nextRun)
-		r.updateStatusWithNextRun(ctx, policy, "Active", nextRun)
-		return ctrl.Result{RequeueAfter: time.Until(nextRun)}, nil
+		r.setPhaseWithNextRun(policy, "Active", nextRun)
+		return ctrl.Result{RequeueAfter: requeueAfter(policy, nextRun)}, nil
 	}
 
-	activeJobs, err := r.hasActiveBackupJobs(ctx, policy, pvcs)
+	activeJobs, err := r.hasActiveBackupJobs(ctx, policy)
 	if err != nil {
 		logger.Error(err, "Failed to check for active backup Jobs")
-		r.updateStatus(ctx, policy, "Error", "Failed to check running Jobs")
+		r.setPhase(policy, "Error", "Failed to check running Jobs")
 		return ctrl.Result{RequeueAfter: requeueAfterError}, err
 	}
 	if activeJobs {
 		logger.Info("Previous backup Jobs are still running, skipping new run")
-		r.updateStatusWithNextRun(ctx, policy, "Active", nextRun)
+		r.setPhaseWithNextRun(policy, "Active", nextRun)
 		return ctrl.Result{RequeueAfter: requeueWhileJobActive}, nil
 	}
 
-	// Track previous backups so we can merge with the new run
-	existingBackups := make(map[string]backupv1alpha1.StoredBackup)
-	for _, stored := range policy.Status.StoredBackups {
-		existingBackups[backupKey(stored.Namespace, stored.Name)] = stored
+	if !triggered && policy.Status.LastBackupTime != nil && missedSchedule(policy.Spec.Schedule, nextRun, time.Now()) {
+		message := fmt.Sprintf("Scheduled backup at %s did not run on time, starting it now", nextRun.Format(time.RFC3339))
+		logger.Info("Backup schedule missed", "scheduled", nextRun)
+		r.notify(ctx, policy, notify.Event{Type: backupv1alpha1.NotificationScheduleMissed, Message: message})
 	}
 
 	// Perform backup for each PVC
@@ -160,24 +289,27 @@ func (r *BackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		result, err := backupStrategy.Backup(ctx, &pvc, policy)
 		if err != nil {
 			logger.Error(err, "Failed to backup PVC", "pvc", pvc.Name)
+			metrics.RecordBackupFailure(policy.Namespace, policy.Name, metrics.ReasonJobCreationFailed)
+			message := fmt.Sprintf("Failed to start backup of PVC %s/%s: %v", pvc.Namespace, pvc.Name, err)
+			backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonBackupFailed, message, policy, &pvc)
+			r.notify(ctx, policy, notify.Event{
+				Type:      backupv1alpha1.NotificationBackupFailed,
+				Namespace: pvc.Namespace,
+				PVC:       pvc.Name,
+				Message:   message,
+			})
 			backupErrors++
 			continue
 		}
 
-		// Add to stored backups with Running status
-		// Status will be updated when Job completes via Job watch event
-		storedBackup := backupv1alpha1.StoredBackup{
-			Name:      result.Name,
-			Timestamp: &metav1.Time{Time: result.Timestamp},
-			PVCName:   pvc.Name,
-			Namespace: pvc.Namespace,
-			Size:      result.Size,
-			Location:  result.Location,
-			Status:    "Running",
-			Strategy:  strategy,
-		}
+		backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonBackupStarted,
+			fmt.Sprintf("Started %s backup %s of PVC %s/%s", strategy, result.Name, pvc.Namespace, pvc.Name), policy, &pvc)
 
-		existingBackups[backupKey(storedBackup.Namespace, storedBackup.Name)] = storedBackup
+		// Record the backup as Running; handleJobCompletion updates it when the Job or VolumeSnapshot finishes
+		if err := r.createBackupRecord(ctx, policy, &pvc, strategy, result); err != nil {
+			logger.Error(err, "Failed to create Backup resource", "backup", result.Name)
+			backupErrors++
+		}
 
 		// Run cleanup to remove old backups
 		if err := backupStrategy.Cleanup(ctx, &pvc, policy); err != nil {
@@ -185,9 +317,14 @@ func (r *BackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}
 
-	// Update status with the combined view of old + new backups
-	policy.Status.StoredBackups = normalizeStoredBackups(existingBackups)
-	policy.Status.BackupCount = len(policy.Status.StoredBackups)
+	if triggered {
+		logger.Info("Started requested backup", "requestedAt", policy.Annotations[backup.AnnotationBackupNow])
+		if err := r.clearAnnotation(ctx, policy, backup.AnnotationBackupNow); err != nil {
+			logger.Error(err, "Failed to clear backup request")
+		}
+	}
+
+	r.summarizeTargets(ctx, policy, pvcs)
 
 	nextRun, nextRunErr := r.nextRun(policy, time.Now())
 	if nextRunErr != nil {
@@ -197,28 +334,50 @@ func (r *BackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	if backupErrors > 0 {
 		msg := fmt.Sprintf("Backup completed with %d errors", backupErrors)
-		r.updateStatus(ctx, policy, "Error", msg)
+		r.setPhase(policy, "Error", msg)
 		return ctrl.Result{RequeueAfter: requeueAfterError}, fmt.Errorf("backup completed with %d errors", backupErrors)
 	}
 
-	r.updateStatus(ctx, policy, "Active", "Backup completed successfully")
+	if policy.Spec.Suspend {
+		r.setPhase(policy, "Suspended", "Requested backup started, scheduled backups are suspended")
+		return ctrl.Result{RequeueAfter: requeueAfterSuccess}, nil
+	}
+	r.setPhase(policy, "Active", "Backup completed successfully")
+
+	return ctrl.Result{RequeueAfter: requeueAfter(policy, nextRun)}, nil
+}
 
-	return ctrl.Result{RequeueAfter: time.Until(nextRun)}, nil
+// requeueAfter returns the delay until the next scheduled backup or verification, whichever comes first
+func requeueAfter(policy *backupv1alpha1.BackupPolicy, nextRun time.Time) time.Duration {
+	next := nextRun
+	if policy.Spec.Verification != nil && policy.Status.NextVerificationTime != nil &&
+		policy.Status.NextVerificationTime.Before(&metav1.Time{Time: next}) {
+		next = policy.Status.NextVerificationTime.Time
+	}
+	if check, ok := nextDestinationCheck(policy); ok && check.Before(next) {
+		next = check
+	}
+	return time.Until(next)
 }
 
 // getBackupStrategy returns the appropriate backup strategy based on the policy
 func (r *BackupPolicyReconciler) getBackupStrategy(ctx context.Context, strategy string, policy *backupv1alpha1.BackupPolicy) (backup.Strategy, error) {
+	return newBackupStrategy(ctx, r.Client, r.Recorder, strategy, policy, r.DefaultThrottle)
+}
+
+// newBackupStrategy creates the strategy implementation for a policy; shared by the policy and Backup reconcilers
+func newBackupStrategy(ctx context.Context, c client.Client, recorder record.EventRecorder, strategy string, policy *backupv1alpha1.BackupPolicy, defaultThrottle *backupv1alpha1.Throttle) (backup.Strategy, error) {
 	switch strategy {
 	case "snapshot":
-		return backup.NewSnapshotStrategy(r.Client), nil
+		return backup.NewSnapshotStrategy(c, recorder), nil
 
 	case "external":
 		// Get storage backend configuration
-		backend, err := r.getStorageBackend(ctx, policy)
+		backend, err := getStorageBackend(ctx, c, policy)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get storage backend: %w", err)
 		}
-		return backup.NewExternalStrategy(r.Client, backend), nil
+		return backup.NewExternalStrategy(c, backend, recorder, defaultThrottle), nil
 
 	default:
 		return nil, fmt.Errorf("unknown backup strategy: %s", strategy)
@@ -226,7 +385,7 @@ func (r *BackupPolicyReconciler) getBackupStrategy(ctx context.Context, strategy
 }
 
 // getStorageBackend creates a storage backend based on the destination configuration
-func (r *BackupPolicyReconciler) getStorageBackend(ctx context.Context, policy *backupv1alpha1.BackupPolicy) (storage.Backend, error) {
+func getStorageBackend(ctx context.Context, c client.Reader, policy *backupv1alpha1.BackupPolicy) (storage.Backend, error) {
 	dest := policy.Spec.Destination
 
 	if dest.Type == "" {
@@ -247,6 +406,28 @@ func (r *BackupPolicyReconciler) getStorageBackend(ctx context.Context, policy *
 		config.Bucket = bucket
 		config.Prefix = prefix
 		config.Endpoint = dest.Endpoint
+		if dest.Auth != nil {
+			config.RoleARN = dest.Auth.RoleARN
+			config.ExternalID = dest.Auth.ExternalID
+		}
+		if sse := dest.ServerSideEncryption; sse != nil {
+			config.ServerSideEncryption = sse.Algorithm
+			config.KMSKeyID = sse.KMSKeyID
+		}
+		if lock := dest.ObjectLock; lock != nil {
+			config.ObjectLockMode = lock.Mode
+			config.ObjectLockRetentionDays = lock.RetentionDays
+		}
+		if transfer := dest.Transfer; transfer != nil {
+			if transfer.PartSize != nil {
+				config.PartSize = transfer.PartSize.Value()
+			}
+			if transfer.BandwidthLimit != nil {
+				config.BandwidthLimit = transfer.BandwidthLimit.Value()
+			}
+			config.Concurrency = int(transfer.Concurrency)
+			config.ChecksumAlgorithm = transfer.ChecksumAlgorithm
+		}
 
 	case "nfs":
 		config.Prefix = dest.URL
@@ -259,7 +440,7 @@ func (r *BackupPolicyReconciler) getStorageBackend(ctx context.Context, policy *
 	// Load credentials from Secret if specified
 	if dest.CredentialsSecret != "" {
 		secret := &corev1.Secret{}
-		err := r.Get(ctx, types.NamespacedName{
+		err := c.Get(ctx, types.NamespacedName{
 			Name:      dest.CredentialsSecret,
 			Namespace: policy.Namespace,
 		}, secret)
@@ -270,8 +451,11 @@ func (r *BackupPolicyReconciler) getStorageBackend(ctx context.Context, policy *
 
 		// Parse credentials based on storage type
 		if dest.Type == "s3" {
-			config.AccessKey = string(secret.Data["access-key"])
-			config.SecretKey = string(secret.Data["secret-key"])
+			// Keys are ignored with the default credential chain so a leftover Secret cannot override IRSA
+			if !backup.UsesDefaultCredentialChain(policy) {
+				config.AccessKey = string(secret.Data["access-key"])
+				config.SecretKey = string(secret.Data["secret-key"])
+			}
 			config.Region = string(secret.Data["region"])
 
 			// Endpoint can also be in Secret (for backward compatibility)
@@ -297,45 +481,10 @@ func parseS3URL(url string) (bucket, prefix string) {
 	return bucket, prefix
 }
 
-const maxStatusHistory = 200
-
 func backupKey(namespace, name string) string {
 	return fmt.Sprintf("%s/%s", namespace, name)
 }
 
-func normalizeStoredBackups(backups map[string]backupv1alpha1.StoredBackup) []backupv1alpha1.StoredBackup {
-	list := make([]backupv1alpha1.StoredBackup, 0, len(backups))
-	for _, b := range backups {
-		list = append(list, b)
-	}
-
-	sort.SliceStable(list, func(i, j int) bool {
-		ti := storedBackupTime(list[i])
-		tj := storedBackupTime(list[j])
-		if ti.Equal(tj) {
-			// Fall back to name to keep deterministic order
-			if list[i].Namespace == list[j].Namespace {
-				return list[i].Name > list[j].Name
-			}
-			return list[i].Namespace > list[j].Namespace
-		}
-		return ti.After(tj)
-	})
-
-	if len(list) > maxStatusHistory {
-		list = list[:maxStatusHistory]
-	}
-
-	return list
-}
-
-func storedBackupTime(b backupv1alpha1.StoredBackup) time.Time {
-	if b.Timestamp == nil {
-		return time.Time{}
-	}
-	return b.Timestamp.Time
-}
-
 // findTargetPVCs finds all PVCs matching the policy selector
 func (r *BackupPolicyReconciler) findTargetPVCs(ctx context.Context, policy *backupv1alpha1.BackupPolicy) ([]corev1.PersistentVolumeClaim, error) {
 	logger := log.FromContext(ctx)
@@ -370,42 +519,75 @@ func (r *BackupPolicyReconciler) findTargetPVCs(ctx context.Context, policy *bac
 		allPVCs = append(allPVCs, pvcList.Items...)
 	}
 
+	if policy.Spec.Dump != nil {
+		return dumpTargets(allPVCs), nil
+	}
 	return allPVCs, nil
 }
 
-func (r *BackupPolicyReconciler) hasActiveBackupJobs(ctx context.Context, policy *backupv1alpha1.BackupPolicy, pvcs []corev1.PersistentVolumeClaim) (bool, error) {
-	namespaces := map[string]struct{}{
-		policy.Namespace: {},
-	}
-	for _, ns := range policy.Spec.Namespaces {
-		namespaces[ns] = struct{}{}
+// dumpTargets keeps one PVC per namespace, the first by name. A dump reads the database Service of the
+// namespace rather than the volume, so the replica PVCs of a StatefulSet would otherwise dump it repeatedly.
+func dumpTargets(pvcs []corev1.PersistentVolumeClaim) []corev1.PersistentVolumeClaim {
+	slices.SortFunc(pvcs, func(a, b corev1.PersistentVolumeClaim) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+	return slices.CompactFunc(pvcs, func(a, b corev1.PersistentVolumeClaim) bool {
+		return a.Namespace == b.Namespace
+	})
+}
+
+// hasActiveBackupJobs reports whether a backup Job of the policy is still running; the other Jobs of the
+// policy, e.g. long replica copies, do not hold back scheduled backups
+func (r *BackupPolicyReconciler) hasActiveBackupJobs(ctx context.Context, policy *backupv1alpha1.BackupPolicy) (bool, error) {
+	return r.hasActiveJobs(ctx, policy, isBackupJob)
+}
+
+// hasActivePolicyJobs reports whether any Job of the policy is still running, for work that needs the
+// repositories to itself
+func (r *BackupPolicyReconciler) hasActivePolicyJobs(ctx context.Context, policy *backupv1alpha1.BackupPolicy) (bool, error) {
+	return r.hasActiveJobs(ctx, policy, func(*batchv1.Job) bool { return true })
+}
+
+func (r *BackupPolicyReconciler) hasActiveJobs(ctx context.Context, policy *backupv1alpha1.BackupPolicy, match func(*batchv1.Job) bool) (bool, error) {
+	jobs, err := r.listPolicyJobs(ctx, policy)
+	if err != nil {
+		return false, err
 	}
-	for _, pvc := range pvcs {
-		namespaces[pvc.Namespace] = struct{}{}
+	for i := range jobs {
+		if match(&jobs[i]) && isJobActive(&jobs[i]) {
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	for ns := range namespaces {
-		jobList := &batchv1.JobList{}
-		if err := r.List(ctx, jobList,
-			client.InNamespace(ns),
-			client.MatchingLabels{
-				backup.LabelPolicy:          policy.Name,
-				backup.LabelPolicyNamespace: policy.Namespace,
-			}); err != nil {
-			return false, err
-		}
+// isBackupJob reports whether a Job of the policy backs up a PVC
+func isBackupJob(job *batchv1.Job) bool {
+	if isTrackedElsewhere(job) {
+		return false
+	}
+	_, isVerification := job.Labels[backup.LabelVerifiedBackup]
+	_, isRestore := job.Labels[backup.LabelRestoredBackup]
+	return !isVerification && !isRestore
+}
 
-		for _, job := range jobList.Items {
-			if job.Status.Active > 0 {
-				return true, nil
-			}
-			if job.Status.Succeeded == 0 && job.Status.Failed == 0 && job.Status.CompletionTime == nil {
-				return true, nil
-			}
+// isTrackedElsewhere reports whether a Job is a key rotation, forget or replication Job. Those are tracked
+// by their own reconcile steps and expire through their TTL.
+func isTrackedElsewhere(job *batchv1.Job) bool {
+	for _, label := range []string{backup.LabelKeyRotation, backup.LabelDeletedBackup, backup.LabelReplicatedBackup} {
+		if _, ok := job.Labels[label]; ok {
+			return true
 		}
 	}
+	return false
+}
 
-	return false, nil
+// isJobActive reports whether a Job is running or has not started yet
+func isJobActive(job *batchv1.Job) bool {
+	if job.Status.Active > 0 {
+		return true
+	}
+	return job.Status.Succeeded == 0 && job.Status.Failed == 0 && job.Status.CompletionTime == nil
 }
 
 // shouldBackupNow determines if a backup should be performed now based on the schedule
@@ -439,16 +621,21 @@ func (r *BackupPolicyReconciler) shouldBackupNow(policy *backupv1alpha1.BackupPo
 }
 
 func (r *BackupPolicyReconciler) nextRun(policy *backupv1alpha1.BackupPolicy, from time.Time) (time.Time, error) {
+	return nextScheduleTime(policy.Spec.Schedule, from)
+}
+
+// nextScheduleTime returns the next activation of a cron schedule after from, defaulting to one hour
+func nextScheduleTime(spec string, from time.Time) (time.Time, error) {
 	if from.IsZero() {
 		from = time.Now()
 	}
 
-	if policy.Spec.Schedule == "" {
+	if spec == "" {
 		return from.Add(1 * time.Hour), nil
 	}
 
 	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-	schedule, err := parser.Parse(policy.Spec.Schedule)
+	schedule, err := parser.Parse(spec)
 	if err != nil {
 		return from.Add(1 * time.Hour), err
 	}
@@ -456,110 +643,124 @@ func (r *BackupPolicyReconciler) nextRun(policy *backupv1alpha1.BackupPolicy, fr
 	return schedule.Next(from), nil
 }
 
-// updateStatus updates the BackupPolicy status
-func (r *BackupPolicyReconciler) updateStatus(ctx context.Context, policy *backupv1alpha1.BackupPolicy, phase string, message string) {
+// handleJobCompletion checks if backup Jobs and VolumeSnapshots have completed and updates the Backups and the
+// BackupPolicy status
+func (r *BackupPolicyReconciler) handleJobCompletion(ctx context.Context, policy *backupv1alpha1.BackupPolicy) error {
 	logger := log.FromContext(ctx)
 
-	policy.Status.Phase = phase
-
-	// Update conditions
-	condition := metav1.Condition{
-		Type:               "Ready",
-		Status:             metav1.ConditionTrue,
-		Reason:             phase,
-		Message:            message,
-		LastTransitionTime: metav1.Now(),
+	items, err := r.listBackups(ctx, policy)
+	if err != nil {
+		return err
 	}
 
-	if phase == "Error" {
-		condition.Status = metav1.ConditionFalse
+	jobs, err := r.listPolicyJobs(ctx, policy)
+	if err != nil {
+		return err
 	}
 
-	// Find and update or append condition
-	found := false
-	for i, c := range policy.Status.Conditions {
-		if c.Type == "Ready" {
-			policy.Status.Conditions[i] = condition
-			found = true
-			break
+	jobsByKey := make(map[string]batchv1.Job)
+	verificationJobs := make(map[string]batchv1.Job)
+	replicationJobs := make(map[string]batchv1.Job)
+	for _, job := range jobs {
+		ns := job.Namespace
+		jobsByKey[backupKey(ns, job.Name)] = job
+
+		// Keep only the most recent verification Job for each backup
+		if verified := job.Labels[backup.LabelVerifiedBackup]; verified != "" {
+			key := backupKey(ns, verified)
+			if existing, ok := verificationJobs[key]; !ok || existing.CreationTimestamp.Before(&job.CreationTimestamp) {
+				verificationJobs[key] = job
+			}
+		}
+		if replicated := job.Labels[backup.LabelReplicatedBackup]; replicated != "" {
+			replicationJobs[replicationJobKey(ns, replicated, job.Labels[backup.LabelReplica])] = job
 		}
 	}
-	if !found {
-		policy.Status.Conditions = append(policy.Status.Conditions, condition)
-	}
-
-	if err := r.Status().Update(ctx, policy); err != nil {
-		logger.Error(err, "Failed to update BackupPolicy status")
-	}
-}
-
-// updateStatusWithNextRun updates status with next run time
-func (r *BackupPolicyReconciler) updateStatusWithNextRun(ctx context.Context, policy *backupv1alpha1.BackupPolicy, phase string, nextRun time.Time) {
-	policy.Status.NextRunTime = &metav1.Time{Time: nextRun}
-	r.updateStatus(ctx, policy, phase, fmt.Sprintf("Next backup at %s", nextRun.Format(time.RFC3339)))
-}
-
-// handleJobCompletion checks if a backup Job has completed and updates BackupPolicy status
-func (r *BackupPolicyReconciler) handleJobCompletion(ctx context.Context, policy *backupv1alpha1.BackupPolicy) error {
-	logger := log.FromContext(ctx)
 
-	namespaces := map[string]struct{}{
-		policy.Namespace: {},
-	}
-	for _, ns := range policy.Spec.Namespaces {
-		namespaces[ns] = struct{}{}
-	}
-	for _, stored := range policy.Status.StoredBackups {
-		if stored.Namespace != "" {
-			namespaces[stored.Namespace] = struct{}{}
+	activeJobs := 0
+	for _, job := range jobsByKey {
+		if isJobActive(&job) {
+			activeJobs++
 		}
 	}
+	metrics.ActiveJobs.WithLabelValues(policy.Namespace, policy.Name).Set(float64(activeJobs))
 
-	jobsByKey := make(map[string]batchv1.Job)
-	for ns := range namespaces {
-		jobList := &batchv1.JobList{}
-		if err := r.List(ctx, jobList,
-			client.InNamespace(ns),
-			client.MatchingLabels{
-				backup.LabelPolicy:          policy.Name,
-				backup.LabelPolicyNamespace: policy.Namespace,
-			}); err != nil {
-			logger.Error(err, "Failed to list backup Jobs", "namespace", ns)
-			continue
+	var latestCompletion time.Time
+	for i := range items {
+		item := &items[i]
+		base := item.DeepCopy()
+		changed := false
+
+		if verificationJob, found := verificationJobs[backupKey(item.Namespace, item.Name)]; found {
+			if applyVerificationResult(item, &verificationJob) {
+				logger.Info("Backup verification finished", "job", verificationJob.Name, "backup", item.Name,
+					"verified", meta.IsStatusConditionTrue(item.Status.Conditions, backup.ConditionVerified))
+				changed = true
+			}
 		}
 
-		for _, job := range jobList.Items {
-			jobsByKey[backupKey(ns, job.Name)] = job
+		if r.applyReplicationResults(policy, item, replicationJobs) {
+			changed = true
 		}
-	}
 
-	changed := false
-	var latestCompletion time.Time
-	for i := range policy.Status.StoredBackups {
-		stored := &policy.Status.StoredBackups[i]
-		job, found := jobsByKey[backupKey(stored.Namespace, stored.Name)]
-		if !found {
-			continue
-		}
+		job, found := jobsByKey[backupKey(item.Namespace, item.Name)]
+		switch {
+		case item.Spec.Strategy == "snapshot" && item.Status.Phase == backupv1alpha1.BackupPhaseRunning:
+			// VolumeSnapshots have no Job; the snapshot controller reports their progress
+			state, err := backup.GetSnapshotState(ctx, r.Client, item)
+			if err != nil {
+				logger.Error(err, "Failed to check VolumeSnapshot", "backup", item.Name, "namespace", item.Namespace)
+				break
+			}
+			switch state.Phase {
+			case backupv1alpha1.BackupPhaseCompleted:
+				if state.Size != "" {
+					item.Status.Size = state.Size
+				}
+				completed := metav1.Now()
+				if state.CreationTime != nil {
+					completed = *state.CreationTime
+				}
+				r.completeBackup(ctx, policy, item, completed, nil)
+				if completed.After(latestCompletion) {
+					latestCompletion = completed.Time
+				}
+				changed = true
+			case backupv1alpha1.BackupPhaseFailed:
+				r.failBackup(ctx, policy, item, state.Message, metrics.ReasonSnapshotFailed)
+				changed = true
+			}
 
-		if job.Status.Succeeded > 0 && stored.Status != "Completed" {
-			stored.Status = "Completed"
+		case !found:
+		case job.Status.Succeeded > 0 && item.Status.Phase != backupv1alpha1.BackupPhaseCompleted:
+			completed := metav1.Now()
 			if job.Status.CompletionTime != nil {
-				stored.Timestamp = &metav1.Time{Time: job.Status.CompletionTime.Time}
-				if job.Status.CompletionTime.After(latestCompletion) {
-					latestCompletion = job.Status.CompletionTime.Time
-				}
+				completed = *job.Status.CompletionTime
+			}
+			// Jobs created before backups reported a result leave the PVC capacity as size
+			if summary, err := backup.ParseResticSummary(jobTerminationMessage(ctx, r.Client, &job)); err != nil {
+				logger.Info("Backup Job did not report a result", "job", job.Name, "reason", err.Error())
+			} else {
+				summary.Apply(&item.Status)
+			}
+			logger.Info("Backup Job completed successfully", "job", job.Name, "backup", item.Name, "snapshot", item.Status.SnapshotID)
+			r.completeBackup(ctx, policy, item, completed, job.Status.StartTime)
+			if completed.After(latestCompletion) {
+				latestCompletion = completed.Time
 			}
-			logger.Info("Backup Job completed successfully", "job", job.Name, "backup", stored.Name)
 			changed = true
-			continue
-		}
 
-		if job.Status.Failed > 0 && stored.Status != "Failed" {
-			stored.Status = "Failed"
-			logger.Error(nil, "Backup Job failed", "job", job.Name, "backup", stored.Name, "failed", job.Status.Failed)
+		case job.Status.Failed > 0 && item.Status.Phase != backupv1alpha1.BackupPhaseFailed:
+			logger.Error(nil, "Backup Job failed", "job", job.Name, "backup", item.Name, "failed", job.Status.Failed)
+			r.failBackup(ctx, policy, item, jobTerminationMessage(ctx, r.Client, &job), jobFailureReason(&job))
 			changed = true
 		}
+
+		if changed {
+			if err := r.Status().Patch(ctx, item, client.MergeFrom(base)); err != nil {
+				logger.Error(err, "Failed to update Backup status", "backup", item.Name, "namespace", item.Namespace)
+			}
+		}
 	}
 
 	if !latestCompletion.IsZero() {
@@ -574,49 +775,311 @@ func (r *BackupPolicyReconciler) handleJobCompletion(ctx context.Context, policy
 		policy.Status.NextRunTime = &metav1.Time{Time: nextRun}
 	}
 
-	if !changed {
+	setLastBackupCondition(policy, items)
+	items = r.pruneBackups(ctx, policy, items)
+	summarizeBackups(policy, items, nil)
+	return nil
+}
+
+// completeBackup marks a Backup Completed and reports the success through metrics, events and notifications
+func (r *BackupPolicyReconciler) completeBackup(ctx context.Context, policy *backupv1alpha1.BackupPolicy, item *backupv1alpha1.Backup, completed metav1.Time, started *metav1.Time) {
+	item.Status.Phase = backupv1alpha1.BackupPhaseCompleted
+	item.Status.CompletionTime = &completed
+	recordBackupSuccess(policy, item, started)
+	message := fmt.Sprintf("Backup %s of PVC %s/%s completed", item.Name, item.Namespace, item.Spec.PVCName)
+	backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonBackupCompleted, message,
+		policy, item, r.eventPVC(ctx, item.Namespace, item.Spec.PVCName))
+	r.notify(ctx, policy, notify.Event{
+		Type:      backupv1alpha1.NotificationBackupSucceeded,
+		Namespace: item.Namespace,
+		PVC:       item.Spec.PVCName,
+		Backup:    item.Name,
+		Message:   message,
+	})
+}
+
+// failBackup marks a Backup Failed and reports the failure through metrics, events and notifications
+func (r *BackupPolicyReconciler) failBackup(ctx context.Context, policy *backupv1alpha1.BackupPolicy, item *backupv1alpha1.Backup, reason, metricReason string) {
+	item.Status.Phase = backupv1alpha1.BackupPhaseFailed
+	item.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	item.Status.Message = reason
+	metrics.RecordBackupFailure(policy.Namespace, policy.Name, metricReason)
+	message := fmt.Sprintf("Backup %s of PVC %s/%s failed", item.Name, item.Namespace, item.Spec.PVCName)
+	if reason != "" {
+		message = fmt.Sprintf("%s: %s", message, reason)
+	}
+	backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonBackupFailed, message,
+		policy, item, r.eventPVC(ctx, item.Namespace, item.Spec.PVCName))
+	r.notify(ctx, policy, notify.Event{
+		Type:      backupv1alpha1.NotificationBackupFailed,
+		Namespace: item.Namespace,
+		PVC:       item.Spec.PVCName,
+		Backup:    item.Name,
+		Message:   message,
+	})
+}
+
+// notify sends a webhook notification in the background so slow or retrying webhooks never block reconciliation
+func (r *BackupPolicyReconciler) notify(ctx context.Context, policy *backupv1alpha1.BackupPolicy, event notify.Event) {
+	if r.Notifier == nil || policy.Spec.Notifications == nil || len(policy.Spec.Notifications.Webhooks) == 0 {
+		return
+	}
+
+	event.PolicyNamespace = policy.Namespace
+	event.Policy = policy.Name
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	policy = policy.DeepCopy()
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := r.Notifier.Notify(ctx, policy, event); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to deliver notification", "event", event.Type)
+		}
+	}()
+}
+
+// missedSchedule reports whether at least one full schedule activation passed after the due time
+func missedSchedule(spec string, due, now time.Time) bool {
+	following, err := nextScheduleTime(spec, due)
+	if err != nil {
+		return false
+	}
+	return !following.After(now)
+}
+
+// eventPVC returns the PVC to attach events to, or nil when it no longer exists
+func (r *BackupPolicyReconciler) eventPVC(ctx context.Context, namespace, name string) runtime.Object {
+	if name == "" {
 		return nil
 	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pvc); err != nil {
+		return nil
+	}
+	return pvc
+}
+
+// recordBackupSuccess exports metrics for a completed backup; the duration is unknown without a start time
+func recordBackupSuccess(policy *backupv1alpha1.BackupPolicy, item *backupv1alpha1.Backup, started *metav1.Time) {
+	completed := item.Status.CompletionTime.Time
 
-	if err := r.Status().Update(ctx, policy); err != nil {
-		return fmt.Errorf("failed to update BackupPolicy status: %w", err)
+	var duration time.Duration
+	if started != nil {
+		duration = completed.Sub(started.Time)
 	}
 
-	return nil
+	var sizeBytes int64
+	if qty, err := resource.ParseQuantity(item.Status.Size); err == nil {
+		sizeBytes = qty.Value()
+	}
+
+	metrics.RecordBackupSuccess(policy.Namespace, policy.Name, item.Spec.Strategy, item.Namespace, item.Spec.PVCName, completed, duration, sizeBytes)
 }
 
-// cleanupOldJobs removes old completed/failed Jobs to reduce API server load
-// Keeps only the 3 most recent completed Jobs and immediately deletes failed Jobs
-func (r *BackupPolicyReconciler) cleanupOldJobs(ctx context.Context, policy *backupv1alpha1.BackupPolicy) error {
+// jobFailureReason returns the reason of the Job's Failed condition (e.g., BackoffLimitExceeded, DeadlineExceeded)
+func jobFailureReason(job *batchv1.Job) string {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue && c.Reason != "" {
+			return c.Reason
+		}
+	}
+	return metrics.ReasonJobFailed
+}
+
+// jobTerminationMessage returns the most recent termination message written by a pod of the Job
+func jobTerminationMessage(ctx context.Context, c client.Reader, job *batchv1.Job) string {
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList,
+		client.InNamespace(job.Namespace),
+		client.MatchingLabels{batchv1.JobNameLabel: job.Name}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list pods of Job", "job", job.Name)
+		return ""
+	}
+
+	var message string
+	var finishedAt time.Time
+	for _, pod := range podList.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			for _, terminated := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+				if terminated == nil || terminated.Message == "" || terminated.FinishedAt.Time.Before(finishedAt) {
+					continue
+				}
+				message = strings.TrimSpace(terminated.Message)
+				finishedAt = terminated.FinishedAt.Time
+			}
+		}
+	}
+	return message
+}
+
+// runVerification starts verification Jobs for the latest completed backup of each PVC when the verification schedule is due
+func (r *BackupPolicyReconciler) runVerification(ctx context.Context, policy *backupv1alpha1.BackupPolicy, strategy backup.Strategy, pvcs []corev1.PersistentVolumeClaim) error {
 	logger := log.FromContext(ctx)
 
-	// Limit for successful Jobs to keep (like CronJob's successfulJobsHistoryLimit)
-	successfulJobsHistoryLimit := 3
-	// Limit for failed Jobs (keep 1 for debugging)
-	failedJobsHistoryLimit := 1
+	verification := policy.Spec.Verification
+	if verification == nil {
+		policy.Status.NextVerificationTime = nil
+		return nil
+	}
+
+	// A verify-now request runs outside the verification schedule, even while the policy is suspended
+	_, requested := policy.Annotations[backup.AnnotationVerifyNow]
+	if policy.Spec.Suspend && !requested {
+		return nil
+	}
+
+	verifier, ok := strategy.(backup.Verifier)
+	if !ok {
+		return fmt.Errorf("backup strategy %q does not support verification", policy.Spec.Strategy)
+	}
 
-	namespaces := map[string]struct{}{
-		policy.Namespace: {},
+	now := time.Now()
+	if policy.Status.NextVerificationTime == nil && !requested {
+		// First reconcile with verification enabled: wait for the first scheduled slot
+		next, err := nextScheduleTime(verification.Schedule, now)
+		policy.Status.NextVerificationTime = &metav1.Time{Time: next}
+		return err
 	}
-	for _, ns := range policy.Spec.Namespaces {
-		namespaces[ns] = struct{}{}
+	if !requested && now.Before(policy.Status.NextVerificationTime.Time) {
+		return nil
 	}
 
-	for ns := range namespaces {
-		jobList := &batchv1.JobList{}
-		if err := r.List(ctx, jobList,
-			client.InNamespace(ns),
-			client.MatchingLabels{
-				backup.LabelPolicy:          policy.Name,
-				backup.LabelPolicyNamespace: policy.Namespace,
-			}); err != nil {
-			logger.Error(err, "Failed to list Jobs for cleanup", "namespace", ns)
+	// restic check needs an exclusive repository lock, so wait for running Jobs to finish
+	active, err := r.hasActivePolicyJobs(ctx, policy)
+	if err != nil {
+		return err
+	}
+	if active {
+		logger.Info("Jobs of the policy are still running, postponing verification")
+		return nil
+	}
+
+	items, err := r.listBackups(ctx, policy)
+	if err != nil {
+		return err
+	}
+
+	latest := latestCompletedBackups(items)
+	for _, pvc := range pvcs {
+		idx, found := latest[backupKey(pvc.Namespace, pvc.Name)]
+		if !found {
 			continue
 		}
+		item := &items[idx]
+
+		jobName, err := verifier.Verify(ctx, item, &pvc, policy)
+		if err != nil {
+			logger.Error(err, "Failed to start verification", "backup", item.Name, "pvc", pvc.Name)
+			meta.SetStatusCondition(&item.Status.Conditions, metav1.Condition{
+				Type:    backup.ConditionVerified,
+				Status:  metav1.ConditionFalse,
+				Reason:  "VerificationNotStarted",
+				Message: err.Error(),
+			})
+		} else {
+			meta.SetStatusCondition(&item.Status.Conditions, metav1.Condition{
+				Type:    backup.ConditionVerified,
+				Status:  metav1.ConditionUnknown,
+				Reason:  "Verifying",
+				Message: fmt.Sprintf("Verification Job %s is running", jobName),
+			})
+		}
+
+		if err := r.Status().Update(ctx, item); err != nil {
+			logger.Error(err, "Failed to update Backup status", "backup", item.Name, "namespace", item.Namespace)
+		}
+	}
+
+	if requested {
+		if err := r.clearAnnotation(ctx, policy, backup.AnnotationVerifyNow); err != nil {
+			logger.Error(err, "Failed to clear verification request")
+		}
+	}
+
+	policy.Status.LastVerificationTime = &metav1.Time{Time: now}
+	next, err := nextScheduleTime(verification.Schedule, now)
+	policy.Status.NextVerificationTime = &metav1.Time{Time: next}
+	return err
+}
+
+// latestCompletedBackups returns the index of the newest completed backup per PVC, keyed by namespace/pvc
+func latestCompletedBackups(items []backupv1alpha1.Backup) map[string]int {
+	latest := make(map[string]int)
+	for i, item := range items {
+		if item.Status.Phase != backupv1alpha1.BackupPhaseCompleted {
+			continue
+		}
+		key := backupKey(item.Namespace, item.Spec.PVCName)
+		if idx, found := latest[key]; found && !backupTime(&item).After(backupTime(&items[idx])) {
+			continue
+		}
+		latest[key] = i
+	}
+	return latest
+}
+
+// applyVerificationResult records the outcome of a finished verification Job on the backup it verified
+func applyVerificationResult(item *backupv1alpha1.Backup, job *batchv1.Job) bool {
+	condition := meta.FindStatusCondition(item.Status.Conditions, backup.ConditionVerified)
+	if condition == nil || condition.Status != metav1.ConditionUnknown {
+		return false
+	}
+
+	switch {
+	case job.Status.Succeeded > 0:
+		verifiedAt := metav1.Now()
+		if job.Status.CompletionTime != nil {
+			verifiedAt = *job.Status.CompletionTime
+		}
+		item.Status.LastVerifiedTime = &verifiedAt
+		meta.SetStatusCondition(&item.Status.Conditions, metav1.Condition{
+			Type:    backup.ConditionVerified,
+			Status:  metav1.ConditionTrue,
+			Reason:  "VerificationSucceeded",
+			Message: fmt.Sprintf("Verification Job %s succeeded", job.Name),
+		})
+	case job.Status.Failed > 0:
+		meta.SetStatusCondition(&item.Status.Conditions, metav1.Condition{
+			Type:    backup.ConditionVerified,
+			Status:  metav1.ConditionFalse,
+			Reason:  "VerificationFailed",
+			Message: fmt.Sprintf("Verification Job %s failed, see its logs for details", job.Name),
+		})
+	default:
+		return false
+	}
 
+	return true
+}
+
+// cleanupOldJobs removes old completed/failed Jobs to reduce API server load.
+// The policy's history limits decide how many finished Jobs are kept per namespace, and running
+// Jobs well past their deadline are deleted as stuck.
+func (r *BackupPolicyReconciler) cleanupOldJobs(ctx context.Context, policy *backupv1alpha1.BackupPolicy) error {
+	logger := log.FromContext(ctx)
+
+	successfulJobsHistoryLimit := historyLimit(policy.Spec.SuccessfulJobsHistoryLimit, defaultSuccessfulJobsHistoryLimit)
+	failedJobsHistoryLimit := historyLimit(policy.Spec.FailedJobsHistoryLimit, defaultFailedJobsHistoryLimit)
+
+	jobs, err := r.listPolicyJobs(ctx, policy)
+	if err != nil {
+		return err
+	}
+
+	jobsByNamespace := make(map[string][]batchv1.Job)
+	for _, job := range jobs {
+		jobsByNamespace[job.Namespace] = append(jobsByNamespace[job.Namespace], job)
+	}
+
+	for _, namespaceJobs := range jobsByNamespace {
 		// Separate Jobs by status
 		var completedJobs, failedJobs, runningJobs []batchv1.Job
-		for _, job := range jobList.Items {
+		for _, job := range namespaceJobs {
+			if isTrackedElsewhere(&job) {
+				continue
+			}
 			if job.Status.Succeeded > 0 {
 				completedJobs = append(completedJobs, job)
 			} else if job.Status.Failed > 0 {
@@ -659,7 +1122,7 @@ func (r *BackupPolicyReconciler) cleanupOldJobs(ctx context.Context, policy *bac
 			return failedJobs[i].Status.StartTime.After(failedJobs[j].Status.StartTime.Time)
 		})
 
-		// Delete old failed Jobs (keep only 1 for debugging)
+		// Delete old failed Jobs (keep the most recent N for debugging)
 		if len(failedJobs) > failedJobsHistoryLimit {
 			for i := failedJobsHistoryLimit; i < len(failedJobs); i++ {
 				job := &failedJobs[i]
@@ -670,16 +1133,31 @@ func (r *BackupPolicyReconciler) cleanupOldJobs(ctx context.Context, policy *bac
 			}
 		}
 
-		// Check for stuck running Jobs (running for more than 10 minutes)
+		// Kubernetes fails Jobs at their deadline; one still running well past it is stuck (e.g., a pod that never terminates)
 		for _, job := range runningJobs {
-			if job.Status.StartTime != nil {
-				runningDuration := time.Since(job.Status.StartTime.Time)
-				if runningDuration > 10*time.Minute {
-					logger.Info("Deleting stuck running Job", "job", job.Name, "namespace", job.Namespace, "duration", runningDuration)
-					if err := r.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
-						logger.Error(err, "Failed to delete stuck Job", "job", job.Name)
-					}
-				}
+			if job.Status.StartTime == nil {
+				continue
+			}
+			deadline := time.Duration(backup.ActiveDeadlineSeconds(policy)) * time.Second
+			if job.Spec.ActiveDeadlineSeconds != nil {
+				deadline = time.Duration(*job.Spec.ActiveDeadlineSeconds) * time.Second
+			}
+			runningDuration := time.Since(job.Status.StartTime.Time)
+			if runningDuration <= deadline+stuckJobGracePeriod {
+				continue
+			}
+
+			logger.Info("Deleting stuck running Job", "job", job.Name, "namespace", job.Namespace, "duration", runningDuration, "deadline", deadline)
+			if err := r.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+				logger.Error(err, "Failed to delete stuck Job", "job", job.Name)
+				continue
+			}
+			message := fmt.Sprintf("Job %s/%s was deleted after running for %s, past its deadline of %s",
+				job.Namespace, job.Name, runningDuration.Round(time.Second), deadline)
+			backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonStuckJobKilled, message,
+				policy, r.eventPVC(ctx, job.Namespace, job.Labels[backup.LabelPVC]))
+			if err := r.recordKilledJob(ctx, policy, &job, message); err != nil {
+				logger.Error(err, "Failed to record stuck Job in Backup status", "job", job.Name)
 			}
 		}
 	}
@@ -687,11 +1165,66 @@ func (r *BackupPolicyReconciler) cleanupOldJobs(ctx context.Context, policy *bac
 	return nil
 }
 
+// recordKilledJob explains in the Backup status that the Job backing up or verifying it was deleted as stuck
+func (r *BackupPolicyReconciler) recordKilledJob(ctx context.Context, policy *backupv1alpha1.BackupPolicy, job *batchv1.Job, message string) error {
+	name := job.Name
+	verified := job.Labels[backup.LabelVerifiedBackup]
+	if verified != "" {
+		name = verified
+	}
+
+	item := &backupv1alpha1.Backup{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: job.Namespace, Name: name}, item); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if verified != "" {
+		condition := meta.FindStatusCondition(item.Status.Conditions, backup.ConditionVerified)
+		if condition == nil || condition.Status != metav1.ConditionUnknown {
+			return nil
+		}
+		meta.SetStatusCondition(&item.Status.Conditions, metav1.Condition{
+			Type:    backup.ConditionVerified,
+			Status:  metav1.ConditionFalse,
+			Reason:  "VerificationKilled",
+			Message: message,
+		})
+	} else {
+		if item.Status.Phase != backupv1alpha1.BackupPhaseRunning {
+			return nil
+		}
+		now := metav1.Now()
+		item.Status.Phase = backupv1alpha1.BackupPhaseFailed
+		item.Status.CompletionTime = &now
+		item.Status.Message = message
+		metrics.RecordBackupFailure(policy.Namespace, policy.Name, metrics.ReasonStuckJobKilled)
+	}
+	return r.Status().Update(ctx, item)
+}
+
+// historyLimit returns the configured Job history limit or its default
+func historyLimit(limit *int32, defaultLimit int) int {
+	if limit == nil {
+		return defaultLimit
+	}
+	return int(*limit)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *BackupPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := setupIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&backupv1alpha1.BackupPolicy{}).
-		Owns(&batchv1.Job{}). // Watch Jobs created by this controller
+		// Jobs in other namespaces carry no owner reference, so map them to their policy by label
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(policyForJob)).
+		// Pick up new or relabelled PVCs without waiting for the next scheduled run
+		Watches(&corev1.PersistentVolumeClaim{}, handler.EnqueueRequestsFromMapFunc(r.policiesForPVC),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		// Watch credential Secrets so their copies follow rotations; only their metadata is cached
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.policiesForSecret),
+			builder.OnlyMetadata, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
 		Named("backuppolicy").
 		Complete(r)
 }
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func TestHandleJobCompletionEmitsFailureEvents(t *testing.T) {
+	scheme := newTestScheme(t)
+
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "control"},
+	}
+	item := &backupv1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "policy-data-1",
+			Namespace: "control",
+			Labels: map[string]string{
+				backup.LabelPolicy:          "policy",
+				backup.LabelPolicyNamespace: "control",
+			},
+		},
+		Spec:   backupv1alpha1.BackupSpec{PVCName: "data", Strategy: "external"},
+		Status: backupv1alpha1.BackupStatus{Phase: backupv1alpha1.BackupPhaseRunning},
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "control"}}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "policy-data-1",
+			Namespace: "control",
+			Labels: map[string]string{
+				backup.LabelPolicy:          "policy",
+				backup.LabelPolicyNamespace: "control",
+			},
+		},
+		Status: batchv1.JobStatus{Failed: 1},
+	}
+
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(policy, item, pvc, job).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}, &backupv1alpha1.Backup{}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme, Recorder: recorder}
+
+	if err := r.handleJobCompletion(context.Background(), policy); err != nil {
+		t.Fatalf("handleJobCompletion returned error: %v", err)
+	}
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(item), item); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if item.Status.Phase != backupv1alpha1.BackupPhaseFailed {
+		t.Fatalf("expected backup to be marked failed, got %s", item.Status.Phase)
+	}
+	if policy.Status.FailedBackups != 1 {
+		t.Fatalf("expected one failed backup in the policy summary, got %d", policy.Status.FailedBackups)
+	}
+
+	// One event each on the policy, the Backup and the PVC
+	for i := 0; i < 3; i++ {
+		select {
+		case event := <-recorder.Events:
+			if !strings.HasPrefix(event, corev1.EventTypeWarning+" "+backup.EventReasonBackupFailed) {
+				t.Fatalf("unexpected event %q", event)
+			}
+		default:
+			t.Fatalf("expected 3 BackupFailed events, got %d", i)
+		}
+	}
+}
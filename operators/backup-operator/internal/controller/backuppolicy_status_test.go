@@ -1,55 +1,438 @@
 package controller
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+	"github.com/example/backup-operator/internal/metrics"
 )
 
-func TestNormalizeStoredBackupsSortsByTimestamp(t *testing.T) {
-	older := time.Now().Add(-1 * time.Hour)
-	newer := time.Now()
-
-	backups := map[string]backupv1alpha1.StoredBackup{
-		"ns/older": {
-			Name:      "older",
+func newBackupRecord(name, pvc, phase string, started time.Time) *backupv1alpha1.Backup {
+	return &backupv1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
 			Namespace: "ns",
-			Timestamp: &metav1.Time{Time: older},
+			Labels: map[string]string{
+				backup.LabelPolicy:          "policy",
+				backup.LabelPolicyNamespace: "ns",
+			},
 		},
-		"ns/newer": {
-			Name:      "newer",
-			Namespace: "ns",
-			Timestamp: &metav1.Time{Time: newer},
+		Spec: backupv1alpha1.BackupSpec{
+			PolicyRef: backupv1alpha1.PolicyReference{Name: "policy", Namespace: "ns"},
+			PVCName:   pvc,
+			Strategy:  "external",
 		},
+		Status: backupv1alpha1.BackupStatus{Phase: phase, StartTime: &metav1.Time{Time: started}},
 	}
+}
 
-	normalized := normalizeStoredBackups(backups)
-	if len(normalized) != 2 {
-		t.Fatalf("expected 2 backups, got %d", len(normalized))
+func newBackupRecordReconciler(t *testing.T, objects ...client.Object) *BackupPolicyReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := backupv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
 	}
+	fakeClient := newFakeClientBuilder(scheme).WithObjects(objects...).Build()
+	return &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+}
+
+func TestListBackupsSortsNewestFirst(t *testing.T) {
+	now := time.Now()
+	r := newBackupRecordReconciler(t,
+		newBackupRecord("older", "data", backupv1alpha1.BackupPhaseCompleted, now.Add(-time.Hour)),
+		newBackupRecord("newer", "data", backupv1alpha1.BackupPhaseCompleted, now),
+	)
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"}}
 
-	if normalized[0].Name != "newer" {
-		t.Fatalf("expected newer backup first, got %s", normalized[0].Name)
+	records, err := r.listBackups(context.Background(), policy)
+	if err != nil {
+		t.Fatalf("listBackups returned error: %v", err)
+	}
+	if len(records) != 2 || records[0].Name != "newer" {
+		t.Fatalf("expected newer backup first, got %v", records)
 	}
 }
 
-func TestNormalizeStoredBackupsCapsHistory(t *testing.T) {
-	backups := make(map[string]backupv1alpha1.StoredBackup)
-	for i := 0; i < maxStatusHistory+5; i++ {
-		name := fmt.Sprintf("backup-%d", i)
-		backups[backupKey("ns", name)] = backupv1alpha1.StoredBackup{
-			Name:      name,
-			Namespace: "ns",
-			Timestamp: &metav1.Time{Time: time.Now().Add(time.Duration(-i) * time.Minute)},
+func TestPruneBackupsAppliesRetentionPerPhase(t *testing.T) {
+	now := time.Now()
+	var objects []client.Object
+	for i := 0; i < 5; i++ {
+		objects = append(objects, newBackupRecord(fmt.Sprintf("completed-%d", i), "data", backupv1alpha1.BackupPhaseCompleted, now.Add(time.Duration(-i)*time.Hour)))
+	}
+	objects = append(objects,
+		newBackupRecord("failed", "data", backupv1alpha1.BackupPhaseFailed, now),
+		newBackupRecord("running", "data", backupv1alpha1.BackupPhaseRunning, now.Add(-48*time.Hour)),
+	)
+	r := newBackupRecordReconciler(t, objects...)
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec:       backupv1alpha1.BackupPolicySpec{Retention: backupv1alpha1.Retention{MaxBackups: 3}},
+	}
+
+	records, err := r.listBackups(context.Background(), policy)
+	if err != nil {
+		t.Fatalf("listBackups returned error: %v", err)
+	}
+	deletions := metrics.RetentionDeletions.WithLabelValues("ns", "policy", "external")
+	before := testutil.ToFloat64(deletions)
+	remaining := r.pruneBackups(context.Background(), policy, records)
+	if got := testutil.ToFloat64(deletions) - before; got != 2 {
+		t.Fatalf("expected 2 retention deletions, got %v", got)
+	}
+
+	completed := now.Add(-time.Minute).Truncate(time.Second)
+	for i := range remaining {
+		if remaining[i].Status.Phase == backupv1alpha1.BackupPhaseCompleted {
+			remaining[i].Status.CompletionTime = &metav1.Time{Time: completed}
+			break
 		}
 	}
+	summarizeBackups(policy, remaining, nil)
+	if policy.Status.CompletedBackups != 3 || policy.Status.FailedBackups != 1 || policy.Status.BackupCount != 5 {
+		t.Fatalf("unexpected summary after pruning: %+v", policy.Status)
+	}
+	// The last successful backup is known from the records, e.g. after a restart
+	if got := testutil.ToFloat64(metrics.LastSuccessfulBackup.WithLabelValues("ns", "policy", "ns", "data")); got != float64(completed.Unix()) {
+		t.Fatalf("expected last successful backup %d, got %v", completed.Unix(), got)
+	}
+	// A PVC the policy no longer targets stops reporting a last successful backup
+	summarizeBackups(policy, remaining, targetSet(nil))
+	if metrics.LastSuccessfulBackup.DeleteLabelValues("ns", "policy", "ns", "data") {
+		t.Fatalf("expected the series of the untargeted PVC to be deleted")
+	}
+
+	left, _ := r.listBackups(context.Background(), policy)
+	if len(left) != 5 {
+		t.Fatalf("expected 5 Backups left, got %d", len(left))
+	}
+}
 
-	normalized := normalizeStoredBackups(backups)
-	if len(normalized) != maxStatusHistory {
-		t.Fatalf("expected history to be capped at %d, got %d", maxStatusHistory, len(normalized))
+func TestPruneBackupsAppliesMaxAgeInDays(t *testing.T) {
+	now := time.Now()
+	r := newBackupRecordReconciler(t,
+		newBackupRecord("recent", "data", backupv1alpha1.BackupPhaseCompleted, now.Add(-72*time.Hour)),
+		newBackupRecord("expired", "data", backupv1alpha1.BackupPhaseCompleted, now.Add(-240*time.Hour)),
+	)
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec:       backupv1alpha1.BackupPolicySpec{Retention: backupv1alpha1.Retention{MaxAge: "7d"}},
+	}
+	recorder := record.NewFakeRecorder(10)
+	r.Recorder = recorder
+
+	records, err := r.listBackups(context.Background(), policy)
+	if err != nil {
+		t.Fatalf("listBackups returned error: %v", err)
+	}
+	remaining := r.pruneBackups(context.Background(), policy, records)
+	if len(remaining) != 1 || remaining[0].Name != "recent" {
+		t.Fatalf("expected only the backup younger than 7 days to remain, got %v", remaining)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, corev1.EventTypeNormal+" "+backup.EventReasonRetentionDeleted) {
+			t.Fatalf("unexpected event %q", event)
+		}
+	default:
+		t.Fatal("expected a RetentionDeleted event")
+	}
+}
+
+func TestFindTargetPVCsDumpsOncePerNamespace(t *testing.T) {
+	pvc := func(namespace, name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: namespace, Labels: map[string]string{"app": "db"},
+		}}
+	}
+	r := newCredentialsReconciler(t, pvc("apps", "data-db-1"), pvc("apps", "data-db-0"), pvc("shop", "data-db-0"))
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ops"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Namespaces: []string{"apps", "shop"},
+			Selector:   metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Dump:       &backupv1alpha1.Dump{Engine: "postgres", Service: "db", CredentialsSecret: "db"},
+		},
+	}
+
+	pvcs, err := r.findTargetPVCs(context.Background(), policy)
+	if err != nil {
+		t.Fatalf("findTargetPVCs returned error: %v", err)
+	}
+	var names []string
+	for _, p := range pvcs {
+		names = append(names, p.Namespace+"/"+p.Name)
+	}
+	if !slices.Equal(names, []string{"apps/data-db-0", "shop/data-db-0"}) {
+		t.Fatalf("expected one dump target per namespace, got %v", names)
+	}
+}
+
+func TestMissedSchedule(t *testing.T) {
+	due := time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC)
+
+	if missedSchedule("0 2 * * *", due, due.Add(time.Hour)) {
+		t.Fatalf("backup started within its schedule window should not be reported as missed")
+	}
+	if !missedSchedule("0 2 * * *", due, due.Add(25*time.Hour)) {
+		t.Fatalf("expected a missed schedule after a full day without backups")
+	}
+}
+
+func TestHandleJobCompletionRecordsBackupResult(t *testing.T) {
+	scheme := newTestScheme(t)
+
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"}}
+	item := newBackupRecord("policy-data-1", "data", backupv1alpha1.BackupPhaseRunning, time.Now())
+	item.Status.Size = "10Gi"
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: item.Name, Namespace: "ns", Labels: item.Labels},
+		Status:     batchv1.JobStatus{Succeeded: 1},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: item.Name + "-abcde", Namespace: "ns", Labels: map[string]string{batchv1.JobNameLabel: job.Name}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name: "backup",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Message: `{"message_type":"summary","files_new":3,"files_changed":1,"files_unmodified":96,` +
+					`"data_added":1048576,"data_added_packed":524288,"total_files_processed":100,` +
+					`"total_bytes_processed":2147483648,"total_duration":91.6,"snapshot_id":"4c5d1a2b"}` + "\n",
+			}},
+		}}},
+	}
+
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(policy, item, job, pod).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}, &backupv1alpha1.Backup{}).
+		Build()
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+
+	if err := r.handleJobCompletion(context.Background(), policy); err != nil {
+		t.Fatalf("handleJobCompletion returned error: %v", err)
+	}
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(item), item); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if item.Status.SnapshotID != "4c5d1a2b" {
+		t.Fatalf("expected the snapshot ID from the Job result, got %q", item.Status.SnapshotID)
+	}
+	if item.Status.Size != "2Gi" {
+		t.Fatalf("expected the size read from the PVC instead of its capacity, got %q", item.Status.Size)
+	}
+	stats := item.Status.Stats
+	if stats == nil || stats.BytesAdded != 1048576 || stats.BytesStored != 524288 || stats.FilesNew != 3 || stats.Duration != "1m32s" {
+		t.Fatalf("unexpected backup stats %+v", stats)
+	}
+}
+
+func TestHandleJobCompletionSyncsSnapshotBackups(t *testing.T) {
+	scheme := newTestScheme(t)
+
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"}}
+	var objects []client.Object
+	snapshot := func(name string, status map[string]any) {
+		item := newBackupRecord(name, "data", backupv1alpha1.BackupPhaseRunning, time.Now())
+		item.Spec.Strategy = "snapshot"
+		item.Status.Location = "ns/" + name
+		objects = append(objects, item)
+		if status == nil {
+			return
+		}
+		volumeSnapshot := &unstructured.Unstructured{Object: map[string]any{"status": status}}
+		volumeSnapshot.SetAPIVersion("snapshot.storage.k8s.io/v1")
+		volumeSnapshot.SetKind("VolumeSnapshot")
+		volumeSnapshot.SetNamespace("ns")
+		volumeSnapshot.SetName(name)
+		objects = append(objects, volumeSnapshot)
+	}
+	snapshot("ready", map[string]any{"readyToUse": true, "restoreSize": "5Gi"})
+	snapshot("pending", map[string]any{"readyToUse": false})
+	snapshot("broken", map[string]any{"readyToUse": false, "error": map[string]any{"message": "driver failed"}})
+	snapshot("gone", nil)
+
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(append(objects, policy)...).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}, &backupv1alpha1.Backup{}).
+		Build()
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+
+	if err := r.handleJobCompletion(ctx, policy); err != nil {
+		t.Fatalf("handleJobCompletion returned error: %v", err)
+	}
+	expected := map[string]string{
+		"ready":   backupv1alpha1.BackupPhaseCompleted,
+		"pending": backupv1alpha1.BackupPhaseRunning,
+		"broken":  backupv1alpha1.BackupPhaseFailed,
+		"gone":    backupv1alpha1.BackupPhaseFailed,
+	}
+	for name, phase := range expected {
+		item := &backupv1alpha1.Backup{}
+		if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "ns", Name: name}, item); err != nil {
+			t.Fatalf("failed to get Backup %s: %v", name, err)
+		}
+		if item.Status.Phase != phase {
+			t.Fatalf("expected Backup %s to be %s, got %s", name, phase, item.Status.Phase)
+		}
+	}
+	if policy.Status.LastBackupTime == nil || policy.Status.CompletedBackups != 1 || policy.Status.FailedBackups != 2 {
+		t.Fatalf("expected the ready snapshot to count as the last backup, got %+v", policy.Status)
+	}
+	if result := r.requeueForBackups(ctx, policy, ctrl.Result{RequeueAfter: time.Hour}); result.RequeueAfter != requeueWhileSnapshotPending {
+		t.Fatalf("expected a short requeue while a snapshot is pending, got %s", result.RequeueAfter)
+	}
+}
+
+func TestMigrateStoredBackups(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := backupv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	taken := metav1.NewTime(time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC))
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns", UID: "policy-uid"},
+		Status: backupv1alpha1.BackupPolicyStatus{StoredBackups: []backupv1alpha1.StoredBackup{
+			{Name: "policy-data-1", Namespace: "ns", PVCName: "data", Strategy: "external", Status: "Completed", Timestamp: &taken, Location: "s3:bucket/policy/ns/data"},
+			{Name: "policy-data-2", Namespace: "other", PVCName: "data", Strategy: "snapshot", Status: "InProgress", Timestamp: &taken},
+		}},
+	}
+	existing := newBackupRecord("policy-data-1", "data", backupv1alpha1.BackupPhaseCompleted, taken.Time)
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(policy, existing).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}, &backupv1alpha1.Backup{}).
+		Build()
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+
+	r.migrateStoredBackups(ctx, policy)
+	if len(policy.Status.StoredBackups) != 0 {
+		t.Fatalf("expected the status list to be cleared, got %v", policy.Status.StoredBackups)
+	}
+	item := &backupv1alpha1.Backup{}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "other", Name: "policy-data-2"}, item); err != nil {
+		t.Fatalf("expected the stored backup to be migrated: %v", err)
+	}
+	if item.Status.Phase != backupv1alpha1.BackupPhaseRunning || item.Spec.Strategy != "snapshot" ||
+		item.Labels[backup.LabelPolicy] != "policy" || len(item.OwnerReferences) != 0 {
+		t.Fatalf("unexpected migrated Backup %+v", item)
+	}
+}
+
+func TestOnlyBackupJobsHoldBackBackups(t *testing.T) {
+	labels := func(extra ...string) map[string]string {
+		l := map[string]string{backup.LabelPolicy: "policy", backup.LabelPolicyNamespace: "ns"}
+		for i := 0; i+1 < len(extra); i += 2 {
+			l[extra[i]] = extra[i+1]
+		}
+		return l
+	}
+	replication := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy-data-1-replica-offsite", Namespace: "ns",
+			Labels: labels(backup.LabelReplicatedBackup, "policy-data-1")},
+		Status: batchv1.JobStatus{Active: 1},
+	}
+	forget := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy-data-0-forget", Namespace: "ns",
+			Labels: labels(backup.LabelDeletedBackup, "policy-data-0")},
+		Status: batchv1.JobStatus{Active: 1},
+	}
+	r := newCredentialsReconciler(t, replication, forget)
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"}}
+	ctx := context.Background()
+
+	if active, err := r.hasActiveBackupJobs(ctx, policy); err != nil || active {
+		t.Fatalf("expected replication and forget Jobs not to hold back backups, got %v (err %v)", active, err)
+	}
+	if active, err := r.hasActivePolicyJobs(ctx, policy); err != nil || !active {
+		t.Fatalf("expected the running Jobs to be reported for exclusive work, got %v (err %v)", active, err)
+	}
+
+	running := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy-data-2", Namespace: "ns", Labels: labels()},
+		Status:     batchv1.JobStatus{Active: 1},
+	}
+	if err := r.Create(ctx, running); err != nil {
+		t.Fatalf("failed to create backup Job: %v", err)
+	}
+	if active, err := r.hasActiveBackupJobs(ctx, policy); err != nil || !active {
+		t.Fatalf("expected the running backup Job to hold back backups, got %v (err %v)", active, err)
+	}
+}
+
+func TestCleanupOldJobsAppliesHistoryLimitsAndKillsStuckJobs(t *testing.T) {
+	scheme := newTestScheme(t)
+
+	now := time.Now()
+	successfulJobsHistoryLimit := int32(1)
+	failedJobsHistoryLimit := int32(0)
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			SuccessfulJobsHistoryLimit: &successfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     &failedJobsHistoryLimit,
+		},
+	}
+	stuck := newBackupRecord("policy-data-stuck", "data", backupv1alpha1.BackupPhaseRunning, now.Add(-time.Hour))
+	newJob := func(name string, started time.Duration, status batchv1.JobStatus) *batchv1.Job {
+		deadline := int64(600)
+		status.StartTime = &metav1.Time{Time: now.Add(-started)}
+		if status.Succeeded > 0 {
+			status.CompletionTime = status.StartTime
+		}
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: stuck.Labels},
+			Spec:       batchv1.JobSpec{ActiveDeadlineSeconds: &deadline},
+			Status:     status,
+		}
+	}
+
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(policy, stuck,
+			newJob("completed-new", time.Minute, batchv1.JobStatus{Succeeded: 1}),
+			newJob("completed-old", time.Hour, batchv1.JobStatus{Succeeded: 1}),
+			newJob("failed", time.Minute, batchv1.JobStatus{Failed: 1}),
+			newJob("running", 5*time.Minute, batchv1.JobStatus{}),
+			newJob(stuck.Name, time.Hour, batchv1.JobStatus{}),
+		).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}, &backupv1alpha1.Backup{}).
+		Build()
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+
+	if err := r.cleanupOldJobs(context.Background(), policy); err != nil {
+		t.Fatalf("cleanupOldJobs returned error: %v", err)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := fakeClient.List(context.Background(), jobs); err != nil {
+		t.Fatalf("failed to list Jobs: %v", err)
+	}
+	var remaining []string
+	for _, job := range jobs.Items {
+		remaining = append(remaining, job.Name)
+	}
+	if len(remaining) != 2 || remaining[0] != "completed-new" || remaining[1] != "running" {
+		t.Fatalf("expected only the newest completed Job and the running Job to remain, got %v", remaining)
+	}
+
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(stuck), stuck); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if stuck.Status.Phase != backupv1alpha1.BackupPhaseFailed || stuck.Status.CompletionTime == nil || stuck.Status.Message == "" {
+		t.Fatalf("expected the Backup of the stuck Job to be marked failed with a reason, got %+v", stuck.Status)
 	}
 }
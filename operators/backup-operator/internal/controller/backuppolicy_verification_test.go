@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func TestLatestCompletedBackupsPicksNewestPerPVC(t *testing.T) {
+	now := time.Now()
+	backups := []backupv1alpha1.Backup{
+		*newBackupRecord("old", "data", backupv1alpha1.BackupPhaseCompleted, now.Add(-2*time.Hour)),
+		*newBackupRecord("new", "data", backupv1alpha1.BackupPhaseCompleted, now.Add(-1*time.Hour)),
+		*newBackupRecord("running", "data", backupv1alpha1.BackupPhaseRunning, now),
+		*newBackupRecord("other", "logs", backupv1alpha1.BackupPhaseFailed, now),
+	}
+
+	latest := latestCompletedBackups(backups)
+	if len(latest) != 1 {
+		t.Fatalf("expected one PVC with completed backups, got %d", len(latest))
+	}
+	if idx := latest[backupKey("ns", "data")]; backups[idx].Name != "new" {
+		t.Fatalf("expected newest completed backup, got %s", backups[idx].Name)
+	}
+}
+
+func TestApplyVerificationResult(t *testing.T) {
+	item := &backupv1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "backup"}}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "verify"}}
+	job.Status.Succeeded = 1
+
+	if applyVerificationResult(item, job) {
+		t.Fatalf("expected no change for a backup without a running verification")
+	}
+
+	meta.SetStatusCondition(&item.Status.Conditions, metav1.Condition{
+		Type:   backup.ConditionVerified,
+		Status: metav1.ConditionUnknown,
+		Reason: "Verifying",
+	})
+	if !applyVerificationResult(item, job) {
+		t.Fatalf("expected verification result to be applied")
+	}
+	if !meta.IsStatusConditionTrue(item.Status.Conditions, backup.ConditionVerified) {
+		t.Fatalf("expected Verified condition to be true, got %v", item.Status.Conditions)
+	}
+	if item.Status.LastVerifiedTime == nil {
+		t.Fatalf("expected last verified time to be set")
+	}
+
+	// A finished verification is not applied twice
+	if applyVerificationResult(item, job) {
+		t.Fatalf("expected finished verification to be ignored")
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// BackupPolicyTemplateReconciler generates a BackupPolicy per namespace for PVCs annotated with a template
+type BackupPolicyTemplateReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backuppolicytemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backuppolicytemplates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backuppolicytemplates/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;patch
+
+// Reconcile labels the PVCs annotated with the template so a policy selector can match them, creates or
+// updates one policy per namespace with such PVCs and deletes the policies of namespaces without any
+func (r *BackupPolicyTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	template := &backupv1alpha1.BackupPolicyTemplate{}
+	if err := r.Get(ctx, req.NamespacedName, template); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !template.DeletionTimestamp.IsZero() {
+		// Generated policies are garbage collected through their owner reference
+		return ctrl.Result{}, nil
+	}
+
+	namespaces, pvcCount, err := r.syncPVCLabels(ctx, template.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	wanted := make(map[client.ObjectKey]bool, len(namespaces))
+	var policies []string
+	for _, namespace := range namespaces {
+		key := generatedPolicyKey(template, namespace)
+		wanted[key] = true
+		managed, err := r.ensurePolicy(ctx, template, key, namespace)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if managed {
+			policies = append(policies, backupKey(key.Namespace, key.Name))
+		}
+	}
+	if err := r.removeStalePolicies(ctx, template, wanted); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	patch := client.MergeFrom(template.DeepCopy())
+	template.Status.ObservedGeneration = template.Generation
+	template.Status.Policies = policies
+	template.Status.PVCCount = pvcCount
+	return ctrl.Result{}, client.IgnoreNotFound(r.Status().Patch(ctx, template, patch))
+}
+
+// syncPVCLabels mirrors the template annotation of PVCs into a label and removes the label from PVCs that
+// opted out. It returns the sorted namespaces of the annotated PVCs and their number.
+func (r *BackupPolicyTemplateReconciler) syncPVCLabels(ctx context.Context, name string) ([]string, int, error) {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, pvcs, client.MatchingFields{pvcTemplateIndex: name}); err != nil {
+		return nil, 0, fmt.Errorf("failed to list PVCs: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var namespaces []string
+	count := 0
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		annotated := pvc.Annotations[backup.AnnotationPolicyTemplate] == name
+		labelled := pvc.Labels[backup.LabelPolicyTemplate] == name
+		if annotated != labelled {
+			patch := client.MergeFrom(pvc.DeepCopy())
+			if annotated {
+				metav1.SetMetaDataLabel(&pvc.ObjectMeta, backup.LabelPolicyTemplate, name)
+			} else {
+				delete(pvc.Labels, backup.LabelPolicyTemplate)
+			}
+			if err := r.Patch(ctx, pvc, patch); client.IgnoreNotFound(err) != nil {
+				return nil, 0, fmt.Errorf("failed to label PVC %s/%s: %w", pvc.Namespace, pvc.Name, err)
+			}
+		}
+		if !annotated {
+			continue
+		}
+		count++
+		if !seen[pvc.Namespace] {
+			seen[pvc.Namespace] = true
+			namespaces = append(namespaces, pvc.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, count, nil
+}
+
+// ensurePolicy creates or updates the policy generated for a namespace. It reports whether the
+// policy is managed by the template; policies created by someone else are left alone.
+func (r *BackupPolicyTemplateReconciler) ensurePolicy(ctx context.Context, template *backupv1alpha1.BackupPolicyTemplate, key client.ObjectKey, namespace string) (bool, error) {
+	logger := log.FromContext(ctx)
+	spec := generatedPolicySpec(template, namespace)
+
+	policy := &backupv1alpha1.BackupPolicy{}
+	err := r.Get(ctx, key, policy)
+	switch {
+	case apierrors.IsNotFound(err):
+		policy = &backupv1alpha1.BackupPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels:    map[string]string{backup.LabelPolicyTemplate: template.Name},
+			},
+			Spec: spec,
+		}
+		if err := controllerutil.SetControllerReference(template, policy, r.Scheme); err != nil {
+			return false, err
+		}
+		if err := r.Create(ctx, policy); err != nil {
+			return false, fmt.Errorf("failed to create BackupPolicy %s: %w", key, err)
+		}
+		logger.Info("Generated BackupPolicy", "template", template.Name, "policy", key)
+		backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonPolicyGenerated,
+			fmt.Sprintf("Created BackupPolicy %s for annotated PVCs in namespace %s", key, namespace), template)
+		return true, nil
+	case err != nil:
+		return false, err
+	}
+
+	if !metav1.IsControlledBy(policy, template) {
+		backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonPolicyConflict,
+			fmt.Sprintf("BackupPolicy %s is not managed by this template, PVCs in namespace %s are not backed up by it", key, namespace), template)
+		return false, nil
+	}
+	// Suspending a generated policy, e.g. with "kubectl backup suspend", survives template changes
+	spec.Suspend = spec.Suspend || policy.Spec.Suspend
+	if equality.Semantic.DeepEqual(policy.Spec, spec) {
+		return true, nil
+	}
+	policy.Spec = spec
+	if err := r.Update(ctx, policy); err != nil {
+		return false, fmt.Errorf("failed to update BackupPolicy %s: %w", key, err)
+	}
+	logger.Info("Updated generated BackupPolicy", "template", template.Name, "policy", key)
+	return true, nil
+}
+
+// removeStalePolicies deletes generated policies of namespaces without annotated PVCs;
+// their backups are kept or deleted according to the deletion policy
+func (r *BackupPolicyTemplateReconciler) removeStalePolicies(ctx context.Context, template *backupv1alpha1.BackupPolicyTemplate, wanted map[client.ObjectKey]bool) error {
+	policies := &backupv1alpha1.BackupPolicyList{}
+	if err := r.List(ctx, policies, client.MatchingLabels{backup.LabelPolicyTemplate: template.Name}); err != nil {
+		return fmt.Errorf("failed to list generated BackupPolicies: %w", err)
+	}
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		key := client.ObjectKeyFromObject(policy)
+		if wanted[key] || !metav1.IsControlledBy(policy, template) || !policy.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.Delete(ctx, policy); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete BackupPolicy %s: %w", key, err)
+		}
+		log.FromContext(ctx).Info("Deleted generated BackupPolicy", "template", template.Name, "policy", key)
+		backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonPolicyRemoved,
+			fmt.Sprintf("Deleted BackupPolicy %s, no PVCs in namespace %s are annotated with the template",
+				key, strings.Join(policy.Spec.Namespaces, ", ")), template)
+	}
+	return nil
+}
+
+// generatedPolicyKey returns where the policy for the annotated PVCs of a namespace lives
+func generatedPolicyKey(template *backupv1alpha1.BackupPolicyTemplate, namespace string) client.ObjectKey {
+	if template.Spec.PolicyNamespace == "" {
+		return client.ObjectKey{Namespace: namespace, Name: template.Name}
+	}
+	return client.ObjectKey{Namespace: template.Spec.PolicyNamespace, Name: template.Name + "-" + namespace}
+}
+
+// generatedPolicySpec returns the template's policy spec restricted to the labelled PVCs of a namespace
+func generatedPolicySpec(template *backupv1alpha1.BackupPolicyTemplate, namespace string) backupv1alpha1.BackupPolicySpec {
+	spec := *template.Spec.Policy.DeepCopy()
+	spec.Selector = metav1.LabelSelector{MatchLabels: map[string]string{backup.LabelPolicyTemplate: template.Name}}
+	spec.Namespaces = []string{namespace}
+	return spec
+}
+
+// templatesForPVC maps a PVC to the templates it opts into or is still labelled with
+func templatesForPVC(_ context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, name := range indexPVCByTemplate(obj) {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: name}})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupPolicyTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.PersistentVolumeClaim{}, pvcTemplateIndex, indexPVCByTemplate); err != nil {
+		return fmt.Errorf("failed to index PVCs by policy template: %w", err)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1alpha1.BackupPolicyTemplate{}).
+		Owns(&backupv1alpha1.BackupPolicy{}).
+		// PVCs opt in and out by annotation; the label change is picked up too when it is edited by hand
+		Watches(&corev1.PersistentVolumeClaim{}, handler.EnqueueRequestsFromMapFunc(templatesForPVC),
+			builder.WithPredicates(predicate.Or(predicate.AnnotationChangedPredicate{}, predicate.LabelChangedPredicate{}))).
+		Named("backuppolicytemplate").
+		Complete(r)
+}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func annotatedPVC(namespace, name, template string) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if template != "" {
+		pvc.Annotations = map[string]string{backup.AnnotationPolicyTemplate: template}
+	}
+	return pvc
+}
+
+func TestTemplateGeneratesPolicyPerNamespace(t *testing.T) {
+	scheme := newTestScheme(t)
+	template := &backupv1alpha1.BackupPolicyTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "gold", UID: "gold-uid"},
+		Spec: backupv1alpha1.BackupPolicyTemplateSpec{
+			Policy: backupv1alpha1.BackupPolicySpec{
+				Schedule:  "0 1 * * *",
+				Strategy:  "snapshot",
+				Retention: backupv1alpha1.Retention{MaxBackups: 14},
+			},
+		},
+	}
+	userPolicy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "gold", Namespace: "c"},
+		Spec:       backupv1alpha1.BackupPolicySpec{Schedule: "0 3 * * *"},
+	}
+	stale := annotatedPVC("d", "old", "")
+	stale.Labels = map[string]string{backup.LabelPolicyTemplate: "gold"}
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(template, userPolicy, stale,
+			annotatedPVC("a", "data", "gold"), annotatedPVC("a", "logs", "gold"), annotatedPVC("b", "db", "gold"),
+			annotatedPVC("c", "cache", "gold"), annotatedPVC("e", "other", "silver"), annotatedPVC("e", "plain", "")).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicyTemplate{}).
+		Build()
+	r := &BackupPolicyTemplateReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKey{Name: "gold"}}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	for _, key := range []client.ObjectKey{{Namespace: "a", Name: "data"}, {Namespace: "a", Name: "logs"}, {Namespace: "b", Name: "db"}} {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := fakeClient.Get(ctx, key, pvc); err != nil {
+			t.Fatalf("failed to get PVC: %v", err)
+		}
+		if pvc.Labels[backup.LabelPolicyTemplate] != "gold" {
+			t.Fatalf("expected annotated PVC %s to be labelled, got %v", key, pvc.Labels)
+		}
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(stale), stale); err != nil {
+		t.Fatalf("failed to get PVC: %v", err)
+	}
+	if _, found := stale.Labels[backup.LabelPolicyTemplate]; found {
+		t.Fatalf("expected the label of a PVC without annotation to be removed, got %v", stale.Labels)
+	}
+
+	policy := &backupv1alpha1.BackupPolicy{}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "a", Name: "gold"}, policy); err != nil {
+		t.Fatalf("expected a generated policy in namespace a: %v", err)
+	}
+	if !metav1.IsControlledBy(policy, template) || policy.Labels[backup.LabelPolicyTemplate] != "gold" {
+		t.Fatalf("expected the policy to be owned by the template, got %+v", policy.ObjectMeta)
+	}
+	if policy.Spec.Schedule != "0 1 * * *" || policy.Spec.Retention.MaxBackups != 14 ||
+		policy.Spec.Selector.MatchLabels[backup.LabelPolicyTemplate] != "gold" || !reflect.DeepEqual(policy.Spec.Namespaces, []string{"a"}) {
+		t.Fatalf("expected the template spec restricted to namespace a, got %+v", policy.Spec)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "c", Name: "gold"}, userPolicy); err != nil {
+		t.Fatalf("failed to get BackupPolicy: %v", err)
+	}
+	if userPolicy.Spec.Schedule != "0 3 * * *" {
+		t.Fatalf("expected a policy not generated by the template to be left alone, got %+v", userPolicy.Spec)
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, template); err != nil {
+		t.Fatalf("failed to get BackupPolicyTemplate: %v", err)
+	}
+	if template.Status.PVCCount != 4 || !reflect.DeepEqual(template.Status.Policies, []string{"a/gold", "b/gold"}) {
+		t.Fatalf("expected 4 PVCs and the policies of namespaces a and b, got %+v", template.Status)
+	}
+
+	// Opting out removes the policy of the namespace, template changes reach the remaining ones
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "b", Name: "db"}, pvc); err != nil {
+		t.Fatalf("failed to get PVC: %v", err)
+	}
+	delete(pvc.Annotations, backup.AnnotationPolicyTemplate)
+	if err := fakeClient.Update(ctx, pvc); err != nil {
+		t.Fatalf("failed to update PVC: %v", err)
+	}
+	template.Spec.Policy.Schedule = "30 1 * * *"
+	if err := fakeClient.Update(ctx, template); err != nil {
+		t.Fatalf("failed to update BackupPolicyTemplate: %v", err)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "a", Name: "gold"}, policy); err != nil {
+		t.Fatalf("failed to get BackupPolicy: %v", err)
+	}
+	policy.Spec.Suspend = true
+	if err := fakeClient.Update(ctx, policy); err != nil {
+		t.Fatalf("failed to update BackupPolicyTemplate: %v", err)
+	}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "b", Name: "gold"}, policy); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the policy of namespace b to be deleted, got %v", err)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "a", Name: "gold"}, policy); err != nil {
+		t.Fatalf("failed to get BackupPolicy: %v", err)
+	}
+	if policy.Spec.Schedule != "30 1 * * *" || !policy.Spec.Suspend {
+		t.Fatalf("expected the generated policy to follow the template and stay suspended, got %+v", policy.Spec)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "b", Name: "db"}, pvc); err != nil {
+		t.Fatalf("failed to get PVC: %v", err)
+	}
+	if _, found := pvc.Labels[backup.LabelPolicyTemplate]; found {
+		t.Fatalf("expected the label of the opted out PVC to be removed, got %v", pvc.Labels)
+	}
+}
+
+func TestGeneratedPolicyKeyInPolicyNamespace(t *testing.T) {
+	template := &backupv1alpha1.BackupPolicyTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "gold"},
+		Spec:       backupv1alpha1.BackupPolicyTemplateSpec{PolicyNamespace: "backup-system"},
+	}
+	if key := generatedPolicyKey(template, "apps"); key != (client.ObjectKey{Namespace: "backup-system", Name: "gold-apps"}) {
+		t.Fatalf("expected the policy in the policy namespace, got %s", key)
+	}
+	template.Spec.PolicyNamespace = ""
+	if key := generatedPolicyKey(template, "apps"); key != (client.ObjectKey{Namespace: "apps", Name: "gold"}) {
+		t.Fatalf("expected the policy in the PVC namespace, got %s", key)
+	}
+}
@@ -0,0 +1,424 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// Catalogue sync interval of BackupRepositories that do not set one
+const defaultRepositorySyncInterval = time.Hour
+
+// BackupRepositoryReconciler imports the backups found in a destination as read-only Backups
+type BackupRepositoryReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backuprepositories,verbs=get;list;watch
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backuprepositories/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=backup.backup.example.com,resources=backuprepositories/finalizers,verbs=update
+
+// Reconcile periodically discovers the restic repositories of the destination and keeps a Backup per snapshot.
+// A sync runs in rounds: each round starts one catalogue Job per repository describing the next batch of
+// snapshots without a Backup, until all are imported or a round failed.
+func (r *BackupRepositoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	repository := &backupv1alpha1.BackupRepository{}
+	if err := r.Get(ctx, req.NamespacedName, repository); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !repository.DeletionTimestamp.IsZero() {
+		// Imported Backups and catalogue Jobs are garbage collected through their owner reference
+		return ctrl.Result{}, nil
+	}
+	original := repository.Status.DeepCopy()
+
+	strategy, err := newBackupStrategy(ctx, r.Client, r.Recorder, "external", backup.RepositoryPolicy(repository, ""), nil)
+	if err != nil {
+		setRepositoryPhase(repository, backupv1alpha1.RepositoryPhaseError, "InvalidDestination", err.Error())
+		return ctrl.Result{RequeueAfter: requeueAfterError}, r.patchStatus(ctx, repository, original)
+	}
+	cataloguer, ok := strategy.(backup.Cataloguer)
+	if !ok {
+		return ctrl.Result{}, fmt.Errorf("backups of %s destinations cannot be imported", repository.Spec.Destination.Type)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, client.InNamespace(repository.Namespace),
+		client.MatchingLabels{backup.LabelRepository: repository.Name}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list catalogue Jobs: %w", err)
+	}
+	active := 0
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if !isJobFinished(job) {
+			active++
+			continue
+		}
+		if err := r.importCatalog(ctx, repository, cataloguer, job); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	switch {
+	case active > 0:
+		setRepositoryPhase(repository, backupv1alpha1.RepositoryPhaseSyncing, "Syncing",
+			fmt.Sprintf("Listing the snapshots of %d repositories", active))
+		return ctrl.Result{RequeueAfter: requeueWhileJobActive}, r.patchStatus(ctx, repository, original)
+	case repository.Status.Phase == backupv1alpha1.RepositoryPhaseSyncing && repository.Status.Message == "":
+		return r.startSync(ctx, repository, cataloguer, original)
+	case repository.Status.Phase == backupv1alpha1.RepositoryPhaseSyncing:
+		return r.finishSync(ctx, repository, original)
+	}
+
+	if last := repository.Status.LastSyncTime; last != nil && repository.Status.ObservedGeneration == repository.Generation {
+		if wait := time.Until(last.Add(repositorySyncInterval(repository))); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, r.patchStatus(ctx, repository, original)
+		}
+	}
+	return r.startSync(ctx, repository, cataloguer, original)
+}
+
+// startSync discovers the repositories of the destination, removes the Backups of forgotten snapshots and starts
+// a catalogue Job for each repository with snapshots that have no Backup yet. The sync finishes once no Job
+// was started.
+func (r *BackupRepositoryReconciler) startSync(ctx context.Context, repository *backupv1alpha1.BackupRepository, cataloguer backup.Cataloguer, original *backupv1alpha1.BackupRepositoryStatus) (ctrl.Result, error) {
+	sources, err := cataloguer.DiscoverRepositories(ctx, repository)
+	if err != nil {
+		setRepositoryPhase(repository, backupv1alpha1.RepositoryPhaseError, "DiscoveryFailed", err.Error())
+		backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonCatalogFailed,
+			fmt.Sprintf("Failed to search %s for repositories: %v", repository.Spec.Destination.URL, err), repository)
+		return ctrl.Result{RequeueAfter: requeueAfterError}, r.patchStatus(ctx, repository, original)
+	}
+	if err := r.removeVanishedBackups(ctx, repository, sources); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var failures []string
+	started := 0
+	for _, source := range sources {
+		missing, err := r.reconcileSnapshots(ctx, repository, cataloguer, source)
+		if err == nil && len(missing) > 0 {
+			if _, err = cataloguer.Catalog(ctx, repository, source, missing); err == nil {
+				started++
+			}
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", source.Location, err))
+		}
+	}
+	repository.Status.ObservedGeneration = repository.Generation
+	repository.Status.Repositories = len(sources)
+	repository.Status.Message = strings.Join(failures, "; ")
+	if started == 0 {
+		return r.finishSync(ctx, repository, original)
+	}
+
+	log.FromContext(ctx).Info("Syncing backup catalogue", "repositories", len(sources), "catalogueJobs", started)
+	setRepositoryPhase(repository, backupv1alpha1.RepositoryPhaseSyncing, "Syncing",
+		fmt.Sprintf("Listing the snapshots of %d repositories", started))
+	return ctrl.Result{RequeueAfter: requeueWhileJobActive}, r.patchStatus(ctx, repository, original)
+}
+
+// reconcileSnapshots deletes the Backups of snapshots no longer in the source repository, e.g. because retention
+// in the source cluster forgot them, and returns the snapshots that have no Backup yet
+func (r *BackupRepositoryReconciler) reconcileSnapshots(ctx context.Context, repository *backupv1alpha1.BackupRepository, cataloguer backup.Cataloguer, source backup.SourceRepository) ([]string, error) {
+	ids, err := cataloguer.ListSnapshots(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := r.listImportedBackups(ctx, repository, importedBackupLabels(repository, source))
+	if err != nil {
+		return nil, err
+	}
+
+	var imported []string
+	for i := range existing {
+		item := &existing[i]
+		if backup.SnapshotListed(ids, item.Status.SnapshotID) {
+			imported = append(imported, item.Status.SnapshotID)
+			continue
+		}
+		if err := r.Delete(ctx, item); client.IgnoreNotFound(err) != nil {
+			return nil, fmt.Errorf("failed to delete imported Backup %s/%s: %w", item.Namespace, item.Name, err)
+		}
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if !slices.ContainsFunc(imported, func(short string) bool { return strings.HasPrefix(id, short) }) {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// finishSync records the result of a sync once all catalogue Jobs have been imported
+func (r *BackupRepositoryReconciler) finishSync(ctx context.Context, repository *backupv1alpha1.BackupRepository, original *backupv1alpha1.BackupRepositoryStatus) (ctrl.Result, error) {
+	backups, err := r.listImportedBackups(ctx, repository, nil)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	now := metav1.Now()
+	repository.Status.LastSyncTime = &now
+	repository.Status.BackupCount = len(backups)
+
+	if repository.Status.Message != "" {
+		setRepositoryPhase(repository, backupv1alpha1.RepositoryPhaseError, "CatalogFailed", repository.Status.Message)
+		backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonCatalogFailed,
+			fmt.Sprintf("Failed to list the snapshots of some repositories: %s", repository.Status.Message), repository)
+	} else {
+		message := fmt.Sprintf("Imported %d backups from %d repositories", len(backups), repository.Status.Repositories)
+		setRepositoryPhase(repository, backupv1alpha1.RepositoryPhaseReady, "Synced", message)
+		backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonCatalogSynced, message, repository)
+	}
+	return ctrl.Result{RequeueAfter: repositorySyncInterval(repository)}, r.patchStatus(ctx, repository, original)
+}
+
+// importCatalog turns the snapshots described by a finished catalogue Job into Backups and deletes the Job.
+// Snapshots the Job did not describe are reported, which ends the sync instead of retrying them in a loop.
+func (r *BackupRepositoryReconciler) importCatalog(ctx context.Context, repository *backupv1alpha1.BackupRepository, cataloguer backup.Cataloguer, job *batchv1.Job) error {
+	source := backup.SourceRepositoryForJob(job)
+	message := jobTerminationMessage(ctx, r.Client, job)
+
+	var failure string
+	if job.Status.Succeeded > 0 {
+		entries := backup.ParseCatalog(message)
+		if err := r.importBackups(ctx, repository, cataloguer, source, entries); err != nil {
+			return err
+		}
+		if requested := len(backup.CatalogSnapshotsForJob(job)); len(entries) < requested {
+			failure = fmt.Sprintf("%s: %d of %d snapshots could not be described", source.Location, requested-len(entries), requested)
+		}
+	} else {
+		if message == "" {
+			message = "catalogue Job failed"
+		}
+		failure = fmt.Sprintf("%s: %s", source.Location, message)
+	}
+	if failure != "" {
+		if repository.Status.Message != "" {
+			failure = repository.Status.Message + "; " + failure
+		}
+		repository.Status.Message = failure
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	if err := r.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagation}); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete catalogue Job %s/%s: %w", job.Namespace, job.Name, err)
+	}
+	return nil
+}
+
+// importBackups creates a Backup for every described snapshot that has none yet
+func (r *BackupRepositoryReconciler) importBackups(ctx context.Context, repository *backupv1alpha1.BackupRepository, cataloguer backup.Cataloguer, source backup.SourceRepository, entries []backup.CatalogEntry) error {
+	existing, err := r.listImportedBackups(ctx, repository, importedBackupLabels(repository, source))
+	if err != nil {
+		return err
+	}
+	imported := make(map[string]bool, len(existing))
+	for i := range existing {
+		imported[existing[i].Status.SnapshotID] = true
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if imported[entry.SnapshotID] {
+			continue
+		}
+		if err := r.createImportedBackup(ctx, repository, cataloguer, source, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createImportedBackup creates a completed, read-only Backup for a snapshot. It is named after the backup
+// that wrote the snapshot; the snapshot ID is appended when another source used the same name.
+func (r *BackupRepositoryReconciler) createImportedBackup(ctx context.Context, repository *backupv1alpha1.BackupRepository, cataloguer backup.Cataloguer, source backup.SourceRepository, entry *backup.CatalogEntry) error {
+	name := entry.Backup
+	if name == "" || len(validation.IsDNS1123Subdomain(name)) > 0 {
+		name = fmt.Sprintf("%s-%s-%s", source.Policy, source.PVC, entry.Time.UTC().Format("20060102-150405"))
+	}
+	manifests, err := cataloguer.ManifestsFor(ctx, source, entry.Backup)
+	if err != nil {
+		return err
+	}
+
+	taken := metav1.NewTime(entry.Time)
+	status := backupv1alpha1.BackupStatus{
+		Phase:          backupv1alpha1.BackupPhaseCompleted,
+		Location:       source.Location,
+		Size:           entry.Size(),
+		SnapshotID:     entry.SnapshotID,
+		Manifests:      manifests,
+		StartTime:      &taken,
+		CompletionTime: &taken,
+	}
+	item := &backupv1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: repository.Namespace,
+			Labels:    importedBackupLabels(repository, source),
+		},
+		Spec: backupv1alpha1.BackupSpec{
+			PolicyRef: backupv1alpha1.PolicyReference{Name: source.Policy, Namespace: repository.Namespace},
+			PVCName:   source.PVC,
+			Strategy:  "external",
+			Imported:  &backupv1alpha1.ImportSource{Repository: repository.Name, Namespace: source.Namespace},
+		},
+	}
+	if entry.IsDump() {
+		item.Spec.Imported.DumpFile = entry.Path
+	}
+	if err := controllerutil.SetControllerReference(repository, item, r.Scheme); err != nil {
+		return err
+	}
+
+	err = r.Create(ctx, item)
+	if apierrors.IsAlreadyExists(err) {
+		item.Name = fmt.Sprintf("%s-%s", name, entry.SnapshotID)
+		item.ResourceVersion = ""
+		err = r.Create(ctx, item)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create imported Backup %s/%s: %w", item.Namespace, item.Name, err)
+	}
+	item.Status = status
+	if err := r.Status().Update(ctx, item); err != nil {
+		return fmt.Errorf("failed to update status of imported Backup %s/%s: %w", item.Namespace, item.Name, err)
+	}
+	log.FromContext(ctx).Info("Imported backup", "backup", item.Name, "snapshot", entry.SnapshotID, "repository", source.Location)
+	return nil
+}
+
+// removeVanishedBackups deletes the imported Backups of repositories that are no longer found
+func (r *BackupRepositoryReconciler) removeVanishedBackups(ctx context.Context, repository *backupv1alpha1.BackupRepository, sources []backup.SourceRepository) error {
+	found := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		found[source.Location] = true
+	}
+	backups, err := r.listImportedBackups(ctx, repository, nil)
+	if err != nil {
+		return err
+	}
+	for i := range backups {
+		item := &backups[i]
+		if found[item.Status.Location] {
+			continue
+		}
+		if err := r.Delete(ctx, item); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete imported Backup %s/%s: %w", item.Namespace, item.Name, err)
+		}
+	}
+	return nil
+}
+
+// listImportedBackups lists the Backups imported by the repository, optionally only those with the given labels
+func (r *BackupRepositoryReconciler) listImportedBackups(ctx context.Context, repository *backupv1alpha1.BackupRepository, labels map[string]string) ([]backupv1alpha1.Backup, error) {
+	if labels == nil {
+		labels = map[string]string{backup.LabelRepository: repository.Name}
+	}
+	backups := &backupv1alpha1.BackupList{}
+	if err := r.List(ctx, backups, client.InNamespace(repository.Namespace), client.MatchingLabels(labels)); err != nil {
+		return nil, fmt.Errorf("failed to list imported Backups: %w", err)
+	}
+	return backups.Items, nil
+}
+
+// importedBackupLabels returns the labels identifying the Backups imported from a source repository
+func importedBackupLabels(repository *backupv1alpha1.BackupRepository, source backup.SourceRepository) map[string]string {
+	return map[string]string{
+		backup.LabelRepository:      repository.Name,
+		backup.LabelSourcePolicy:    source.Policy,
+		backup.LabelSourceNamespace: source.Namespace,
+		backup.LabelPVC:             source.PVC,
+		backup.LabelStrategy:        "external",
+	}
+}
+
+// repositorySyncInterval returns the interval between catalogue syncs
+func repositorySyncInterval(repository *backupv1alpha1.BackupRepository) time.Duration {
+	if repository.Spec.SyncInterval != nil && repository.Spec.SyncInterval.Duration > 0 {
+		return repository.Spec.SyncInterval.Duration
+	}
+	return defaultRepositorySyncInterval
+}
+
+// setRepositoryPhase records the phase with the Ready condition; a sync in progress keeps the previous result
+func setRepositoryPhase(repository *backupv1alpha1.BackupRepository, phase, reason, message string) {
+	repository.Status.Phase = phase
+	status := metav1.ConditionTrue
+	switch phase {
+	case backupv1alpha1.RepositoryPhaseError:
+		status = metav1.ConditionFalse
+	case backupv1alpha1.RepositoryPhaseSyncing:
+		if meta.FindStatusCondition(repository.Status.Conditions, backupv1alpha1.ConditionReady) != nil {
+			return
+		}
+		status = metav1.ConditionFalse
+	}
+	meta.SetStatusCondition(&repository.Status.Conditions, metav1.Condition{
+		Type:               backupv1alpha1.ConditionReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: repository.Generation,
+	})
+}
+
+// patchStatus writes the status changes made since original with a merge patch
+func (r *BackupRepositoryReconciler) patchStatus(ctx context.Context, repository *backupv1alpha1.BackupRepository, original *backupv1alpha1.BackupRepositoryStatus) error {
+	if equality.Semantic.DeepEqual(original, &repository.Status) {
+		return nil
+	}
+	base := repository.DeepCopy()
+	base.Status = *original
+	return client.IgnoreNotFound(r.Status().Patch(ctx, repository, client.MergeFrom(base)))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupRepositoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1alpha1.BackupRepository{}).
+		// Finished catalogue Jobs are imported right away
+		Owns(&batchv1.Job{}).
+		Named("backuprepository").
+		Complete(r)
+}
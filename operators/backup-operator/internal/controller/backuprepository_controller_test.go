@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// stubCataloguer serves fixed repositories and snapshots and finds manifests for every backup except "daily-data-2"
+type stubCataloguer struct {
+	sources   []backup.SourceRepository
+	snapshots []string
+	// Snapshots passed to Catalog
+	catalogued []string
+}
+
+func (c *stubCataloguer) DiscoverRepositories(context.Context, *backupv1alpha1.BackupRepository) ([]backup.SourceRepository, error) {
+	return c.sources, nil
+}
+
+func (c *stubCataloguer) ListSnapshots(context.Context, backup.SourceRepository) ([]string, error) {
+	return c.snapshots, nil
+}
+
+func (c *stubCataloguer) Catalog(_ context.Context, _ *backupv1alpha1.BackupRepository, _ backup.SourceRepository, snapshotIDs []string) (string, error) {
+	c.catalogued = append(c.catalogued, snapshotIDs...)
+	return "job", nil
+}
+
+func (c *stubCataloguer) ManifestsFor(_ context.Context, source backup.SourceRepository, backupName string) (string, error) {
+	if backupName == "" || backupName == "daily-data-2" {
+		return "", nil
+	}
+	return ".manifests/" + source.Policy + "/" + backupName + ".yaml", nil
+}
+
+func finishedCatalogJob(repository *backupv1alpha1.BackupRepository, source backup.SourceRepository, snapshotIDs []string, message string) (*batchv1.Job, *corev1.Pod) {
+	name := backup.CatalogJobName(repository, source, snapshotIDs)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: repository.Namespace,
+			Labels: map[string]string{
+				backup.LabelRepository:      repository.Name,
+				backup.LabelSourcePolicy:    source.Policy,
+				backup.LabelSourceNamespace: source.Namespace,
+				backup.LabelPVC:             source.PVC,
+			},
+		},
+		Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "catalog",
+			Env: []corev1.EnvVar{
+				{Name: "RESTIC_REPOSITORY", Value: source.Location},
+				{Name: "CATALOG_SNAPSHOTS", Value: strings.Join(snapshotIDs, " ")},
+			},
+		}}}}},
+		Status: batchv1.JobStatus{Succeeded: 1},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-pod", Namespace: repository.Namespace, Labels: map[string]string{batchv1.JobNameLabel: name}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message, FinishedAt: metav1.Now()}},
+		}}},
+	}
+	return job, pod
+}
+
+func TestBackupRepositoryImportsCatalog(t *testing.T) {
+	scheme := newTestScheme(t)
+	repository := &backupv1alpha1.BackupRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "dr", UID: "repo-uid"},
+		Spec: backupv1alpha1.BackupRepositorySpec{
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://bucket/backups"},
+		},
+		Status: backupv1alpha1.BackupRepositoryStatus{Phase: backupv1alpha1.RepositoryPhaseSyncing, Repositories: 1},
+	}
+	source := backup.SourceRepository{Policy: "daily", Namespace: "apps", PVC: "data", Location: "s3:bucket/backups/daily/apps/data"}
+	job, pod := finishedCatalogJob(repository, source, []string{"b2b2b2b2ff", "c3c3c3c3ff", "d4d4d4d4ff"},
+		"c3c3c3c3 2025-01-03T02:00:00Z daily-data-3 /data 1024\n"+
+			"b2b2b2b2 2025-01-02T02:00:00Z daily-data-2 /data 0\n"+
+			"d4d4d4d4 2025-01-01T02:00:00Z - /dump/db.sql 0\n")
+
+	// A Backup imported by an earlier batch of the sync
+	earlier := &backupv1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "daily-data-1", Namespace: "dr", Labels: importedBackupLabels(repository, source)},
+		Spec:       backupv1alpha1.BackupSpec{Imported: &backupv1alpha1.ImportSource{Repository: "prod", Namespace: "apps"}},
+		Status:     backupv1alpha1.BackupStatus{SnapshotID: "a1a1a1a1", Location: source.Location},
+	}
+	// A local Backup that already uses the name of an imported one
+	local := &backupv1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "daily-data-3", Namespace: "dr"}}
+
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(repository, job, pod, earlier, local).
+		WithStatusSubresource(&backupv1alpha1.BackupRepository{}, &backupv1alpha1.Backup{}).
+		Build()
+	r := &BackupRepositoryReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+
+	original := repository.Status.DeepCopy()
+	if err := r.importCatalog(ctx, repository, &stubCataloguer{}, job); err != nil {
+		t.Fatalf("importCatalog failed: %v", err)
+	}
+	if _, err := r.finishSync(ctx, repository, original); err != nil {
+		t.Fatalf("finishSync failed: %v", err)
+	}
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(job), &batchv1.Job{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the catalogue Job to be deleted, got %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(earlier), &backupv1alpha1.Backup{}); err != nil {
+		t.Fatalf("expected the Backup of an earlier batch to be kept: %v", err)
+	}
+
+	renamed := &backupv1alpha1.Backup{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "dr", Name: "daily-data-3-c3c3c3c3"}, renamed); err != nil {
+		t.Fatalf("expected the clashing Backup to be imported under a suffixed name: %v", err)
+	}
+	if renamed.Status.Phase != backupv1alpha1.BackupPhaseCompleted || renamed.Status.Size != "1Ki" ||
+		renamed.Status.Manifests != ".manifests/daily/daily-data-3.yaml" || renamed.Status.Location != source.Location {
+		t.Fatalf("unexpected status of the imported Backup: %+v", renamed.Status)
+	}
+	if renamed.Spec.PolicyRef.Name != "daily" || renamed.Spec.Imported.Namespace != "apps" || metav1.GetControllerOf(renamed) == nil {
+		t.Fatalf("unexpected imported Backup %+v", renamed)
+	}
+
+	dump := &backupv1alpha1.Backup{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "dr", Name: "daily-data-20250101-020000"}, dump); err != nil {
+		t.Fatalf("expected the untagged snapshot to be named after its time: %v", err)
+	}
+	if dump.Spec.Imported.DumpFile != "/dump/db.sql" {
+		t.Fatalf("expected the dump file to be recorded, got %+v", dump.Spec.Imported)
+	}
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(repository), repository); err != nil {
+		t.Fatalf("failed to get BackupRepository: %v", err)
+	}
+	if repository.Status.Phase != backupv1alpha1.RepositoryPhaseReady || repository.Status.BackupCount != 4 || repository.Status.LastSyncTime == nil {
+		t.Fatalf("unexpected BackupRepository status %+v", repository.Status)
+	}
+	if !meta.IsStatusConditionTrue(repository.Status.Conditions, backupv1alpha1.ConditionReady) {
+		t.Fatalf("expected Ready condition, got %v", repository.Status.Conditions)
+	}
+}
+
+func TestBackupRepositorySyncRounds(t *testing.T) {
+	scheme := newTestScheme(t)
+	repository := &backupv1alpha1.BackupRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "dr", UID: "repo-uid"},
+		Spec: backupv1alpha1.BackupRepositorySpec{
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://bucket/backups"},
+		},
+	}
+	source := backup.SourceRepository{Policy: "daily", Namespace: "apps", PVC: "data", Location: "s3:bucket/backups/daily/apps/data"}
+	imported := func(name, snapshotID string) *backupv1alpha1.Backup {
+		return &backupv1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dr", Labels: importedBackupLabels(repository, source)},
+			Spec:       backupv1alpha1.BackupSpec{Imported: &backupv1alpha1.ImportSource{Repository: "prod", Namespace: "apps"}},
+			Status:     backupv1alpha1.BackupStatus{SnapshotID: snapshotID, Location: source.Location},
+		}
+	}
+	// Older than any catalogue window, but still in the repository
+	old := imported("daily-data-1", "a1a1a1a1")
+	forgotten := imported("daily-data-0", "f0f0f0f0")
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(repository, old, forgotten).
+		WithStatusSubresource(&backupv1alpha1.BackupRepository{}, &backupv1alpha1.Backup{}).
+		Build()
+	r := &BackupRepositoryReconciler{Client: fakeClient, Scheme: scheme}
+	cataloguer := &stubCataloguer{
+		sources:   []backup.SourceRepository{source},
+		snapshots: []string{"a1a1a1a1ff", "b2b2b2b2ff"},
+	}
+	ctx := context.Background()
+
+	if _, err := r.startSync(ctx, repository, cataloguer, repository.Status.DeepCopy()); err != nil {
+		t.Fatalf("startSync failed: %v", err)
+	}
+	if len(cataloguer.catalogued) != 1 || cataloguer.catalogued[0] != "b2b2b2b2ff" {
+		t.Fatalf("expected only the snapshot without a Backup to be catalogued, got %v", cataloguer.catalogued)
+	}
+	if repository.Status.Phase != backupv1alpha1.RepositoryPhaseSyncing {
+		t.Fatalf("expected the sync to continue, got phase %s", repository.Status.Phase)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(old), &backupv1alpha1.Backup{}); err != nil {
+		t.Fatalf("expected the Backup of an old snapshot to be kept: %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(forgotten), &backupv1alpha1.Backup{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the Backup of the forgotten snapshot to be deleted, got %v", err)
+	}
+
+	// The next round finds every snapshot imported and finishes the sync
+	cataloguer.snapshots = []string{"a1a1a1a1ff"}
+	cataloguer.catalogued = nil
+	if _, err := r.startSync(ctx, repository, cataloguer, repository.Status.DeepCopy()); err != nil {
+		t.Fatalf("startSync failed: %v", err)
+	}
+	if len(cataloguer.catalogued) != 0 || repository.Status.Phase != backupv1alpha1.RepositoryPhaseReady {
+		t.Fatalf("expected the sync to finish, got phase %s and %v", repository.Status.Phase, cataloguer.catalogued)
+	}
+}
+
+func TestBackupRepositorySyncInterval(t *testing.T) {
+	repository := &backupv1alpha1.BackupRepository{}
+	if got := repositorySyncInterval(repository); got != defaultRepositorySyncInterval {
+		t.Fatalf("expected the default interval, got %s", got)
+	}
+	repository.Spec.SyncInterval = &metav1.Duration{Duration: 10 * time.Minute}
+	if got := repositorySyncInterval(repository); got != 10*time.Minute {
+		t.Fatalf("expected 10m, got %s", got)
+	}
+}
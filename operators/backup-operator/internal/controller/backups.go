@@ -0,0 +1,318 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+	"github.com/example/backup-operator/internal/metrics"
+)
+
+// listBackups returns the Backups created by the policy in all namespaces, newest first
+func (r *BackupPolicyReconciler) listBackups(ctx context.Context, policy *backupv1alpha1.BackupPolicy) ([]backupv1alpha1.Backup, error) {
+	list := &backupv1alpha1.BackupList{}
+	if err := r.List(ctx, list, client.MatchingFields{policyLabelIndex: policyIndexKey(policy)}); err != nil {
+		return nil, fmt.Errorf("failed to list Backups: %w", err)
+	}
+
+	items := list.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, tj := backupTime(&items[i]), backupTime(&items[j])
+		if ti.Equal(tj) {
+			// Fall back to name to keep deterministic order
+			if items[i].Namespace == items[j].Namespace {
+				return items[i].Name > items[j].Name
+			}
+			return items[i].Namespace > items[j].Namespace
+		}
+		return ti.After(tj)
+	})
+	return items, nil
+}
+
+// createBackupRecord creates the Backup resource for a backup that has just been started
+func (r *BackupPolicyReconciler) createBackupRecord(ctx context.Context, policy *backupv1alpha1.BackupPolicy, pvc *corev1.PersistentVolumeClaim, strategy string, result *backup.BackupResult) error {
+	item, err := r.newPolicyBackup(policy, result.Name, pvc.Namespace, pvc.Name, strategy)
+	if err != nil {
+		return err
+	}
+	if err := r.Create(ctx, item); err != nil {
+		return fmt.Errorf("failed to create Backup %s/%s: %w", item.Namespace, item.Name, err)
+	}
+
+	item.Status = backupv1alpha1.BackupStatus{
+		Phase:     backupv1alpha1.BackupPhaseRunning,
+		Location:  result.Location,
+		Size:      result.Size,
+		StartTime: &metav1.Time{Time: result.Timestamp},
+		Manifests: result.ManifestsKey,
+	}
+	if err := r.Status().Update(ctx, item); err != nil {
+		return fmt.Errorf("failed to update Backup %s/%s status: %w", item.Namespace, item.Name, err)
+	}
+	return nil
+}
+
+// newPolicyBackup returns a Backup of the policy, owned by it when both share a namespace
+func (r *BackupPolicyReconciler) newPolicyBackup(policy *backupv1alpha1.BackupPolicy, name, namespace, pvcName, strategy string) (*backupv1alpha1.Backup, error) {
+	item := &backupv1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  namespace,
+			Finalizers: []string{backup.BackupFinalizer},
+			Labels: map[string]string{
+				backup.LabelPolicy:          policy.Name,
+				backup.LabelPolicyNamespace: policy.Namespace,
+				backup.LabelPVC:             pvcName,
+				backup.LabelStrategy:        strategy,
+			},
+		},
+		Spec: backupv1alpha1.BackupSpec{
+			PolicyRef: backupv1alpha1.PolicyReference{Name: policy.Name, Namespace: policy.Namespace},
+			PVCName:   pvcName,
+			Strategy:  strategy,
+		},
+	}
+
+	// Owner references cannot cross namespaces; Backups elsewhere are tracked by label only
+	if policy.Namespace == namespace {
+		if err := controllerutil.SetControllerReference(policy, item, r.Scheme); err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+// migrateStoredBackups turns the backups listed in the status by earlier versions of the operator into Backup
+// resources. Entries that cannot be migrated yet stay in the list and are retried on the next reconcile.
+func (r *BackupPolicyReconciler) migrateStoredBackups(ctx context.Context, policy *backupv1alpha1.BackupPolicy) {
+	logger := log.FromContext(ctx)
+
+	var remaining []backupv1alpha1.StoredBackup
+	for _, stored := range policy.Status.StoredBackups {
+		if err := r.migrateStoredBackup(ctx, policy, &stored); err != nil {
+			logger.Error(err, "Failed to migrate stored backup", "backup", stored.Name, "namespace", stored.Namespace)
+			remaining = append(remaining, stored)
+		}
+	}
+	if migrated := len(policy.Status.StoredBackups) - len(remaining); migrated > 0 {
+		logger.Info("Migrated stored backups to Backup resources", "count", migrated)
+	}
+	policy.Status.StoredBackups = remaining
+}
+
+// migrateStoredBackup creates the Backup of a status entry unless it already exists
+func (r *BackupPolicyReconciler) migrateStoredBackup(ctx context.Context, policy *backupv1alpha1.BackupPolicy, stored *backupv1alpha1.StoredBackup) error {
+	strategy := stored.Strategy
+	if strategy == "" {
+		strategy = defaultStrategy
+	}
+	namespace := stored.Namespace
+	if namespace == "" {
+		namespace = policy.Namespace
+	}
+	item, err := r.newPolicyBackup(policy, stored.Name, namespace, stored.PVCName, strategy)
+	if err != nil {
+		return err
+	}
+	if err := r.Create(ctx, item); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to create Backup %s/%s: %w", item.Namespace, item.Name, err)
+	}
+
+	// Anything not finished is left Running so the Job or VolumeSnapshot decides its outcome
+	phase := stored.Status
+	if phase != backupv1alpha1.BackupPhaseCompleted && phase != backupv1alpha1.BackupPhaseFailed {
+		phase = backupv1alpha1.BackupPhaseRunning
+	}
+	item.Status = backupv1alpha1.BackupStatus{
+		Phase:     phase,
+		Location:  stored.Location,
+		Size:      stored.Size,
+		StartTime: stored.Timestamp,
+	}
+	if phase != backupv1alpha1.BackupPhaseRunning {
+		item.Status.CompletionTime = stored.Timestamp
+	}
+	if err := r.Status().Update(ctx, item); err != nil {
+		return fmt.Errorf("failed to update Backup %s/%s status: %w", item.Namespace, item.Name, err)
+	}
+	return nil
+}
+
+// hasPendingSnapshots reports whether a snapshot Backup of the policy waits for its VolumeSnapshot
+func hasPendingSnapshots(items []backupv1alpha1.Backup) bool {
+	for i := range items {
+		if items[i].Spec.Strategy == "snapshot" && items[i].Status.Phase == backupv1alpha1.BackupPhaseRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneBackups deletes finished Backups beyond the retention policy and returns the remaining ones.
+// Completed and failed Backups are limited separately so failures never push out restorable backups.
+func (r *BackupPolicyReconciler) pruneBackups(ctx context.Context, policy *backupv1alpha1.BackupPolicy, items []backupv1alpha1.Backup) []backupv1alpha1.Backup {
+	logger := log.FromContext(ctx)
+
+	retention := policy.Spec.Retention
+	var cutoff time.Time
+	if retention.MaxAge != "" {
+		parsed, err := backup.RetentionCutoff(retention.MaxAge, time.Now())
+		if err != nil {
+			logger.Error(err, "Failed to parse retention maxAge", "value", retention.MaxAge)
+		}
+		cutoff = parsed
+	}
+	if retention.MaxBackups <= 0 && cutoff.IsZero() {
+		return items
+	}
+
+	seen := make(map[string]int)
+	deleted := make(map[string]int)
+	expired := make(map[types.NamespacedName]int)
+	remaining := items[:0]
+	for _, item := range items {
+		phase := item.Status.Phase
+		if phase != backupv1alpha1.BackupPhaseCompleted && phase != backupv1alpha1.BackupPhaseFailed {
+			remaining = append(remaining, item)
+			continue
+		}
+
+		// items are sorted newest first
+		key := backupKey(item.Namespace, item.Spec.PVCName) + "/" + phase
+		seen[key]++
+		exceedMaxBackups := retention.MaxBackups > 0 && seen[key] > retention.MaxBackups
+		exceedMaxAge := !cutoff.IsZero() && backupTime(&item).Before(cutoff)
+		if !exceedMaxBackups && !exceedMaxAge {
+			remaining = append(remaining, item)
+			continue
+		}
+
+		// The strategy already removed the artifact through its own retention, so only the record goes
+		if err := r.removeBackupFinalizer(ctx, &item); err != nil {
+			logger.Error(err, "Failed to remove finalizer from expired Backup", "backup", item.Name, "namespace", item.Namespace)
+			remaining = append(remaining, item)
+			continue
+		}
+		if err := r.Delete(ctx, &item); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete expired Backup", "backup", item.Name, "namespace", item.Namespace)
+			remaining = append(remaining, item)
+			continue
+		}
+		logger.Info("Deleted expired Backup", "backup", item.Name, "namespace", item.Namespace)
+		expired[types.NamespacedName{Namespace: item.Namespace, Name: item.Spec.PVCName}]++
+		// restic forget removed the snapshot of a completed backup in its Job; VolumeSnapshots deleted by the
+		// snapshot strategy's cleanup are counted there
+		if phase == backupv1alpha1.BackupPhaseCompleted && item.Spec.Strategy != "snapshot" {
+			deleted[item.Spec.Strategy]++
+		}
+	}
+	for strategy, count := range deleted {
+		metrics.RecordRetentionDeletions(policy.Namespace, policy.Name, strategy, count)
+	}
+	for pvc, count := range expired {
+		backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonRetentionDeleted,
+			fmt.Sprintf("Deleted %d expired Backup(s) of PVC %s", count, pvc), policy, r.eventPVC(ctx, pvc.Namespace, pvc.Name))
+	}
+	return remaining
+}
+
+// removeBackupFinalizer drops the artifact finalizer so deleting the Backup leaves backup storage untouched
+func (r *BackupPolicyReconciler) removeBackupFinalizer(ctx context.Context, item *backupv1alpha1.Backup) error {
+	if !controllerutil.RemoveFinalizer(item, backup.BackupFinalizer) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Update(ctx, item))
+}
+
+// summarizeBackups updates the Backup and replica counts in the policy status. The last successful backup
+// metric is reconciled from the records too, so it is known again after the operator restarts. targets are
+// the PVCs the policy selects; the per-PVC series of other PVCs are deleted. A nil targets keeps them, e.g.
+// while Job results are recorded before the PVCs are listed.
+func summarizeBackups(policy *backupv1alpha1.BackupPolicy, items []backupv1alpha1.Backup, targets map[types.NamespacedName]bool) {
+	completed, failed := 0, 0
+	lastSuccess := make(map[types.NamespacedName]time.Time)
+	for _, item := range items {
+		switch item.Status.Phase {
+		case backupv1alpha1.BackupPhaseCompleted:
+			completed++
+			key := types.NamespacedName{Namespace: item.Namespace, Name: item.Spec.PVCName}
+			if item.Status.CompletionTime != nil && item.Status.CompletionTime.After(lastSuccess[key]) {
+				lastSuccess[key] = item.Status.CompletionTime.Time
+			}
+		case backupv1alpha1.BackupPhaseFailed:
+			failed++
+		}
+	}
+	policy.Status.BackupCount = len(items)
+	policy.Status.CompletedBackups = completed
+	policy.Status.FailedBackups = failed
+	policy.Status.Replicas = summarizeReplicas(policy.Spec.Replicas, items)
+	for pvc, completed := range lastSuccess {
+		if targets == nil || targets[pvc] {
+			metrics.SetLastSuccessfulBackup(policy.Namespace, policy.Name, pvc.Namespace, pvc.Name, completed)
+		}
+	}
+	if targets != nil {
+		metrics.DeleteUntargetedPVCs(policy.Namespace, policy.Name, func(namespace, pvc string) bool {
+			return targets[types.NamespacedName{Namespace: namespace, Name: pvc}]
+		})
+	}
+}
+
+// summarizeTargets summarizes the policy's Backups once its target PVCs are known
+func (r *BackupPolicyReconciler) summarizeTargets(ctx context.Context, policy *backupv1alpha1.BackupPolicy, pvcs []corev1.PersistentVolumeClaim) {
+	items, err := r.listBackups(ctx, policy)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list Backups")
+		return
+	}
+	summarizeBackups(policy, items, targetSet(pvcs))
+}
+
+// targetSet returns the keys of the target PVCs of a policy for summarizeBackups
+func targetSet(pvcs []corev1.PersistentVolumeClaim) map[types.NamespacedName]bool {
+	targets := make(map[types.NamespacedName]bool, len(pvcs))
+	for i := range pvcs {
+		targets[client.ObjectKeyFromObject(&pvcs[i])] = true
+	}
+	return targets
+}
+
+// backupTime returns when a backup was taken, falling back to the creation time of the resource
+func backupTime(item *backupv1alpha1.Backup) time.Time {
+	if item.Status.StartTime != nil {
+		return item.Status.StartTime.Time
+	}
+	return item.CreationTimestamp.Time
+}
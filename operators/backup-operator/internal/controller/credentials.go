@@ -0,0 +1,199 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// reconcileCredentialCopies keeps the Secrets copied out of the policy namespace in sync with their source
+// and deletes copies that no policy of that namespace needs anymore. pvcs are the current targets of the
+// policy; a policy being deleted needs no copies.
+func (r *BackupPolicyReconciler) reconcileCredentialCopies(ctx context.Context, policy *backupv1alpha1.BackupPolicy, pvcs []corev1.PersistentVolumeClaim) error {
+	logger := log.FromContext(ctx)
+
+	deleting := !policy.DeletionTimestamp.IsZero()
+	// Only the external strategy copies Secrets; leftovers of an earlier external configuration go with the policy
+	if !deleting && policy.Spec.Strategy != "external" {
+		return nil
+	}
+
+	policies := &backupv1alpha1.BackupPolicyList{}
+	if err := r.List(ctx, policies, client.InNamespace(policy.Namespace)); err != nil {
+		return fmt.Errorf("failed to list BackupPolicies: %w", err)
+	}
+
+	targeted := make(map[string]bool)
+	for _, pvc := range pvcs {
+		targeted[pvc.Namespace] = true
+	}
+	// Copies can be left wherever the policy selects PVCs, keeps Backups or ran Jobs
+	namespaces := sets.New(indexPolicyByNamespace(policy)...)
+	items, err := r.listBackups(ctx, policy)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		namespaces.Insert(items[i].Namespace)
+	}
+	// Jobs that are still running read the copies, e.g. forget Jobs of Backups in namespaces no longer targeted
+	jobs, err := r.listPolicyJobs(ctx, policy)
+	if err != nil {
+		return err
+	}
+	// Restores of imported backups read copies of the BackupRepository's Secrets in namespaces no policy targets
+	restores := &batchv1.JobList{}
+	if err := r.List(ctx, restores, client.MatchingLabels{backup.LabelPolicyNamespace: policy.Namespace},
+		client.HasLabels{backup.LabelRestoredBackup}); err != nil {
+		return fmt.Errorf("failed to list restore Jobs: %w", err)
+	}
+	for _, job := range append(jobs, restores.Items...) {
+		namespaces.Insert(job.Namespace)
+		if isJobActive(&job) {
+			targeted[job.Namespace] = true
+		}
+	}
+	namespaces.Insert(slices.Collect(maps.Keys(targeted))...)
+	namespaces.Delete(policy.Namespace)
+
+	// Secrets are not cached, so a policy being deleted searches all namespaces once and the others only
+	// the namespaces they can have copied to
+	selector := client.MatchingLabels{backup.LabelManaged: "true", backup.LabelSecretSourceNamespace: policy.Namespace}
+	copies := &corev1.SecretList{}
+	if deleting {
+		if err := r.List(ctx, copies, selector); err != nil {
+			return fmt.Errorf("failed to list copied Secrets: %w", err)
+		}
+	} else {
+		for _, namespace := range sets.List(namespaces) {
+			found := &corev1.SecretList{}
+			if err := r.List(ctx, found, client.InNamespace(namespace), selector); err != nil {
+				return fmt.Errorf("failed to list copied Secrets in namespace %s: %w", namespace, err)
+			}
+			copies.Items = append(copies.Items, found.Items...)
+		}
+	}
+
+	for i := range copies.Items {
+		secret := &copies.Items[i]
+
+		source := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: policy.Namespace, Name: secret.Name}, source)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to read secret %s/%s: %w", policy.Namespace, secret.Name, err)
+		}
+
+		// A copy without a source would keep serving credentials that were revoked
+		if errors.IsNotFound(err) || !credentialCopyInUse(secret, policy, policies.Items, targeted) {
+			if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete copied Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+			}
+			logger.Info("Deleted copied Secret", "secret", secret.Name, "namespace", secret.Namespace)
+			backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonCredentialsDeleted,
+				fmt.Sprintf("Deleted Secret %s from namespace %s", secret.Name, secret.Namespace), policy)
+			continue
+		}
+
+		if !backup.SyncSecretCopy(secret, source) {
+			continue
+		}
+		if err := r.Update(ctx, secret); err != nil {
+			return fmt.Errorf("failed to sync copied Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		logger.Info("Synced copied Secret", "secret", secret.Name, "namespace", secret.Namespace)
+		backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonCredentialsSynced,
+			fmt.Sprintf("Updated Secret %s in namespace %s from its source", secret.Name, secret.Namespace), policy)
+	}
+	return nil
+}
+
+// credentialCopyInUse reports whether any policy of the source namespace still needs the copied Secret.
+// Other policies are only known by the namespaces they select PVCs in.
+func credentialCopyInUse(secret *corev1.Secret, policy *backupv1alpha1.BackupPolicy, policies []backupv1alpha1.BackupPolicy, targeted map[string]bool) bool {
+	for i := range policies {
+		p := &policies[i]
+		if !p.DeletionTimestamp.IsZero() || p.Spec.Strategy != "external" {
+			continue
+		}
+		if !slices.Contains(credentialSecretNames(p), secret.Name) {
+			continue
+		}
+		if p.UID == policy.UID {
+			if targeted[secret.Namespace] {
+				return true
+			}
+			continue
+		}
+		if slices.Contains(indexPolicyByNamespace(p), secret.Namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialSecretNames returns the Secrets a policy copies into PVC namespaces
+func credentialSecretNames(policy *backupv1alpha1.BackupPolicy) []string {
+	names := []string{policy.Spec.Destination.CredentialsSecret, backup.ResticPasswordSecret(policy)}
+	if policy.Spec.Dump != nil {
+		names = append(names, policy.Spec.Dump.CredentialsSecret)
+	}
+	for i := range policy.Spec.Replicas {
+		replica := backup.ReplicaPolicy(policy, &policy.Spec.Replicas[i])
+		names = append(names, replica.Spec.Destination.CredentialsSecret, backup.ResticPasswordSecret(replica))
+	}
+	if rotation := policy.Status.KeyRotation; rotation != nil && !isKeyRotationFinished(rotation) {
+		names = append(names, rotation.OldSecret, rotation.NewSecret)
+	}
+	return names
+}
+
+// policiesForSecret maps a source Secret, or a copy of it, to the policies of the source namespace using it.
+// The Secret watch only caches metadata, so obj carries no data.
+func (r *BackupPolicyReconciler) policiesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	namespace := obj.GetNamespace()
+	if labels := obj.GetLabels(); labels[backup.LabelManaged] == "true" && labels[backup.LabelSecretSourceNamespace] != "" {
+		namespace = labels[backup.LabelSecretSourceNamespace]
+	}
+
+	policies := &backupv1alpha1.BackupPolicyList{}
+	if err := r.List(ctx, policies, client.InNamespace(namespace), client.MatchingFields{policySecretIndex: obj.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list BackupPolicies for Secret", "secret", obj.GetName(), "namespace", namespace)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(policies.Items))
+	for i := range policies.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&policies.Items[i])})
+	}
+	return requests
+}
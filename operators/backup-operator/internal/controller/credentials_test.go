@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func newCredentialsPolicy(name, uid string, namespaces ...string) *backupv1alpha1.BackupPolicy {
+	return &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ops", UID: types.UID("uid-" + uid)},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Strategy:   "external",
+			Namespaces: namespaces,
+			Destination: backupv1alpha1.Destination{
+				Type:              "s3",
+				CredentialsSecret: "s3-credentials",
+			},
+		},
+	}
+}
+
+func newCredentialsReconciler(t *testing.T, objects ...client.Object) *BackupPolicyReconciler {
+	t.Helper()
+	scheme := newTestScheme(t)
+	fakeClient := newFakeClientBuilder(scheme).WithObjects(objects...).Build()
+	return &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+}
+
+func credentialCopy(policy *backupv1alpha1.BackupPolicy, namespace, key string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-credentials", Namespace: namespace, Labels: backup.ManagedSecretLabels(policy)},
+		Data:       map[string][]byte{"access-key": []byte(key)},
+	}
+}
+
+func TestReconcileCredentialCopiesSyncsAndRemovesCopies(t *testing.T) {
+	policy := newCredentialsPolicy("policy", "1", "apps", "old")
+	source := credentialCopy(policy, "ops", "rotated")
+	source.Labels = nil
+	r := newCredentialsReconciler(t, policy, source,
+		credentialCopy(policy, "apps", "stale"),
+		credentialCopy(policy, "old", "stale"))
+	ctx := context.Background()
+
+	pvcs := []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "apps"}}}
+	if err := r.reconcileCredentialCopies(ctx, policy, pvcs); err != nil {
+		t.Fatalf("reconcileCredentialCopies returned error: %v", err)
+	}
+
+	synced := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "apps", Name: "s3-credentials"}, synced); err != nil {
+		t.Fatalf("failed to get copy in targeted namespace: %v", err)
+	}
+	if got := string(synced.Data["access-key"]); got != "rotated" {
+		t.Errorf("expected copy to follow the source, got access-key %q", got)
+	}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "old", Name: "s3-credentials"}, &corev1.Secret{}); err == nil {
+		t.Errorf("expected copy in a namespace without target PVCs to be deleted")
+	}
+}
+
+func TestReconcileCredentialCopiesKeepsCopiesSharedWithOtherPolicies(t *testing.T) {
+	now := metav1.Now()
+	deleting := newCredentialsPolicy("deleting", "1", "apps")
+	deleting.Finalizers = []string{backup.PolicyFinalizer}
+	deleting.DeletionTimestamp = &now
+	other := newCredentialsPolicy("other", "2", "apps")
+	source := credentialCopy(deleting, "ops", "key")
+	source.Labels = nil
+	r := newCredentialsReconciler(t, deleting, other, source, credentialCopy(deleting, "apps", "key"))
+	ctx := context.Background()
+
+	if err := r.reconcileCredentialCopies(ctx, deleting, nil); err != nil {
+		t.Fatalf("reconcileCredentialCopies returned error: %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "apps", Name: "s3-credentials"}, &corev1.Secret{}); err != nil {
+		t.Errorf("expected copy still used by another policy to be kept: %v", err)
+	}
+}
+
+func TestReconcileCredentialCopiesIgnoresPoliciesOfTheSourceNamespace(t *testing.T) {
+	policy := newCredentialsPolicy("policy", "1")
+	// Without namespaces the other policy only backs up PVCs in its own namespace, which has the source
+	local := newCredentialsPolicy("local", "2")
+	source := credentialCopy(policy, "ops", "key")
+	source.Labels = nil
+	// The policy backed up PVCs in apps before its namespaces changed
+	previous := newBackupRecord("policy-data-1", "data", backupv1alpha1.BackupPhaseCompleted, time.Now())
+	previous.Namespace = "apps"
+	previous.Labels[backup.LabelPolicyNamespace] = "ops"
+	r := newCredentialsReconciler(t, policy, local, source, previous, credentialCopy(policy, "apps", "key"))
+	ctx := context.Background()
+
+	if err := r.reconcileCredentialCopies(ctx, policy, nil); err != nil {
+		t.Fatalf("reconcileCredentialCopies returned error: %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "apps", Name: "s3-credentials"}, &corev1.Secret{}); err == nil {
+		t.Errorf("expected copy no policy targets to be deleted")
+	}
+}
+
+func TestReconcileCredentialCopiesSkipsPoliciesWithoutCopies(t *testing.T) {
+	policy := newCredentialsPolicy("policy", "1", "apps")
+	policy.Spec.Strategy = "snapshot"
+	// Without a source Secret an external policy would delete the copy
+	r := newCredentialsReconciler(t, policy, credentialCopy(policy, "apps", "key"))
+	ctx := context.Background()
+
+	if err := r.reconcileCredentialCopies(ctx, policy, nil); err != nil {
+		t.Fatalf("reconcileCredentialCopies returned error: %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: "apps", Name: "s3-credentials"}, &corev1.Secret{}); err != nil {
+		t.Errorf("expected a snapshot policy to leave copies alone: %v", err)
+	}
+}
+
+func TestPoliciesForSecretMapsMetadataToUsingPolicies(t *testing.T) {
+	policy := newCredentialsPolicy("policy", "1", "apps")
+	unrelated := newCredentialsPolicy("unrelated", "2", "apps")
+	unrelated.Spec.Destination.CredentialsSecret = "other-credentials"
+	r := newCredentialsReconciler(t, policy, unrelated)
+	ctx := context.Background()
+
+	copied := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Name: "s3-credentials", Namespace: "apps", Labels: backup.ManagedSecretLabels(policy),
+	}}
+	requests := r.policiesForSecret(ctx, copied)
+	if len(requests) != 1 || requests[0].Namespace != "ops" || requests[0].Name != "policy" {
+		t.Fatalf("expected the copy to map to ops/policy, got %v", requests)
+	}
+
+	unused := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "ops"}}
+	if requests := r.policiesForSecret(ctx, unused); len(requests) != 0 {
+		t.Fatalf("expected Secrets no policy uses to be ignored, got %v", requests)
+	}
+}
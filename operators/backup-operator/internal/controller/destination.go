@@ -0,0 +1,107 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+const (
+	// Interval between destination checks while the destination is reachable
+	destinationCheckInterval = 30 * time.Minute
+	// Upper bound for a single destination check so an unresponsive endpoint cannot stall the reconcile
+	destinationCheckTimeout = 30 * time.Second
+	// Shortest delay between checks of an unreachable destination
+	destinationRetryBackoff = 30 * time.Second
+)
+
+// checkDestination sets the DestinationReachable condition. Strategies storing backups in the cluster do not
+// report it. strategyErr is the error from building the strategy, e.g. a destination URL without a bucket.
+func (r *BackupPolicyReconciler) checkDestination(ctx context.Context, policy *backupv1alpha1.BackupPolicy, strategy backup.Strategy, strategyErr error) {
+	if strategyErr != nil {
+		r.setDestinationUnreachable(policy, "InvalidDestination", strategyErr.Error())
+		return
+	}
+	// NFS shares are only mounted by backup Jobs, so the operator cannot probe them
+	checker, ok := strategy.(backup.DestinationChecker)
+	if !ok || policy.Spec.Destination.Type == "nfs" {
+		meta.RemoveStatusCondition(&policy.Status.Conditions, backupv1alpha1.ConditionDestinationReachable)
+		policy.Status.LastDestinationCheckTime = nil
+		return
+	}
+	if !destinationCheckDue(policy, time.Now()) {
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, destinationCheckTimeout)
+	defer cancel()
+	err := checker.CheckDestination(checkCtx, policy)
+	policy.Status.LastDestinationCheckTime = &metav1.Time{Time: time.Now()}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Destination check failed", "url", policy.Spec.Destination.URL)
+		r.setDestinationUnreachable(policy, "Unreachable", err.Error())
+		return
+	}
+	setCondition(policy, backupv1alpha1.ConditionDestinationReachable, metav1.ConditionTrue, "Reachable",
+		fmt.Sprintf("Destination %s is reachable", policy.Spec.Destination.URL))
+}
+
+// setDestinationUnreachable sets the DestinationReachable condition to False, with an event when it was not False already
+func (r *BackupPolicyReconciler) setDestinationUnreachable(policy *backupv1alpha1.BackupPolicy, reason, message string) {
+	if !meta.IsStatusConditionFalse(policy.Status.Conditions, backupv1alpha1.ConditionDestinationReachable) {
+		backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonDestinationUnreachable,
+			fmt.Sprintf("Destination %s cannot be used: %s", policy.Spec.Destination.URL, message), policy)
+	}
+	setCondition(policy, backupv1alpha1.ConditionDestinationReachable, metav1.ConditionFalse, reason, message)
+}
+
+// destinationCheckDue reports whether the destination should be checked: after spec changes, and otherwise
+// once nextDestinationCheck has passed
+func destinationCheckDue(policy *backupv1alpha1.BackupPolicy, now time.Time) bool {
+	condition := meta.FindStatusCondition(policy.Status.Conditions, backupv1alpha1.ConditionDestinationReachable)
+	if condition == nil || condition.ObservedGeneration != policy.Generation {
+		return true
+	}
+	next, ok := nextDestinationCheck(policy)
+	return !ok || !now.Before(next)
+}
+
+// nextDestinationCheck returns when the destination is checked again: periodically while it is reachable,
+// and while it is not after waiting as long as it has been unreachable, from destinationRetryBackoff up to
+// the periodic interval. False when no check was recorded yet.
+func nextDestinationCheck(policy *backupv1alpha1.BackupPolicy) (time.Time, bool) {
+	condition := meta.FindStatusCondition(policy.Status.Conditions, backupv1alpha1.ConditionDestinationReachable)
+	checked := policy.Status.LastDestinationCheckTime
+	if condition == nil || checked == nil {
+		return time.Time{}, false
+	}
+	if condition.Status == metav1.ConditionTrue {
+		return checked.Add(destinationCheckInterval), true
+	}
+	unreachable := checked.Sub(condition.LastTransitionTime.Time)
+	return checked.Add(min(max(unreachable, destinationRetryBackoff), destinationCheckInterval)), true
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// checkingStrategy counts destination checks and fails them with err
+type checkingStrategy struct {
+	backup.Strategy
+	checks int
+	err    error
+}
+
+func (s *checkingStrategy) CheckDestination(context.Context, *backupv1alpha1.BackupPolicy) error {
+	s.checks++
+	return s.err
+}
+
+func TestNextDestinationCheckBacksOff(t *testing.T) {
+	failedAt := time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC)
+	policy := &backupv1alpha1.BackupPolicy{}
+	policy.Status.Conditions = []metav1.Condition{{
+		Type:               backupv1alpha1.ConditionDestinationReachable,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.Time{Time: failedAt},
+	}}
+
+	for _, tt := range []struct {
+		unreachable time.Duration
+		wait        time.Duration
+	}{
+		{0, destinationRetryBackoff},
+		{5 * time.Minute, 5 * time.Minute},
+		{3 * time.Hour, destinationCheckInterval},
+	} {
+		checked := failedAt.Add(tt.unreachable)
+		policy.Status.LastDestinationCheckTime = &metav1.Time{Time: checked}
+		if next, ok := nextDestinationCheck(policy); !ok || next.Sub(checked) != tt.wait {
+			t.Errorf("unreachable for %s: expected the next check after %s, got %s", tt.unreachable, tt.wait, next.Sub(checked))
+		}
+	}
+}
+
+func TestCheckDestinationReportsConcreteError(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &BackupPolicyReconciler{Recorder: recorder}
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns", Generation: 1},
+		Spec:       backupv1alpha1.BackupPolicySpec{Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://backups/cluster"}},
+	}
+	strategy := &checkingStrategy{err: errors.New("read probe failed: AccessDenied")}
+	ctx := context.Background()
+
+	r.checkDestination(ctx, policy, strategy, nil)
+	condition := meta.FindStatusCondition(policy.Status.Conditions, backupv1alpha1.ConditionDestinationReachable)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Message != strategy.err.Error() {
+		t.Fatalf("expected the probe error in the condition, got %+v", condition)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected one warning event, got %d", len(recorder.Events))
+	}
+
+	// Unreachable destinations are checked again after a backoff, without repeating the event
+	r.checkDestination(ctx, policy, strategy, nil)
+	if strategy.checks != 1 {
+		t.Fatalf("expected no check during the backoff, got %d checks", strategy.checks)
+	}
+	policy.Status.LastDestinationCheckTime = &metav1.Time{Time: time.Now().Add(-destinationRetryBackoff)}
+	r.checkDestination(ctx, policy, strategy, nil)
+	if strategy.checks != 2 || len(recorder.Events) != 1 {
+		t.Fatalf("expected a second check without a new event, got %d checks and %d events", strategy.checks, len(recorder.Events))
+	}
+	policy.Status.LastDestinationCheckTime = &metav1.Time{Time: time.Now().Add(-destinationRetryBackoff)}
+
+	strategy.err = nil
+	r.checkDestination(ctx, policy, strategy, nil)
+	if !meta.IsStatusConditionTrue(policy.Status.Conditions, backupv1alpha1.ConditionDestinationReachable) {
+		t.Fatalf("expected the destination to be reachable, got %v", policy.Status.Conditions)
+	}
+
+	// Reachable destinations are only checked periodically or after spec changes
+	r.checkDestination(ctx, policy, strategy, nil)
+	if strategy.checks != 3 {
+		t.Fatalf("expected no check before the interval, got %d checks", strategy.checks)
+	}
+	policy.Status.LastDestinationCheckTime = &metav1.Time{Time: time.Now().Add(-destinationCheckInterval)}
+	r.checkDestination(ctx, policy, strategy, nil)
+	policy.Generation = 2
+	r.checkDestination(ctx, policy, strategy, nil)
+	if strategy.checks != 5 {
+		t.Fatalf("expected checks after the interval and the spec change, got %d checks", strategy.checks)
+	}
+
+	r.checkDestination(ctx, policy, nil, errors.New("destination URL must include bucket name for S3 backend"))
+	condition = meta.FindStatusCondition(policy.Status.Conditions, backupv1alpha1.ConditionDestinationReachable)
+	if condition.Status != metav1.ConditionFalse || condition.Reason != "InvalidDestination" {
+		t.Fatalf("expected an invalid destination, got %+v", condition)
+	}
+
+	r.checkDestination(ctx, policy, backup.NewSnapshotStrategy(nil, nil), nil)
+	if meta.FindStatusCondition(policy.Status.Conditions, backupv1alpha1.ConditionDestinationReachable) != nil {
+		t.Fatalf("expected no destination condition for snapshots, got %v", policy.Status.Conditions)
+	}
+}
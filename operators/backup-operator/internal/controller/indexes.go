@@ -0,0 +1,154 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+const (
+	// policyLabelIndex indexes Jobs and Backups by the "<namespace>/<name>" of the policy that created them
+	policyLabelIndex = ".metadata.labels.policy"
+	// policyNamespaceIndex indexes BackupPolicies by the namespaces they select PVCs in
+	policyNamespaceIndex = ".spec.namespaces"
+	// policySecretIndex indexes BackupPolicies by the Secrets they copy into PVC namespaces
+	policySecretIndex = ".spec.secrets"
+	// pvcTemplateIndex indexes PVCs by the BackupPolicyTemplate they are annotated or labelled with
+	pvcTemplateIndex = ".metadata.annotations.policyTemplate"
+)
+
+// setupIndexes registers the field indexes used to look up the objects of a policy in the cache
+func setupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &batchv1.Job{}, policyLabelIndex, indexByPolicyLabels); err != nil {
+		return fmt.Errorf("failed to index Jobs by policy: %w", err)
+	}
+	if err := indexer.IndexField(ctx, &backupv1alpha1.Backup{}, policyLabelIndex, indexByPolicyLabels); err != nil {
+		return fmt.Errorf("failed to index Backups by policy: %w", err)
+	}
+	if err := indexer.IndexField(ctx, &backupv1alpha1.BackupPolicy{}, policyNamespaceIndex, indexPolicyByNamespace); err != nil {
+		return fmt.Errorf("failed to index BackupPolicies by target namespace: %w", err)
+	}
+	if err := indexer.IndexField(ctx, &backupv1alpha1.BackupPolicy{}, policySecretIndex, indexPolicyBySecret); err != nil {
+		return fmt.Errorf("failed to index BackupPolicies by Secret: %w", err)
+	}
+	return nil
+}
+
+// indexByPolicyLabels returns the policy key of objects labelled with their policy
+func indexByPolicyLabels(obj client.Object) []string {
+	name, namespace := obj.GetLabels()[backup.LabelPolicy], obj.GetLabels()[backup.LabelPolicyNamespace]
+	if name == "" || namespace == "" {
+		return nil
+	}
+	return []string{backupKey(namespace, name)}
+}
+
+// indexPolicyByNamespace returns the namespaces a policy selects PVCs in, its own when none are listed
+func indexPolicyByNamespace(obj client.Object) []string {
+	policy, ok := obj.(*backupv1alpha1.BackupPolicy)
+	if !ok {
+		return nil
+	}
+	if len(policy.Spec.Namespaces) == 0 {
+		return []string{policy.Namespace}
+	}
+	return policy.Spec.Namespaces
+}
+
+// indexPolicyBySecret returns the Secrets a policy copies into PVC namespaces
+func indexPolicyBySecret(obj client.Object) []string {
+	policy, ok := obj.(*backupv1alpha1.BackupPolicy)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, name := range credentialSecretNames(policy) {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// indexPVCByTemplate returns the templates a PVC opts into or is still labelled with
+func indexPVCByTemplate(obj client.Object) []string {
+	annotated, labelled := obj.GetAnnotations()[backup.AnnotationPolicyTemplate], obj.GetLabels()[backup.LabelPolicyTemplate]
+	switch {
+	case annotated == "" && labelled == "":
+		return nil
+	case annotated == "" || annotated == labelled:
+		return []string{labelled}
+	case labelled == "":
+		return []string{annotated}
+	}
+	return []string{annotated, labelled}
+}
+
+// policyIndexKey returns the key of a policy in the policy label index
+func policyIndexKey(policy *backupv1alpha1.BackupPolicy) string {
+	return backupKey(policy.Namespace, policy.Name)
+}
+
+// listPolicyJobs returns the Jobs created by the policy in all namespaces
+func (r *BackupPolicyReconciler) listPolicyJobs(ctx context.Context, policy *backupv1alpha1.BackupPolicy) ([]batchv1.Job, error) {
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, client.MatchingFields{policyLabelIndex: policyIndexKey(policy)}); err != nil {
+		return nil, fmt.Errorf("failed to list Jobs: %w", err)
+	}
+	return jobList.Items, nil
+}
+
+// policyForJob maps a Job to the policy that created it; owner references cannot cross namespaces
+func policyForJob(_ context.Context, obj client.Object) []reconcile.Request {
+	name, namespace := obj.GetLabels()[backup.LabelPolicy], obj.GetLabels()[backup.LabelPolicyNamespace]
+	if name == "" || namespace == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: namespace, Name: name}}}
+}
+
+// policiesForPVC maps a PVC to the policies whose selector matches it, so new volumes are backed up
+// without waiting for the next scheduled run
+func (r *BackupPolicyReconciler) policiesForPVC(ctx context.Context, obj client.Object) []reconcile.Request {
+	policies := &backupv1alpha1.BackupPolicyList{}
+	if err := r.List(ctx, policies, client.MatchingFields{policyNamespaceIndex: obj.GetNamespace()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list BackupPolicies for PVC", "pvc", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if !labels.SelectorFromSet(policy.Spec.Selector.MatchLabels).Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)})
+	}
+	return requests
+}
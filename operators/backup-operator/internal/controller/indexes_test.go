@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// newTestScheme returns a scheme with the core, batch and backup API kinds the controllers work with
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, batchv1.AddToScheme, backupv1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+	return scheme
+}
+
+// newFakeClientBuilder returns a fake client builder with the controller's field indexes for the kinds in scheme
+func newFakeClientBuilder(scheme *runtime.Scheme) *fake.ClientBuilder {
+	b := fake.NewClientBuilder().WithScheme(scheme)
+	if scheme.Recognizes(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim")) {
+		b = b.WithIndex(&corev1.PersistentVolumeClaim{}, pvcTemplateIndex, indexPVCByTemplate)
+	}
+	if scheme.Recognizes(batchv1.SchemeGroupVersion.WithKind("Job")) {
+		b = b.WithIndex(&batchv1.Job{}, policyLabelIndex, indexByPolicyLabels)
+	}
+	if scheme.Recognizes(backupv1alpha1.GroupVersion.WithKind("Backup")) {
+		b = b.
+			WithIndex(&backupv1alpha1.Backup{}, policyLabelIndex, indexByPolicyLabels).
+			WithIndex(&backupv1alpha1.BackupPolicy{}, policyNamespaceIndex, indexPolicyByNamespace).
+			WithIndex(&backupv1alpha1.BackupPolicy{}, policySecretIndex, indexPolicyBySecret)
+	}
+	return b
+}
+
+func TestPolicyForJobUsesPolicyLabels(t *testing.T) {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "policy-data-1", Namespace: "apps", Labels: map[string]string{
+		backup.LabelPolicy:          "policy",
+		backup.LabelPolicyNamespace: "ns",
+	}}}
+	requests := policyForJob(context.Background(), job)
+	if len(requests) != 1 || requests[0].Namespace != "ns" || requests[0].Name != "policy" {
+		t.Fatalf("expected the Job to map to ns/policy, got %v", requests)
+	}
+
+	if requests := policyForJob(context.Background(), &batchv1.Job{}); len(requests) != 0 {
+		t.Fatalf("expected unlabelled Jobs to be ignored, got %v", requests)
+	}
+}
+
+func TestPoliciesForPVCMatchesNamespaceAndSelector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := backupv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{"backup": "true"}}
+	fakeClient := newFakeClientBuilder(scheme).WithObjects(
+		&backupv1alpha1.BackupPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "apps"},
+			Spec:       backupv1alpha1.BackupPolicySpec{Selector: selector},
+		},
+		&backupv1alpha1.BackupPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "central", Namespace: "backup"},
+			Spec:       backupv1alpha1.BackupPolicySpec{Namespaces: []string{"apps", "db"}},
+		},
+		&backupv1alpha1.BackupPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "backup"},
+			Spec:       backupv1alpha1.BackupPolicySpec{Namespaces: []string{"db"}},
+		},
+		&backupv1alpha1.BackupPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "selective", Namespace: "apps"},
+			Spec:       backupv1alpha1.BackupPolicySpec{Selector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "db"}}},
+		},
+	).Build()
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "apps", Labels: map[string]string{"backup": "true"}}}
+	var got []string
+	for _, request := range r.policiesForPVC(context.Background(), pvc) {
+		got = append(got, request.String())
+	}
+	if len(got) != 2 || got[0] != "apps/local" || got[1] != "backup/central" {
+		t.Fatalf("expected apps/local and backup/central, got %v", got)
+	}
+}
+
+func TestListPolicyJobsFindsJobsInAllNamespaces(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	labels := map[string]string{backup.LabelPolicy: "policy", backup.LabelPolicyNamespace: "ns"}
+	otherLabels := map[string]string{backup.LabelPolicy: "policy", backup.LabelPolicyNamespace: "other"}
+	fakeClient := newFakeClientBuilder(scheme).WithObjects(
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns", Labels: labels}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "apps", Labels: labels}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "apps", Labels: otherLabels}},
+	).Build()
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+
+	jobs, err := r.listPolicyJobs(context.Background(), &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"}})
+	if err != nil {
+		t.Fatalf("listPolicyJobs returned error: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("expected the policy's Jobs in both namespaces, got %d", len(jobs))
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// Key rotation phases recorded in status.keyRotation.phase
+const (
+	keyRotationAddingKey      = "AddingKey"
+	keyRotationAwaitingSwitch = "AwaitingSecretSwitch"
+	keyRotationRemovingKey    = "RemovingKey"
+	keyRotationCompleted      = "Completed"
+	keyRotationFailed         = "Failed"
+)
+
+// reconcileKeyRotation drives an encryption key rotation requested via the rotate-encryption-key annotation.
+// The new key is added to every repository, including replicas sharing the old password, and the rotation
+// then waits for the user to point the spec at the new Secret. The controller never edits the spec, so
+// GitOps tools do not revert the switch. Only then is the old key removed, so a failure never leaves a
+// repository without a usable password.
+func (r *BackupPolicyReconciler) reconcileKeyRotation(ctx context.Context, policy *backupv1alpha1.BackupPolicy, strategy backup.Strategy, pvcs []corev1.PersistentVolumeClaim) error {
+	logger := log.FromContext(ctx)
+
+	newSecret := policy.Annotations[backup.AnnotationRotateEncryptionKey]
+	if newSecret == "" {
+		return nil
+	}
+
+	rotator, ok := strategy.(backup.KeyRotator)
+	if !ok {
+		return fmt.Errorf("backup strategy %q does not support encryption key rotation", policy.Spec.Strategy)
+	}
+
+	rotation := policy.Status.KeyRotation
+	if rotation == nil || rotation.NewSecret != newSecret || isKeyRotationFinished(rotation) {
+		oldSecret := backup.ResticPasswordSecret(policy)
+		if newSecret == oldSecret {
+			logger.Info("Encryption key already uses the requested Secret", "secret", newSecret)
+			return r.clearKeyRotationRequest(ctx, policy)
+		}
+
+		// restic key changes need the repository lock, so wait for running Jobs to finish
+		active, err := r.hasActivePolicyJobs(ctx, policy)
+		if err != nil {
+			return err
+		}
+		if active {
+			logger.Info("Jobs of the policy are still running, postponing key rotation")
+			return nil
+		}
+
+		logger.Info("Starting encryption key rotation", "oldSecret", oldSecret, "newSecret", newSecret)
+		now := metav1.Now()
+		policy.Status.KeyRotation = &backupv1alpha1.KeyRotationStatus{
+			NewSecret: newSecret,
+			OldSecret: oldSecret,
+			Phase:     keyRotationAddingKey,
+			StartTime: &now,
+		}
+		for i := range policy.Spec.Replicas {
+			replica := &policy.Spec.Replicas[i]
+			if backup.ResticPasswordSecret(backup.ReplicaPolicy(policy, replica)) == oldSecret {
+				policy.Status.KeyRotation.Replicas = append(policy.Status.KeyRotation.Replicas, replica.Name)
+			}
+		}
+		return r.startKeyRotationPhase(ctx, policy, rotator, pvcs, backup.KeyRotationAddKey)
+	}
+
+	done, failedJob, err := r.keyRotationJobsFinished(ctx, rotation.Jobs)
+	if err != nil {
+		return err
+	}
+
+	if failedJob != "" {
+		message := fmt.Sprintf("failed to add the new key in Job %s; repositories still use Secret %s", failedJob, rotation.OldSecret)
+		if rotation.Phase == keyRotationRemovingKey {
+			message = fmt.Sprintf("failed to remove the old key in Job %s; Secret %s is active but the old password still opens some repositories", failedJob, rotation.NewSecret)
+		}
+		return r.failKeyRotation(ctx, policy, message)
+	}
+	if !done {
+		return nil
+	}
+
+	switch rotation.Phase {
+	case keyRotationAddingKey:
+		// Every repository now accepts both passwords, backups keep working whichever Secret the spec names
+		logger.Info("New encryption key added to all repositories", "secret", rotation.NewSecret)
+		rotation.Phase = keyRotationAwaitingSwitch
+		rotation.Jobs = nil
+		fallthrough
+
+	case keyRotationAwaitingSwitch:
+		if pending := pendingSecretSwitches(policy, rotation); len(pending) > 0 {
+			rotation.Message = fmt.Sprintf("set the encryption secret of %s to %s to remove the old key", strings.Join(pending, ", "), rotation.NewSecret)
+			return nil
+		}
+		rotation.Message = ""
+		rotation.Phase = keyRotationRemovingKey
+		return r.startKeyRotationPhase(ctx, policy, rotator, pvcs, backup.KeyRotationRemoveKey)
+
+	case keyRotationRemovingKey:
+		logger.Info("Encryption key rotation completed", "secret", rotation.NewSecret)
+		rotation.Phase = keyRotationCompleted
+		rotation.Jobs = nil
+		now := metav1.Now()
+		rotation.CompletionTime = &now
+		return r.clearKeyRotationRequest(ctx, policy)
+	}
+
+	return nil
+}
+
+// pendingSecretSwitches lists the destinations of a rotation whose spec does not name the new Secret yet
+func pendingSecretSwitches(policy *backupv1alpha1.BackupPolicy, rotation *backupv1alpha1.KeyRotationStatus) []string {
+	var pending []string
+	if backup.ResticPasswordSecret(policy) != rotation.NewSecret {
+		pending = append(pending, "spec.destination")
+	}
+	for _, name := range rotation.Replicas {
+		replica := findReplica(policy, name)
+		if replica != nil && backup.ResticPasswordSecret(backup.ReplicaPolicy(policy, replica)) != rotation.NewSecret {
+			pending = append(pending, fmt.Sprintf("replica %s", name))
+		}
+	}
+	return pending
+}
+
+// startKeyRotationPhase creates one rotation Job per target repository, on the primary destination and
+// on the replicas being rotated, and records them in status
+func (r *BackupPolicyReconciler) startKeyRotationPhase(ctx context.Context, policy *backupv1alpha1.BackupPolicy, rotator backup.KeyRotator, pvcs []corev1.PersistentVolumeClaim, phase string) error {
+	rotation := policy.Status.KeyRotation
+	rotation.Jobs = nil
+
+	targets := []*backupv1alpha1.BackupPolicy{policy}
+	for _, name := range rotation.Replicas {
+		// Replicas removed from the spec since the rotation started are left alone
+		if replica := findReplica(policy, name); replica != nil {
+			targets = append(targets, backup.ReplicaPolicy(policy, replica))
+		}
+	}
+	for _, target := range targets {
+		for _, pvc := range pvcs {
+			jobName, err := rotator.RotateKey(ctx, &pvc, target, rotation.OldSecret, rotation.NewSecret, phase)
+			if err != nil {
+				return r.failKeyRotation(ctx, policy, err.Error())
+			}
+			rotation.Jobs = append(rotation.Jobs, backupKey(pvc.Namespace, jobName))
+		}
+	}
+
+	return nil
+}
+
+// keyRotationJobsFinished reports whether all rotation Jobs succeeded, or the first one that failed
+func (r *BackupPolicyReconciler) keyRotationJobsFinished(ctx context.Context, jobs []string) (bool, string, error) {
+	done := true
+	for _, key := range jobs {
+		namespace, name, _ := strings.Cut(key, "/")
+		job := &batchv1.Job{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, job); err != nil {
+			if errors.IsNotFound(err) {
+				return false, key, nil
+			}
+			return false, "", err
+		}
+
+		if job.Status.Failed > 0 && job.Status.Succeeded == 0 && isJobFinished(job) {
+			if message := jobTerminationMessage(ctx, r.Client, job); message != "" {
+				return false, fmt.Sprintf("%s (%s)", key, message), nil
+			}
+			return false, key, nil
+		}
+		if job.Status.Succeeded == 0 {
+			done = false
+		}
+	}
+	return done, "", nil
+}
+
+func (r *BackupPolicyReconciler) failKeyRotation(ctx context.Context, policy *backupv1alpha1.BackupPolicy, message string) error {
+	log.FromContext(ctx).Error(nil, "Encryption key rotation failed", "message", message)
+
+	rotation := policy.Status.KeyRotation
+	rotation.Phase = keyRotationFailed
+	rotation.Message = message
+	now := metav1.Now()
+	rotation.CompletionTime = &now
+	return r.clearKeyRotationRequest(ctx, policy)
+}
+
+// clearKeyRotationRequest removes the rotate-encryption-key annotation once the request has been handled
+func (r *BackupPolicyReconciler) clearKeyRotationRequest(ctx context.Context, policy *backupv1alpha1.BackupPolicy) error {
+	return r.clearAnnotation(ctx, policy, backup.AnnotationRotateEncryptionKey)
+}
+
+// clearAnnotation removes a request annotation from the policy once the request has been handled
+func (r *BackupPolicyReconciler) clearAnnotation(ctx context.Context, policy *backupv1alpha1.BackupPolicy, key string) error {
+	if _, found := policy.Annotations[key]; !found {
+		return nil
+	}
+	delete(policy.Annotations, key)
+	return r.updatePolicy(ctx, policy)
+}
+
+// updatePolicy persists metadata and spec changes without discarding in-memory status changes
+func (r *BackupPolicyReconciler) updatePolicy(ctx context.Context, policy *backupv1alpha1.BackupPolicy) error {
+	status := policy.Status.DeepCopy()
+	if err := r.Update(ctx, policy); err != nil {
+		return err
+	}
+	policy.Status = *status
+	return nil
+}
+
+func isKeyRotationFinished(rotation *backupv1alpha1.KeyRotationStatus) bool {
+	return rotation.Phase == keyRotationCompleted || rotation.Phase == keyRotationFailed
+}
+
+// isJobFinished reports whether the Job reached a terminal Complete or Failed condition
+func isJobFinished(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func newKeyRotationFixture(t *testing.T) (*BackupPolicyReconciler, *backupv1alpha1.BackupPolicy, []corev1.PersistentVolumeClaim) {
+	t.Helper()
+
+	scheme := newTestScheme(t)
+
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "policy",
+			Namespace:   "control",
+			Annotations: map[string]string{backup.AnnotationRotateEncryptionKey: "key-v2"},
+		},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Strategy: "external",
+			Destination: backupv1alpha1.Destination{
+				Type:             "s3",
+				URL:              "s3://bucket/backups",
+				EncryptionSecret: "key-v1",
+			},
+			Replicas: []backupv1alpha1.Replica{
+				{Name: "offsite", Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://offsite/backups", EncryptionSecret: "key-v1"}},
+				{Name: "archive", Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://archive/backups", EncryptionSecret: "archive-key"}},
+			},
+		},
+	}
+	secrets := []client.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "key-v1", Namespace: "control"},
+			Data:       map[string][]byte{backup.ResticPasswordKey: []byte("old")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "key-v2", Namespace: "control"},
+			Data:       map[string][]byte{backup.ResticPasswordKey: []byte("new")},
+		},
+	}
+
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(append(secrets, policy)...).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}, &batchv1.Job{}).
+		Build()
+
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: "policy", Namespace: "control"}, policy); err != nil {
+		t.Fatalf("failed to get policy: %v", err)
+	}
+
+	pvcs := []corev1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "control"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "tenant"}},
+	}
+	return &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}, policy, pvcs
+}
+
+func completeJobs(t *testing.T, c client.Client, jobs []string) {
+	t.Helper()
+	for _, key := range jobs {
+		namespace, name, _ := strings.Cut(key, "/")
+		job := &batchv1.Job{}
+		if err := c.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, job); err != nil {
+			t.Fatalf("rotation Job %s not found: %v", key, err)
+		}
+		job.Status.Succeeded = 1
+		if err := c.Status().Update(context.Background(), job); err != nil {
+			t.Fatalf("failed to complete Job %s: %v", key, err)
+		}
+	}
+}
+
+func TestReconcileKeyRotationAddsBeforeRemoving(t *testing.T) {
+	ctx := context.Background()
+	r, policy, pvcs := newKeyRotationFixture(t)
+	strategy := backup.NewExternalStrategy(r.Client, nil, nil, nil)
+
+	if err := r.reconcileKeyRotation(ctx, policy, strategy, pvcs); err != nil {
+		t.Fatalf("reconcileKeyRotation returned error: %v", err)
+	}
+	// Both PVCs on the primary destination and on the replica sharing the old password
+	if rotation := policy.Status.KeyRotation; rotation == nil || rotation.Phase != keyRotationAddingKey || len(rotation.Jobs) != 4 ||
+		len(rotation.Replicas) != 1 || rotation.Replicas[0] != "offsite" {
+		t.Fatalf("expected four add-key Jobs, got %+v", policy.Status.KeyRotation)
+	}
+	if policy.Spec.Destination.EncryptionSecret != "key-v1" {
+		t.Fatalf("encryption secret must not switch before all repositories have the new key")
+	}
+
+	// Nothing changes while Jobs are running
+	if err := r.reconcileKeyRotation(ctx, policy, strategy, pvcs); err != nil {
+		t.Fatalf("reconcileKeyRotation returned error: %v", err)
+	}
+	if phase := policy.Status.KeyRotation.Phase; phase != keyRotationAddingKey {
+		t.Fatalf("expected rotation to wait for add-key Jobs, got phase %s", phase)
+	}
+
+	completeJobs(t, r.Client, policy.Status.KeyRotation.Jobs)
+	if err := r.reconcileKeyRotation(ctx, policy, strategy, pvcs); err != nil {
+		t.Fatalf("reconcileKeyRotation returned error: %v", err)
+	}
+	if rotation := policy.Status.KeyRotation; rotation.Phase != keyRotationAwaitingSwitch || !strings.Contains(rotation.Message, "replica offsite") {
+		t.Fatalf("expected the rotation to wait for the spec to switch, got %+v", rotation)
+	}
+	if policy.Spec.Destination.EncryptionSecret != "key-v1" {
+		t.Fatalf("the controller must not edit the encryption secret of the spec")
+	}
+
+	// The old key stays until every rotated destination names the new Secret
+	policy.Spec.Destination.EncryptionSecret = "key-v2"
+	if err := r.reconcileKeyRotation(ctx, policy, strategy, pvcs); err != nil {
+		t.Fatalf("reconcileKeyRotation returned error: %v", err)
+	}
+	if phase := policy.Status.KeyRotation.Phase; phase != keyRotationAwaitingSwitch {
+		t.Fatalf("expected the rotation to wait for the replica, got phase %s", phase)
+	}
+	policy.Spec.Replicas[0].Destination.EncryptionSecret = "key-v2"
+	if err := r.reconcileKeyRotation(ctx, policy, strategy, pvcs); err != nil {
+		t.Fatalf("reconcileKeyRotation returned error: %v", err)
+	}
+	if rotation := policy.Status.KeyRotation; rotation.Phase != keyRotationRemovingKey || len(rotation.Jobs) != 4 || rotation.Message != "" {
+		t.Fatalf("expected four remove-key Jobs, got %+v", rotation)
+	}
+
+	completeJobs(t, r.Client, policy.Status.KeyRotation.Jobs)
+	if err := r.reconcileKeyRotation(ctx, policy, strategy, pvcs); err != nil {
+		t.Fatalf("reconcileKeyRotation returned error: %v", err)
+	}
+	if phase := policy.Status.KeyRotation.Phase; phase != keyRotationCompleted {
+		t.Fatalf("expected rotation to complete, got phase %s", phase)
+	}
+
+	stored := &backupv1alpha1.BackupPolicy{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "policy", Namespace: "control"}, stored); err != nil {
+		t.Fatalf("failed to get policy: %v", err)
+	}
+	if _, found := stored.Annotations[backup.AnnotationRotateEncryptionKey]; found {
+		t.Fatalf("expected rotation request annotation to be removed")
+	}
+}
+
+func TestReconcileKeyRotationFailsOnMissingSecret(t *testing.T) {
+	ctx := context.Background()
+	r, policy, pvcs := newKeyRotationFixture(t)
+	policy.Annotations[backup.AnnotationRotateEncryptionKey] = "missing"
+
+	if err := r.reconcileKeyRotation(ctx, policy, backup.NewExternalStrategy(r.Client, nil, nil, nil), pvcs); err != nil {
+		t.Fatalf("reconcileKeyRotation returned error: %v", err)
+	}
+	rotation := policy.Status.KeyRotation
+	if rotation == nil || rotation.Phase != keyRotationFailed || rotation.Message == "" {
+		t.Fatalf("expected failed rotation with a message, got %+v", rotation)
+	}
+	if policy.Spec.Destination.EncryptionSecret != "key-v1" {
+		t.Fatalf("failed rotation must keep the old encryption secret")
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+	"github.com/example/backup-operator/internal/metrics"
+)
+
+// finalizePolicy applies the deletion policy to the backups of a BackupPolicy that is being deleted,
+// then removes the Jobs and copied Secrets it created and releases the policy finalizer.
+func (r *BackupPolicyReconciler) finalizePolicy(ctx context.Context, policy *backupv1alpha1.BackupPolicy) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(policy, backup.PolicyFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// Record the outcome of backups that finished since the last reconcile
+	if err := r.handleJobCompletion(ctx, policy); err != nil {
+		logger.Error(err, "Failed to handle Job completion")
+	}
+
+	// Stop backup, verification and key rotation work; forget Jobs must finish to purge artifacts
+	if err := r.deletePolicyJobs(ctx, policy, false); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	items, err := r.listBackups(ctx, policy)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for i := range items {
+		if err := r.abortRunningBackup(ctx, &items[i]); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if policy.Spec.DeletionPolicy == backupv1alpha1.DeletionPolicyDelete {
+		if len(items) > 0 {
+			// The Backups are gone once their repositories can be removed, so remember which ones they used
+			if err := r.recordPurgedRepositories(ctx, policy, items); err != nil {
+				return ctrl.Result{}, err
+			}
+			for i := range items {
+				item := &items[i]
+				if !item.DeletionTimestamp.IsZero() {
+					continue
+				}
+				if err := r.Delete(ctx, item); err != nil && !errors.IsNotFound(err) {
+					return ctrl.Result{}, fmt.Errorf("failed to delete Backup %s/%s: %w", item.Namespace, item.Name, err)
+				}
+			}
+			// The Backup finalizers delete the artifacts and need the policy for destination and credentials
+			logger.Info("Waiting for backups to be deleted before removing BackupPolicy", "remaining", len(items))
+			return ctrl.Result{RequeueAfter: requeueWhileDeleting}, nil
+		}
+		// Forgetting every snapshot leaves the repository config, keys and locks behind
+		if err := r.removeRepositories(ctx, policy); err != nil {
+			logger.Error(err, "Failed to remove restic repositories")
+			backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonDeleteFailed,
+				fmt.Sprintf("Failed to remove the repositories of the BackupPolicy: %v", err), policy)
+		}
+		backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonBackupsPurged,
+			"Deleted all backups of the BackupPolicy as requested by deletionPolicy Delete", policy)
+	} else {
+		if err := r.retainBackups(ctx, policy, items); err != nil {
+			backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonDeleteFailed,
+				fmt.Sprintf("Failed to detach backups from the BackupPolicy: %v", err), policy)
+			return ctrl.Result{}, err
+		}
+		backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonBackupsRetained,
+			fmt.Sprintf("Retained %d backup(s) after the BackupPolicy was deleted", len(items)), policy)
+	}
+
+	if err := r.deletePolicyJobs(ctx, policy, true); err != nil {
+		return ctrl.Result{}, err
+	}
+	// The policy no longer needs copies; other policies of the namespace may still use shared ones
+	if err := r.reconcileCredentialCopies(ctx, policy, nil); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	controllerutil.RemoveFinalizer(policy, backup.PolicyFinalizer)
+	if err := r.updatePolicy(ctx, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	metrics.DeletePolicy(policy.Namespace, policy.Name)
+	logger.Info("Finalized BackupPolicy", "deletionPolicy", policy.Spec.DeletionPolicy, "backups", len(items))
+	return ctrl.Result{}, nil
+}
+
+// recordPurgedRepositories adds the repositories of the policy's backups to status.purgedRepositories
+func (r *BackupPolicyReconciler) recordPurgedRepositories(ctx context.Context, policy *backupv1alpha1.BackupPolicy, items []backupv1alpha1.Backup) error {
+	original := policy.Status.DeepCopy()
+	for i := range items {
+		repository := items[i].Namespace + "/" + items[i].Spec.PVCName
+		if !slices.Contains(policy.Status.PurgedRepositories, repository) {
+			policy.Status.PurgedRepositories = append(policy.Status.PurgedRepositories, repository)
+		}
+	}
+	slices.Sort(policy.Status.PurgedRepositories)
+	if err := r.patchStatus(ctx, policy, original); err != nil {
+		return fmt.Errorf("failed to record the repositories of the deleted backups: %w", err)
+	}
+	return nil
+}
+
+// removeRepositories deletes the repositories of the policy's deleted backups once they are gone, if the
+// strategy keeps any
+func (r *BackupPolicyReconciler) removeRepositories(ctx context.Context, policy *backupv1alpha1.BackupPolicy) error {
+	strategy := policy.Spec.Strategy
+	if strategy == "" {
+		strategy = defaultStrategy
+	}
+	impl, err := r.getBackupStrategy(ctx, strategy, policy)
+	if err != nil {
+		return err
+	}
+	if remover, ok := impl.(backup.RepositoryRemover); ok {
+		return remover.RemoveRepositories(ctx, policy, policy.Status.PurgedRepositories)
+	}
+	return nil
+}
+
+// abortRunningBackup marks a backup whose Job was stopped by the policy deletion as failed
+func (r *BackupPolicyReconciler) abortRunningBackup(ctx context.Context, item *backupv1alpha1.Backup) error {
+	if !runsInJob(item) {
+		return nil
+	}
+	item.Status.Phase = backupv1alpha1.BackupPhaseFailed
+	now := metav1.Now()
+	item.Status.CompletionTime = &now
+	item.Status.Message = "BackupPolicy deleted while the backup was running"
+	if err := r.Status().Update(ctx, item); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to update Backup %s/%s status: %w", item.Namespace, item.Name, err)
+	}
+	return nil
+}
+
+// retainBackups removes the policy's owner references from Backups and strategy artifacts
+// so garbage collection of the policy leaves them in place
+func (r *BackupPolicyReconciler) retainBackups(ctx context.Context, policy *backupv1alpha1.BackupPolicy, items []backupv1alpha1.Backup) error {
+	namespaces := map[string]struct{}{
+		policy.Namespace: {},
+	}
+	for _, ns := range policy.Spec.Namespaces {
+		namespaces[ns] = struct{}{}
+	}
+
+	strategy := policy.Spec.Strategy
+	if strategy == "" {
+		strategy = defaultStrategy
+	}
+	strategies := map[string]struct{}{
+		strategy: {},
+	}
+
+	for i := range items {
+		item := &items[i]
+		namespaces[item.Namespace] = struct{}{}
+		strategies[item.Spec.Strategy] = struct{}{}
+
+		owners := item.GetOwnerReferences()
+		kept := owners[:0]
+		for _, owner := range owners {
+			if owner.UID != policy.UID {
+				kept = append(kept, owner)
+			}
+		}
+		if len(kept) == len(owners) {
+			continue
+		}
+		item.SetOwnerReferences(kept)
+		if err := r.Update(ctx, item); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to detach Backup %s/%s: %w", item.Namespace, item.Name, err)
+		}
+	}
+
+	nsList := make([]string, 0, len(namespaces))
+	for ns := range namespaces {
+		nsList = append(nsList, ns)
+	}
+	for name := range strategies {
+		impl, err := r.getBackupStrategy(ctx, name, policy)
+		if err != nil {
+			// A strategy that cannot be built (e.g., a broken destination) must not block the deletion
+			log.FromContext(ctx).Error(err, "Failed to get backup strategy, skipping detach", "strategy", name)
+			continue
+		}
+		if detacher, ok := impl.(backup.Detacher); ok {
+			if err := detacher.Detach(ctx, policy, nsList); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deletePolicyJobs deletes the Jobs created for the policy in all namespaces, optionally including forget Jobs
+func (r *BackupPolicyReconciler) deletePolicyJobs(ctx context.Context, policy *backupv1alpha1.BackupPolicy, includeForget bool) error {
+	jobs, err := r.listPolicyJobs(ctx, policy)
+	if err != nil {
+		return err
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		if _, isForget := job.Labels[backup.LabelDeletedBackup]; isForget && !includeForget {
+			continue
+		}
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete Job %s/%s: %w", job.Namespace, job.Name, err)
+		}
+	}
+	return nil
+}
+
+// runsInJob reports whether a backup is still being written by a Job; VolumeSnapshots are taken without one
+func runsInJob(item *backupv1alpha1.Backup) bool {
+	return item.Status.Phase == backupv1alpha1.BackupPhaseRunning && item.Spec.Strategy != "snapshot"
+}
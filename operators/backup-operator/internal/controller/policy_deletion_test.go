@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func newDeletingPolicyFixture(t *testing.T, deletionPolicy string) (*BackupPolicyReconciler, *backupv1alpha1.BackupPolicy) {
+	t.Helper()
+	now := metav1.Now()
+	policy := &backupv1alpha1.BackupPolicy{
+		TypeMeta: metav1.TypeMeta{APIVersion: backupv1alpha1.GroupVersion.String(), Kind: "BackupPolicy"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "policy",
+			Namespace:         "ns",
+			UID:               "policy-uid",
+			Finalizers:        []string{backup.PolicyFinalizer},
+			DeletionTimestamp: &now,
+		},
+		Spec: backupv1alpha1.BackupPolicySpec{Strategy: "snapshot", DeletionPolicy: deletionPolicy},
+	}
+
+	completed := newBackupRecord("policy-data-1", "data", backupv1alpha1.BackupPhaseCompleted, time.Now().Add(-time.Hour))
+	completed.Finalizers = []string{backup.BackupFinalizer}
+	completed.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: backupv1alpha1.GroupVersion.String(),
+		Kind:       "BackupPolicy",
+		Name:       policy.Name,
+		UID:        policy.UID,
+	}}
+	running := newBackupRecord("policy-data-2", "data", backupv1alpha1.BackupPhaseRunning, time.Now())
+	running.Finalizers = []string{backup.BackupFinalizer}
+
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:      "policy-data-2",
+		Namespace: "ns",
+		Labels:    map[string]string{backup.LabelPolicy: "policy", backup.LabelPolicyNamespace: "ns"},
+	}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      "restic-credentials",
+		Namespace: "apps",
+		Labels:    backup.ManagedSecretLabels(policy),
+	}}
+
+	scheme := newTestScheme(t)
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(policy, completed, running, job, secret).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}, &backupv1alpha1.Backup{}).
+		Build()
+	return &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}, policy
+}
+
+func TestFinalizePolicyRetainsBackups(t *testing.T) {
+	r, policy := newDeletingPolicyFixture(t, backupv1alpha1.DeletionPolicyRetain)
+	ctx := context.Background()
+
+	if _, err := r.finalizePolicy(ctx, policy); err != nil {
+		t.Fatalf("finalizePolicy returned error: %v", err)
+	}
+
+	items := &backupv1alpha1.BackupList{}
+	if err := r.List(ctx, items); err != nil {
+		t.Fatalf("failed to list Backups: %v", err)
+	}
+	if len(items.Items) != 2 {
+		t.Fatalf("expected both Backups to be retained, got %d", len(items.Items))
+	}
+	for _, item := range items.Items {
+		if len(item.OwnerReferences) != 0 {
+			t.Errorf("expected Backup %s to be detached from the policy, got %v", item.Name, item.OwnerReferences)
+		}
+		if item.Name == "policy-data-2" && item.Status.Phase != backupv1alpha1.BackupPhaseFailed {
+			t.Errorf("expected interrupted Backup to be Failed, got %q", item.Status.Phase)
+		}
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs); err != nil || len(jobs.Items) != 0 {
+		t.Errorf("expected policy Jobs to be deleted, got %d (err %v)", len(jobs.Items), err)
+	}
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets); err != nil || len(secrets.Items) != 0 {
+		t.Errorf("expected copied Secrets to be deleted, got %d (err %v)", len(secrets.Items), err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(policy), &backupv1alpha1.BackupPolicy{}); err == nil {
+		t.Errorf("expected BackupPolicy to be gone once its finalizer was removed")
+	}
+}
+
+func TestFinalizePolicyWaitsForDeletedBackups(t *testing.T) {
+	r, policy := newDeletingPolicyFixture(t, backupv1alpha1.DeletionPolicyDelete)
+	ctx := context.Background()
+
+	result, err := r.finalizePolicy(ctx, policy)
+	if err != nil {
+		t.Fatalf("finalizePolicy returned error: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Fatalf("expected a requeue while Backup artifacts are being deleted")
+	}
+
+	items := &backupv1alpha1.BackupList{}
+	if err := r.List(ctx, items); err != nil {
+		t.Fatalf("failed to list Backups: %v", err)
+	}
+	for _, item := range items.Items {
+		if item.DeletionTimestamp.IsZero() {
+			t.Errorf("expected Backup %s to be marked for deletion", item.Name)
+		}
+	}
+	current := &backupv1alpha1.BackupPolicy{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(policy), current); err != nil {
+		t.Fatalf("expected BackupPolicy to be kept until its backups are deleted: %v", err)
+	}
+	// The repositories are removed after the Backups are gone, so they are recorded first
+	if !slices.Equal(current.Status.PurgedRepositories, []string{"ns/data"}) {
+		t.Errorf("expected the repository of the deleted backups to be recorded, got %v", current.Status.PurgedRepositories)
+	}
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets); err != nil || len(secrets.Items) != 1 {
+		t.Errorf("expected copied Secrets to be kept for the forget Jobs, got %d (err %v)", len(secrets.Items), err)
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+const (
+	// maxReplicationAttempts is how often a copy is started before its failure is final
+	maxReplicationAttempts = 4
+	// replicationRetryBackoff is the delay before the first retry of a failed copy; it doubles after every attempt
+	replicationRetryBackoff = 5 * time.Minute
+)
+
+// runReplication starts copying completed backups to the policy's replicas. Each replica repository
+// receives one copy per PVC at a time, oldest backup first, and never while other policy Jobs hold repository locks.
+// Failed copies are started again once their backoff has passed.
+func (r *BackupPolicyReconciler) runReplication(ctx context.Context, policy *backupv1alpha1.BackupPolicy, strategy backup.Strategy) error {
+	logger := log.FromContext(ctx)
+
+	if len(policy.Spec.Replicas) == 0 {
+		return nil
+	}
+	replicator, ok := strategy.(backup.Replicator)
+	if !ok {
+		return fmt.Errorf("backup strategy %q does not support replication", policy.Spec.Strategy)
+	}
+
+	// Copies read the source repository with the current password, which a rotation is about to remove
+	if rotation := policy.Status.KeyRotation; rotation != nil && !isKeyRotationFinished(rotation) {
+		logger.Info("Encryption key rotation in progress, postponing replication")
+		return nil
+	}
+
+	// The replica prune and the backup prune need exclusive repository locks
+	active, err := r.hasActivePolicyJobs(ctx, policy)
+	if err != nil {
+		return err
+	}
+	if active {
+		return nil
+	}
+
+	items, err := r.listBackups(ctx, policy)
+	if err != nil {
+		return err
+	}
+
+	started := make(map[string]bool)
+	// items are sorted newest first
+	for i := len(items) - 1; i >= 0; i-- {
+		item := &items[i]
+		if item.Status.Phase != backupv1alpha1.BackupPhaseCompleted || !item.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		changed := false
+		for j := range policy.Spec.Replicas {
+			replica := &policy.Spec.Replicas[j]
+			key := backupKey(item.Namespace, item.Spec.PVCName) + "/" + replica.Name
+			if started[key] {
+				continue
+			}
+			attempt := int32(1)
+			if existing := findReplicaStatus(item.Status.Replicas, replica.Name); existing != nil {
+				retryAt, retry := replicaRetryTime(existing)
+				if !retry || time.Now().Before(retryAt) {
+					continue
+				}
+				// The retry reuses the Job name, so the failed Job goes first
+				removed, err := r.removeReplicationJob(ctx, item, replica.Name)
+				if err != nil {
+					logger.Error(err, "Failed to delete failed replication Job", "backup", item.Name, "replica", replica.Name)
+					continue
+				}
+				if !removed {
+					continue
+				}
+				attempt = max(existing.Attempts, 1) + 1
+			}
+			started[key] = true
+
+			status := backupv1alpha1.ReplicaStatus{Name: replica.Name, Phase: backupv1alpha1.BackupPhaseRunning, Attempts: attempt}
+			result, err := replicator.Replicate(ctx, item, policy, replica)
+			if err != nil {
+				logger.Error(err, "Failed to start replication", "backup", item.Name, "replica", replica.Name)
+				now := metav1.Now()
+				status.Phase = backupv1alpha1.BackupPhaseFailed
+				status.CompletionTime = &now
+				status.Message = err.Error()
+				backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonReplicationFailed,
+					fmt.Sprintf("Failed to start copying backup %s/%s to replica %s: %v", item.Namespace, item.Name, replica.Name, err),
+					policy, item)
+			} else {
+				status.Job = result.Name
+				status.Location = result.Location
+				message := fmt.Sprintf("Copying backup %s/%s to replica %s", item.Namespace, item.Name, replica.Name)
+				if attempt > 1 {
+					message = fmt.Sprintf("%s (attempt %d of %d)", message, attempt, maxReplicationAttempts)
+				}
+				backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonReplicationStarted, message, policy, item)
+			}
+			if existing := findReplicaStatus(item.Status.Replicas, replica.Name); existing != nil {
+				*existing = status
+			} else {
+				item.Status.Replicas = append(item.Status.Replicas, status)
+			}
+			changed = true
+		}
+
+		if changed {
+			if err := r.Status().Update(ctx, item); err != nil {
+				logger.Error(err, "Failed to update Backup status", "backup", item.Name, "namespace", item.Namespace)
+			}
+		}
+	}
+	return nil
+}
+
+// replicaRetryTime returns when a failed copy is started again; false when it is not retried
+func replicaRetryTime(status *backupv1alpha1.ReplicaStatus) (time.Time, bool) {
+	if status.Phase != backupv1alpha1.BackupPhaseFailed || status.CompletionTime == nil {
+		return time.Time{}, false
+	}
+	// Copies recorded before attempts were counted have been tried once
+	attempts := max(status.Attempts, 1)
+	if attempts >= maxReplicationAttempts {
+		return time.Time{}, false
+	}
+	return status.CompletionTime.Add(replicationRetryBackoff << (attempts - 1)), true
+}
+
+// nextReplicaRetry returns the earliest retry of a failed copy among the policy's backups
+func nextReplicaRetry(policy *backupv1alpha1.BackupPolicy, items []backupv1alpha1.Backup) (time.Time, bool) {
+	var next time.Time
+	found := false
+	if policy.Spec.Suspend {
+		return next, false
+	}
+	for i := range items {
+		for _, replica := range policy.Spec.Replicas {
+			status := findReplicaStatus(items[i].Status.Replicas, replica.Name)
+			if status == nil {
+				continue
+			}
+			if retryAt, ok := replicaRetryTime(status); ok && (!found || retryAt.Before(next)) {
+				next, found = retryAt, true
+			}
+		}
+	}
+	return next, found
+}
+
+// removeReplicationJob deletes the finished Job of a previous copy; true once it is gone
+func (r *BackupPolicyReconciler) removeReplicationJob(ctx context.Context, item *backupv1alpha1.Backup, replica string) (bool, error) {
+	job := &batchv1.Job{}
+	key := types.NamespacedName{Namespace: item.Namespace, Name: backup.ReplicationJobName(item.Name, replica)}
+	if err := r.Get(ctx, key, job); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	if job.DeletionTimestamp.IsZero() {
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// applyReplicationResults records the outcome of finished replication Jobs of a backup.
+// jobs are keyed by namespace/backup/replica.
+func (r *BackupPolicyReconciler) applyReplicationResults(policy *backupv1alpha1.BackupPolicy, item *backupv1alpha1.Backup, jobs map[string]batchv1.Job) bool {
+	changed := false
+	for i := range item.Status.Replicas {
+		status := &item.Status.Replicas[i]
+		if status.Phase != backupv1alpha1.BackupPhaseRunning {
+			continue
+		}
+		job, found := jobs[replicationJobKey(item.Namespace, item.Name, status.Name)]
+		switch {
+		case !found:
+			continue
+		case job.Status.Succeeded > 0:
+			completed := metav1.Now()
+			if job.Status.CompletionTime != nil {
+				completed = *job.Status.CompletionTime
+			}
+			status.Phase = backupv1alpha1.BackupPhaseCompleted
+			status.CompletionTime = &completed
+			backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonReplicated,
+				fmt.Sprintf("Copied backup %s/%s to replica %s", item.Namespace, item.Name, status.Name), policy, item)
+		case isJobFinished(&job):
+			now := metav1.Now()
+			status.Phase = backupv1alpha1.BackupPhaseFailed
+			status.CompletionTime = &now
+			status.Message = fmt.Sprintf("Replication Job %s failed, see its logs for details", job.Name)
+			backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonReplicationFailed,
+				fmt.Sprintf("Failed to copy backup %s/%s to replica %s", item.Namespace, item.Name, status.Name), policy, item)
+		default:
+			continue
+		}
+		changed = true
+	}
+	return changed
+}
+
+// summarizeReplicas counts the copies of each configured replica
+func summarizeReplicas(replicas []backupv1alpha1.Replica, items []backupv1alpha1.Backup) []backupv1alpha1.ReplicaSummary {
+	if len(replicas) == 0 {
+		return nil
+	}
+	summaries := make([]backupv1alpha1.ReplicaSummary, 0, len(replicas))
+	for _, replica := range replicas {
+		summary := backupv1alpha1.ReplicaSummary{Name: replica.Name}
+		for i := range items {
+			status := findReplicaStatus(items[i].Status.Replicas, replica.Name)
+			if status == nil {
+				continue
+			}
+			switch status.Phase {
+			case backupv1alpha1.BackupPhaseCompleted:
+				summary.CompletedBackups++
+				if status.CompletionTime != nil && (summary.LastReplicationTime == nil || status.CompletionTime.After(summary.LastReplicationTime.Time)) {
+					summary.LastReplicationTime = status.CompletionTime
+				}
+			case backupv1alpha1.BackupPhaseFailed:
+				summary.FailedBackups++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+func findReplica(policy *backupv1alpha1.BackupPolicy, name string) *backupv1alpha1.Replica {
+	for i := range policy.Spec.Replicas {
+		if policy.Spec.Replicas[i].Name == name {
+			return &policy.Spec.Replicas[i]
+		}
+	}
+	return nil
+}
+
+func findReplicaStatus(statuses []backupv1alpha1.ReplicaStatus, name string) *backupv1alpha1.ReplicaStatus {
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+func replicationJobKey(namespace, backupName, replica string) string {
+	return backupKey(namespace, backupName) + "/" + replica
+}
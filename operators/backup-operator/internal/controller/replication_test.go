@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func TestRunReplicationCopiesOldestBackupFirst(t *testing.T) {
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Strategy:    "external",
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://primary/backups", CredentialsSecret: "restic-credentials"},
+			Replicas: []backupv1alpha1.Replica{{
+				Name:        "offsite",
+				Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://offsite/copies", CredentialsSecret: "offsite-credentials"},
+			}},
+		},
+	}
+	secrets := []client.Object{
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "restic-credentials", Namespace: "ns"}, Data: map[string][]byte{backup.ResticPasswordKey: []byte("primary")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "offsite-credentials", Namespace: "ns"}, Data: map[string][]byte{backup.ResticPasswordKey: []byte("offsite")}},
+	}
+	older := newBackupRecord("policy-data-1", "data", backupv1alpha1.BackupPhaseCompleted, time.Now().Add(-2*time.Hour))
+	newer := newBackupRecord("policy-data-2", "data", backupv1alpha1.BackupPhaseCompleted, time.Now().Add(-time.Hour))
+
+	scheme := newTestScheme(t)
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(append(secrets, policy, older, newer)...).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}, &backupv1alpha1.Backup{}).
+		Build()
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+	strategy := backup.NewExternalStrategy(fakeClient, nil, nil, nil)
+
+	if err := r.runReplication(ctx, policy, strategy); err != nil {
+		t.Fatalf("runReplication returned error: %v", err)
+	}
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(newer), newer); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if len(newer.Status.Replicas) != 0 {
+		t.Fatalf("expected the newer backup to wait for the running copy, got %v", newer.Status.Replicas)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(older), older); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if len(older.Status.Replicas) != 1 || older.Status.Replicas[0].Phase != backupv1alpha1.BackupPhaseRunning {
+		t.Fatalf("expected the older backup to be copied first, got %v", older.Status.Replicas)
+	}
+
+	// A running replication Job holds off further copies
+	job := &batchv1.Job{}
+	key := types.NamespacedName{Namespace: "ns", Name: backup.ReplicationJobName(older.Name, "offsite")}
+	if err := r.Get(ctx, key, job); err != nil {
+		t.Fatalf("expected replication Job: %v", err)
+	}
+	if err := r.runReplication(ctx, policy, strategy); err != nil {
+		t.Fatalf("runReplication returned error: %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(newer), newer); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if len(newer.Status.Replicas) != 0 {
+		t.Fatalf("expected no copy while a replication Job is active, got %v", newer.Status.Replicas)
+	}
+
+	job.Status.Succeeded = 1
+	job.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	if err := r.Status().Update(ctx, job); err != nil {
+		t.Fatalf("failed to update Job status: %v", err)
+	}
+	if err := r.handleJobCompletion(ctx, policy); err != nil {
+		t.Fatalf("handleJobCompletion returned error: %v", err)
+	}
+
+	if err := r.Get(ctx, client.ObjectKeyFromObject(older), older); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if phase := older.Status.Replicas[0].Phase; phase != backupv1alpha1.BackupPhaseCompleted {
+		t.Fatalf("expected the copy to be completed, got %s", phase)
+	}
+	summary := policy.Status.Replicas
+	if len(summary) != 1 || summary[0].CompletedBackups != 1 || summary[0].LastReplicationTime == nil {
+		t.Fatalf("expected the replica summary to count the copy, got %v", summary)
+	}
+}
+
+func TestRunReplicationRetriesFailedCopies(t *testing.T) {
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Strategy:    "external",
+			Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://primary/backups", CredentialsSecret: "restic-credentials"},
+			Replicas: []backupv1alpha1.Replica{{
+				Name:        "offsite",
+				Destination: backupv1alpha1.Destination{Type: "s3", URL: "s3://offsite/copies", CredentialsSecret: "restic-credentials"},
+			}},
+		},
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "restic-credentials", Namespace: "ns"}, Data: map[string][]byte{backup.ResticPasswordKey: []byte("secret")}}
+	item := newBackupRecord("policy-data-1", "data", backupv1alpha1.BackupPhaseCompleted, time.Now().Add(-2*time.Hour))
+	failed := metav1.NewTime(time.Now().Add(-time.Minute))
+	item.Status.Replicas = []backupv1alpha1.ReplicaStatus{{Name: "offsite", Phase: backupv1alpha1.BackupPhaseFailed, CompletionTime: &failed, Attempts: 1}}
+	failedJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: backup.ReplicationJobName(item.Name, "offsite"), Namespace: "ns"},
+		Status:     batchv1.JobStatus{Failed: 3},
+	}
+
+	scheme := newTestScheme(t)
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(policy, secret, item, failedJob).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}, &backupv1alpha1.Backup{}).
+		Build()
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+	strategy := backup.NewExternalStrategy(fakeClient, nil, nil, nil)
+
+	// The backoff has not passed yet
+	if err := r.runReplication(ctx, policy, strategy); err != nil {
+		t.Fatalf("runReplication returned error: %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(failedJob), &batchv1.Job{}); err != nil {
+		t.Fatalf("expected the failed Job to be kept during the backoff: %v", err)
+	}
+	items := []backupv1alpha1.Backup{*item}
+	if retryAt, ok := nextReplicaRetry(policy, items); !ok || !retryAt.Equal(failed.Add(replicationRetryBackoff)) {
+		t.Fatalf("expected a retry after the backoff, got %v %v", retryAt, ok)
+	}
+
+	// Once it has, the failed Job is removed and the copy starts again
+	expired := metav1.NewTime(time.Now().Add(-replicationRetryBackoff - time.Minute))
+	item.Status.Replicas[0].CompletionTime = &expired
+	if err := r.Status().Update(ctx, item); err != nil {
+		t.Fatalf("failed to update Backup status: %v", err)
+	}
+	for range 2 {
+		if err := r.runReplication(ctx, policy, strategy); err != nil {
+			t.Fatalf("runReplication returned error: %v", err)
+		}
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(item), item); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	status := item.Status.Replicas[0]
+	if status.Phase != backupv1alpha1.BackupPhaseRunning || status.Attempts != 2 {
+		t.Fatalf("expected the second attempt to be running, got %+v", status)
+	}
+
+	// The last attempt is final
+	status.Phase = backupv1alpha1.BackupPhaseFailed
+	status.Attempts = maxReplicationAttempts
+	status.CompletionTime = &expired
+	if _, ok := replicaRetryTime(&status); ok {
+		t.Fatal("expected no retry after the last attempt")
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// reconcileRestore carries out the restore requested in the restore annotation of a Backup. The outcome is
+// recorded in status.lastRestore and the annotation removed, so a failed restore is not retried.
+func (r *BackupReconciler) reconcileRestore(ctx context.Context, item *backupv1alpha1.Backup) error {
+	logger := log.FromContext(ctx)
+
+	request := &backup.RestoreRequest{}
+	target, err := r.restore(ctx, item, request)
+	now := metav1.Now()
+	status := &backupv1alpha1.RestoreStatus{
+		Phase:       backupv1alpha1.RestorePhaseStarted,
+		RequestTime: request.RequestedAt,
+		Time:        &now,
+	}
+	if target != nil {
+		status.Target = target.Namespace + "/" + target.Name
+	}
+	if err != nil {
+		logger.Error(err, "Restore failed", "backup", item.Name)
+		status.Phase = backupv1alpha1.RestorePhaseFailed
+		status.Message = err.Error()
+		backup.RecordEvent(r.Recorder, corev1.EventTypeWarning, backup.EventReasonRestoreFailed, fmt.Sprintf("Failed to restore: %v", err), item)
+	} else {
+		logger.Info("Started restore", "backup", item.Name, "target", status.Target)
+		backup.RecordEvent(r.Recorder, corev1.EventTypeNormal, backup.EventReasonRestoreStarted, fmt.Sprintf("Restoring into persistentvolumeclaim %s", status.Target), item)
+	}
+
+	// A merge patch without resourceVersion, so a Backup updated while the restore started keeps its outcome
+	patch := client.MergeFrom(item.DeepCopy())
+	item.Status.LastRestore = status
+	if err := r.Status().Patch(ctx, item, patch); err != nil {
+		return err
+	}
+	patch = client.MergeFrom(item.DeepCopy())
+	delete(item.Annotations, backup.AnnotationRestore)
+	return r.Patch(ctx, item, patch)
+}
+
+// restore parses the restore request of a Backup into request and starts the restore, returning the target PVC
+func (r *BackupReconciler) restore(ctx context.Context, item *backupv1alpha1.Backup, request *backup.RestoreRequest) (*corev1.PersistentVolumeClaim, error) {
+	if err := json.Unmarshal([]byte(item.Annotations[backup.AnnotationRestore]), request); err != nil {
+		return nil, fmt.Errorf("invalid restore request: %w", err)
+	}
+	policy, namespace, err := restoreSource(ctx, r.Client, item)
+	if err != nil {
+		return nil, err
+	}
+	if request.Namespace != "" {
+		if item.Spec.Strategy == "snapshot" && request.Namespace != item.Namespace {
+			return nil, fmt.Errorf("snapshot backups can only be restored into the namespace of their VolumeSnapshot")
+		}
+		namespace = request.Namespace
+	}
+	if request.PVC == "" {
+		if item.Spec.Imported == nil {
+			return nil, fmt.Errorf("the restore request names no target PVC")
+		}
+		request.PVC = item.Spec.PVCName
+	}
+
+	target, err := restoreTarget(ctx, r.Client, item, namespace, request)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := newBackupStrategy(ctx, r.Client, r.Recorder, item.Spec.Strategy, policy, nil)
+	if err != nil {
+		return target, err
+	}
+	return target, strategy.Restore(ctx, backup.StoredBackupFor(item), policy, target)
+}
+
+// restoreSource returns the policy whose destination holds the backup and the namespace it is restored into
+// by default. Imported backups are read with the credentials of their BackupRepository.
+func restoreSource(ctx context.Context, c client.Client, item *backupv1alpha1.Backup) (*backupv1alpha1.BackupPolicy, string, error) {
+	imported := item.Spec.Imported
+	if imported == nil {
+		policy := &backupv1alpha1.BackupPolicy{}
+		key := types.NamespacedName{Namespace: item.Spec.PolicyRef.Namespace, Name: item.Spec.PolicyRef.Name}
+		if err := c.Get(ctx, key, policy); err != nil {
+			return nil, "", fmt.Errorf("failed to get BackupPolicy %s: %w", key, err)
+		}
+		return policy, item.Namespace, nil
+	}
+	if imported.DumpFile != "" {
+		return nil, "", fmt.Errorf("backup %s/%s holds the database dump %s; load it from \"restic dump\" output", item.Namespace, item.Name, imported.DumpFile)
+	}
+
+	repository := &backupv1alpha1.BackupRepository{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: item.Namespace, Name: imported.Repository}, repository); err != nil {
+		return nil, "", fmt.Errorf("failed to get BackupRepository %s/%s: %w", item.Namespace, imported.Repository, err)
+	}
+	namespace := imported.Namespace
+	if mapped := repository.Spec.NamespaceMapping[namespace]; mapped != "" {
+		namespace = mapped
+	}
+	return backup.RepositoryPolicy(repository, item.Spec.PolicyRef.Name), namespace, nil
+}
+
+// restoreTarget returns the existing target PVC, or a new one shaped like the PVC the backup was taken from
+func restoreTarget(ctx context.Context, c client.Client, item *backupv1alpha1.Backup, namespace string, request *backup.RestoreRequest) (*corev1.PersistentVolumeClaim, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: request.PVC}
+	existing := &corev1.PersistentVolumeClaim{}
+	err := c.Get(ctx, key, existing)
+	if err == nil {
+		if request.StorageClass != "" || request.Size != "" {
+			return nil, fmt.Errorf("PVC %s already exists; the storage class and size only apply to new PVCs", key)
+		}
+		return existing, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get PVC %s: %w", key, err)
+	}
+
+	target := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: request.PVC, Namespace: namespace},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		},
+	}
+
+	// The source PVC of an imported backup lives in the cluster that wrote it
+	source := &corev1.PersistentVolumeClaim{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: item.Namespace, Name: item.Spec.PVCName}, source)
+	switch {
+	case item.Spec.Imported != nil:
+		if request.Size == "" {
+			return nil, fmt.Errorf("backup %s was imported from another cluster, the size of the new PVC must be set", item.Name)
+		}
+	case err == nil:
+		target.Spec.AccessModes = source.Spec.AccessModes
+		target.Spec.StorageClassName = source.Spec.StorageClassName
+		target.Spec.VolumeMode = source.Spec.VolumeMode
+		target.Spec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceStorage: source.Spec.Resources.Requests[corev1.ResourceStorage],
+		}
+	case !apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("failed to get source PVC %s/%s: %w", item.Namespace, item.Spec.PVCName, err)
+	case request.Size == "":
+		return nil, fmt.Errorf("source PVC %s/%s no longer exists, the size of the new PVC must be set", item.Namespace, item.Spec.PVCName)
+	}
+
+	if request.StorageClass != "" {
+		target.Spec.StorageClassName = &request.StorageClass
+	}
+	if request.Size != "" {
+		size, err := resource.ParseQuantity(request.Size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size %q: %w", request.Size, err)
+		}
+		target.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: size}
+	}
+	return target, nil
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+// requestRestore sets the restore annotation on item and reconciles it, returning the updated Backup
+func requestRestore(t *testing.T, r *BackupReconciler, item *backupv1alpha1.Backup, request backup.RestoreRequest) *backupv1alpha1.Backup {
+	t.Helper()
+	ctx := context.Background()
+	value, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("failed to encode restore request: %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(item), item); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	item.Annotations = map[string]string{backup.AnnotationRestore: string(value)}
+	if err := r.Update(ctx, item); err != nil {
+		t.Fatalf("failed to annotate Backup: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(item)}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	updated := &backupv1alpha1.Backup{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(item), updated); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if _, found := updated.Annotations[backup.AnnotationRestore]; found {
+		t.Fatal("expected the restore annotation to be removed")
+	}
+	if updated.Status.LastRestore == nil {
+		t.Fatal("expected the restore outcome in status.lastRestore")
+	}
+	return updated
+}
+
+func TestBackupReconcilerRestoresSnapshot(t *testing.T) {
+	storageClass := "fast"
+	source := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "ns"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")},
+			},
+		},
+	}
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec:       backupv1alpha1.BackupPolicySpec{Schedule: "0 2 * * *", Strategy: "snapshot"},
+	}
+	item := newBackupRecord("policy-data-1", "data", backupv1alpha1.BackupPhaseCompleted, time.Now())
+	item.Spec.Strategy = "snapshot"
+	item.Status.Location = "ns/policy-data-1"
+	r := newBackupReconciler(t, policy, item, source)
+	ctx := context.Background()
+
+	restored := requestRestore(t, r, item, backup.RestoreRequest{PVC: "data-restored", Size: "10Gi", RequestedAt: "2025-01-01T00:00:00Z"})
+	if status := restored.Status.LastRestore; status.Phase != backupv1alpha1.RestorePhaseStarted ||
+		status.Target != "ns/data-restored" || status.RequestTime != "2025-01-01T00:00:00Z" {
+		t.Fatalf("unexpected restore status %+v", status)
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "data-restored"}, pvc); err != nil {
+		t.Fatalf("expected the target PVC to be created: %v", err)
+	}
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName != storageClass {
+		t.Fatalf("expected the storage class of the source PVC, got %v", pvc.Spec.StorageClassName)
+	}
+	if size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "10Gi" {
+		t.Fatalf("expected the requested size, got %s", size.String())
+	}
+	if pvc.Spec.DataSource == nil || pvc.Spec.DataSource.Name != item.Name {
+		t.Fatalf("expected the snapshot as data source, got %+v", pvc.Spec.DataSource)
+	}
+
+	restored = requestRestore(t, r, item, backup.RestoreRequest{PVC: "data-restored"})
+	if status := restored.Status.LastRestore; status.Phase != backupv1alpha1.RestorePhaseFailed || status.Message == "" {
+		t.Fatalf("expected restoring a snapshot into an existing PVC to fail, got %+v", status)
+	}
+}
+
+func TestBackupReconcilerRestoresImportedBackupIntoMappedNamespace(t *testing.T) {
+	repository := &backupv1alpha1.BackupRepository{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "dr"},
+		Spec: backupv1alpha1.BackupRepositorySpec{
+			Destination: backupv1alpha1.Destination{
+				Type: "s3", URL: "s3://bucket/backups", CredentialsSecret: "s3", EncryptionSecret: "s3",
+			},
+			NamespaceMapping: map[string]string{"apps": "apps-dr"},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "dr"},
+		Data:       map[string][]byte{backup.ResticPasswordKey: []byte("pw")},
+	}
+	item := newBackupRecord("nightly-data-20250101", "data", backupv1alpha1.BackupPhaseCompleted, time.Now())
+	item.Namespace = "dr"
+	item.Labels = map[string]string{backup.LabelRepository: "prod"}
+	item.Spec.PolicyRef = backupv1alpha1.PolicyReference{Name: "nightly", Namespace: "dr"}
+	item.Spec.Imported = &backupv1alpha1.ImportSource{Repository: "prod", Namespace: "apps"}
+	item.Status.Location = "s3:bucket/backups/nightly/apps/data"
+	item.Status.SnapshotID = "4f2a9c1e"
+	r := newBackupReconciler(t, repository, secret, item)
+	ctx := context.Background()
+
+	restored := requestRestore(t, r, item, backup.RestoreRequest{})
+	if status := restored.Status.LastRestore; status.Phase != backupv1alpha1.RestorePhaseFailed || !strings.Contains(status.Message, "size") {
+		t.Fatalf("expected restoring an imported backup into a new PVC without a size to fail, got %+v", status)
+	}
+	restored = requestRestore(t, r, item, backup.RestoreRequest{Size: "5Gi"})
+	if status := restored.Status.LastRestore; status.Phase != backupv1alpha1.RestorePhaseStarted || status.Target != "apps-dr/data" {
+		t.Fatalf("unexpected restore status %+v", status)
+	}
+
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "apps-dr", Name: "data"}, &corev1.PersistentVolumeClaim{}); err != nil {
+		t.Fatalf("expected the source PVC to be re-created in the mapped namespace: %v", err)
+	}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "apps-dr", Name: "s3"}, &corev1.Secret{}); err != nil {
+		t.Fatalf("expected the repository credentials to be copied: %v", err)
+	}
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, client.InNamespace("apps-dr")); err != nil || len(jobs.Items) != 1 {
+		t.Fatalf("expected one restore Job in the mapped namespace, got %d (%v)", len(jobs.Items), err)
+	}
+	env := map[string]string{}
+	for _, e := range jobs.Items[0].Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if env["RESTORE_SNAPSHOT"] != "4f2a9c1e" || env["RESTIC_REPOSITORY"] != item.Status.Location {
+		t.Fatalf("expected the imported snapshot to be restored, got %v", env)
+	}
+}
+
+func TestReconcileRestorePatchesStaleBackup(t *testing.T) {
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec:       backupv1alpha1.BackupPolicySpec{Schedule: "0 2 * * *", Strategy: "snapshot"},
+	}
+	item := newBackupRecord("policy-data-1", "data", backupv1alpha1.BackupPhaseCompleted, time.Now())
+	item.Spec.Strategy = "snapshot"
+	item.Annotations = map[string]string{backup.AnnotationRestore: `{}`}
+	r := newBackupReconciler(t, policy, item)
+	ctx := context.Background()
+
+	stale := &backupv1alpha1.Backup{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(item), stale); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	current := stale.DeepCopy()
+	current.Labels["example.com/changed"] = "true"
+	if err := r.Update(ctx, current); err != nil {
+		t.Fatalf("failed to update Backup: %v", err)
+	}
+
+	if err := r.reconcileRestore(ctx, stale); err != nil {
+		t.Fatalf("reconcileRestore returned error on a stale Backup: %v", err)
+	}
+	updated := &backupv1alpha1.Backup{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(item), updated); err != nil {
+		t.Fatalf("failed to get Backup: %v", err)
+	}
+	if updated.Status.LastRestore == nil || updated.Status.LastRestore.Phase != backupv1alpha1.RestorePhaseFailed {
+		t.Fatalf("expected the failed restore in status.lastRestore, got %+v", updated.Status.LastRestore)
+	}
+	if updated.Labels["example.com/changed"] != "true" {
+		t.Fatal("expected the concurrent label change to be kept")
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+// patchStatus writes the status changes made since original with a merge patch. The patch carries no
+// resourceVersion, so it cannot conflict with metadata or spec updates made during the same reconcile.
+func (r *BackupPolicyReconciler) patchStatus(ctx context.Context, policy *backupv1alpha1.BackupPolicy, original *backupv1alpha1.BackupPolicyStatus) error {
+	if equality.Semantic.DeepEqual(original, &policy.Status) {
+		return nil
+	}
+	base := policy.DeepCopy()
+	base.Status = *original
+	return client.IgnoreNotFound(r.Status().Patch(ctx, policy, client.MergeFrom(base)))
+}
+
+// setPhase records the policy phase with the Ready and Scheduled conditions for the current generation
+func (r *BackupPolicyReconciler) setPhase(policy *backupv1alpha1.BackupPolicy, phase string, message string) {
+	policy.Status.Phase = phase
+	policy.Status.ObservedGeneration = policy.Generation
+
+	ready := metav1.ConditionTrue
+	if phase == "Error" {
+		ready = metav1.ConditionFalse
+	}
+	setCondition(policy, backupv1alpha1.ConditionReady, ready, phase, message)
+
+	if phase == "Suspended" {
+		setCondition(policy, backupv1alpha1.ConditionScheduled, metav1.ConditionFalse, "Suspended",
+			"Scheduled backups are suspended")
+	} else if _, err := r.nextRun(policy, time.Now()); err != nil {
+		setCondition(policy, backupv1alpha1.ConditionScheduled, metav1.ConditionFalse, "InvalidSchedule",
+			fmt.Sprintf("Failed to parse schedule %q: %v", policy.Spec.Schedule, err))
+	} else if policy.Status.NextRunTime != nil {
+		setCondition(policy, backupv1alpha1.ConditionScheduled, metav1.ConditionTrue, "Scheduled",
+			fmt.Sprintf("Next backup at %s", policy.Status.NextRunTime.Format(time.RFC3339)))
+	}
+}
+
+// setPhaseWithNextRun records the policy phase along with the next run time
+func (r *BackupPolicyReconciler) setPhaseWithNextRun(policy *backupv1alpha1.BackupPolicy, phase string, nextRun time.Time) {
+	policy.Status.NextRunTime = &metav1.Time{Time: nextRun}
+	r.setPhase(policy, phase, fmt.Sprintf("Next backup at %s", nextRun.Format(time.RFC3339)))
+}
+
+// setLastBackupCondition reports whether the latest finished backup of every PVC completed. items are sorted newest first.
+func setLastBackupCondition(policy *backupv1alpha1.BackupPolicy, items []backupv1alpha1.Backup) {
+	seen := make(map[string]bool)
+	var failed []string
+	for _, item := range items {
+		phase := item.Status.Phase
+		if phase != backupv1alpha1.BackupPhaseCompleted && phase != backupv1alpha1.BackupPhaseFailed {
+			continue
+		}
+		key := backupKey(item.Namespace, item.Spec.PVCName)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if phase == backupv1alpha1.BackupPhaseFailed {
+			failed = append(failed, fmt.Sprintf("backup %s of PVC %s failed: %s", item.Name, key, item.Status.Message))
+		}
+	}
+
+	switch {
+	case len(seen) == 0:
+		return
+	case len(failed) > 0:
+		sort.Strings(failed)
+		setCondition(policy, backupv1alpha1.ConditionLastBackupSucceeded, metav1.ConditionFalse, "BackupFailed",
+			strings.Join(failed, "; "))
+	default:
+		setCondition(policy, backupv1alpha1.ConditionLastBackupSucceeded, metav1.ConditionTrue, "BackupCompleted",
+			fmt.Sprintf("The latest backup of %d PVC(s) completed", len(seen)))
+	}
+}
+
+// setCondition sets a policy condition for the current generation; the transition time only changes with the status
+func setCondition(policy *backupv1alpha1.BackupPolicy, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&policy.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: policy.Generation,
+	})
+}
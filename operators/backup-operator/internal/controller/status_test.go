@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+func TestPatchStatusDoesNotConflictWithStaleObject(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := backupv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns", Generation: 2},
+		Spec:       backupv1alpha1.BackupPolicySpec{Schedule: "0 2 * * *"},
+	}
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(policy).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}).
+		Build()
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(policy), policy); err != nil {
+		t.Fatalf("failed to get BackupPolicy: %v", err)
+	}
+	original := policy.Status.DeepCopy()
+
+	// Another writer changes the policy after it was read
+	other := policy.DeepCopy()
+	other.Labels = map[string]string{"team": "storage"}
+	if err := fakeClient.Update(ctx, other); err != nil {
+		t.Fatalf("failed to update BackupPolicy: %v", err)
+	}
+
+	r.setPhaseWithNextRun(policy, "Active", time.Now().Add(time.Hour))
+	if err := r.patchStatus(ctx, policy, original); err != nil {
+		t.Fatalf("patchStatus returned error: %v", err)
+	}
+
+	stored := &backupv1alpha1.BackupPolicy{}
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(policy), stored); err != nil {
+		t.Fatalf("failed to get BackupPolicy: %v", err)
+	}
+	if stored.Status.Phase != "Active" || stored.Status.ObservedGeneration != policy.Generation {
+		t.Fatalf("expected the patched phase and observed generation, got %+v", stored.Status)
+	}
+	if !meta.IsStatusConditionTrue(stored.Status.Conditions, backupv1alpha1.ConditionReady) ||
+		!meta.IsStatusConditionTrue(stored.Status.Conditions, backupv1alpha1.ConditionScheduled) {
+		t.Fatalf("expected Ready and Scheduled conditions, got %v", stored.Status.Conditions)
+	}
+	if stored.Labels["team"] != "storage" {
+		t.Fatalf("expected the concurrent label change to be kept, got %v", stored.Labels)
+	}
+}
+
+func TestSetPhaseKeepsTransitionTimeWhileStatusIsUnchanged(t *testing.T) {
+	r := &BackupPolicyReconciler{}
+	policy := &backupv1alpha1.BackupPolicy{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+
+	r.setPhase(policy, "Active", "No PVCs found")
+	transition := metav1.NewTime(time.Now().Add(-time.Hour))
+	meta.FindStatusCondition(policy.Status.Conditions, backupv1alpha1.ConditionReady).LastTransitionTime = transition
+
+	r.setPhase(policy, "Active", "Backup completed successfully")
+	ready := meta.FindStatusCondition(policy.Status.Conditions, backupv1alpha1.ConditionReady)
+	if !ready.LastTransitionTime.Equal(&transition) || ready.Message != "Backup completed successfully" {
+		t.Fatalf("expected only the message to change, got %+v", ready)
+	}
+
+	policy.Generation = 2
+	r.setPhase(policy, "Error", "Failed to find target PVCs")
+	ready = meta.FindStatusCondition(policy.Status.Conditions, backupv1alpha1.ConditionReady)
+	if ready.Status != metav1.ConditionFalse || ready.LastTransitionTime.Equal(&transition) || ready.ObservedGeneration != 2 {
+		t.Fatalf("expected a transition to False for generation 2, got %+v", ready)
+	}
+
+	policy.Spec.Schedule = "not a schedule"
+	r.setPhase(policy, "Error", "Failed to find target PVCs")
+	if meta.IsStatusConditionTrue(policy.Status.Conditions, backupv1alpha1.ConditionScheduled) {
+		t.Fatalf("expected Scheduled to be false for an invalid schedule, got %v", policy.Status.Conditions)
+	}
+}
+
+func TestSetLastBackupConditionUsesLatestBackupOfEachPVC(t *testing.T) {
+	now := time.Now()
+	policy := &backupv1alpha1.BackupPolicy{}
+	items := []backupv1alpha1.Backup{
+		*newBackupRecord("data-3", "data", backupv1alpha1.BackupPhaseRunning, now),
+		*newBackupRecord("data-2", "data", backupv1alpha1.BackupPhaseCompleted, now.Add(-time.Hour)),
+		*newBackupRecord("logs-2", "logs", backupv1alpha1.BackupPhaseFailed, now.Add(-time.Hour)),
+		*newBackupRecord("data-1", "data", backupv1alpha1.BackupPhaseFailed, now.Add(-2*time.Hour)),
+	}
+
+	setLastBackupCondition(policy, items)
+	condition := meta.FindStatusCondition(policy.Status.Conditions, backupv1alpha1.ConditionLastBackupSucceeded)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "BackupFailed" {
+		t.Fatalf("expected the failed logs backup to be reported, got %+v", condition)
+	}
+
+	setLastBackupCondition(policy, items[:2])
+	if !meta.IsStatusConditionTrue(policy.Status.Conditions, backupv1alpha1.ConditionLastBackupSucceeded) {
+		t.Fatalf("expected the completed data backup to be reported, got %v", policy.Status.Conditions)
+	}
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+	"github.com/example/backup-operator/internal/backup"
+)
+
+func TestSuspendedPolicyOnlyRunsRequestedBackups(t *testing.T) {
+	scheme := newTestScheme(t)
+	policy := &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "ns"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"backup": "true"}},
+			Schedule: "0 2 * * *",
+			Strategy: "snapshot",
+			Suspend:  true,
+		},
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "ns", Labels: map[string]string{"backup": "true"}},
+	}
+	fakeClient := newFakeClientBuilder(scheme).
+		WithObjects(policy, pvc).
+		WithStatusSubresource(&backupv1alpha1.BackupPolicy{}, &backupv1alpha1.Backup{}).
+		Build()
+	r := &BackupPolicyReconciler{Client: fakeClient, Scheme: scheme}
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, policy); err != nil {
+		t.Fatalf("failed to get BackupPolicy: %v", err)
+	}
+	if policy.Status.Phase != "Suspended" || policy.Status.NextRunTime == nil {
+		t.Fatalf("expected a suspended policy with its next slot, got %+v", policy.Status)
+	}
+	condition := meta.FindStatusCondition(policy.Status.Conditions, backupv1alpha1.ConditionScheduled)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "Suspended" {
+		t.Fatalf("expected Scheduled=False with reason Suspended, got %+v", condition)
+	}
+	backups := &backupv1alpha1.BackupList{}
+	if err := fakeClient.List(ctx, backups); err != nil {
+		t.Fatalf("failed to list Backups: %v", err)
+	}
+	if len(backups.Items) != 0 {
+		t.Fatalf("expected no backups while suspended, got %d", len(backups.Items))
+	}
+
+	// A backup-now request runs once and is removed
+	policy.Annotations = map[string]string{backup.AnnotationBackupNow: "2025-01-01T00:00:00Z"}
+	if err := fakeClient.Update(ctx, policy); err != nil {
+		t.Fatalf("failed to annotate BackupPolicy: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := fakeClient.List(ctx, backups); err != nil {
+		t.Fatalf("failed to list Backups: %v", err)
+	}
+	if len(backups.Items) != 1 {
+		t.Fatalf("expected the requested backup, got %d", len(backups.Items))
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, policy); err != nil {
+		t.Fatalf("failed to get BackupPolicy: %v", err)
+	}
+	if _, found := policy.Annotations[backup.AnnotationBackupNow]; found {
+		t.Fatalf("expected the backup request to be cleared, got %v", policy.Annotations)
+	}
+	if policy.Status.Phase != "Suspended" {
+		t.Fatalf("expected the policy to stay suspended, got %s", policy.Status.Phase)
+	}
+}
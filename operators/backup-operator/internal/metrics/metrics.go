@@ -0,0 +1,175 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the backup domain metrics served on the controller-runtime metrics endpoint.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "backup_operator"
+
+// Label names shared by all backup metrics
+const (
+	labelPolicyNamespace = "policy_namespace"
+	labelPolicy          = "policy"
+	labelNamespace       = "namespace"
+	labelPVC             = "pvc"
+	labelStrategy        = "strategy"
+	labelReason          = "reason"
+)
+
+// Failure reasons used when a backup fails without a Job reporting its own reason
+const (
+	ReasonJobCreationFailed = "JobCreationFailed"
+	ReasonJobFailed         = "JobFailed"
+	ReasonStuckJobKilled    = "StuckJobKilled"
+	ReasonSnapshotFailed    = "SnapshotFailed"
+)
+
+var (
+	// LastSuccessfulBackup is the completion time of the last successful backup per PVC
+	LastSuccessfulBackup = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_successful_backup_timestamp_seconds",
+		Help:      "Unix time of the last successful backup of a PVC.",
+	}, []string{labelPolicyNamespace, labelPolicy, labelNamespace, labelPVC})
+
+	// BackupDuration observes how long backup Jobs ran until they succeeded
+	BackupDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "backup_duration_seconds",
+		Help:      "Duration of successful backups.",
+		// 10s up to ~11h
+		Buckets: prometheus.ExponentialBuckets(10, 2, 13),
+	}, []string{labelPolicyNamespace, labelPolicy, labelStrategy})
+
+	// BackupSize is the size of the last successful backup per PVC
+	BackupSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "backup_size_bytes",
+		Help:      "Size of the last successful backup of a PVC.",
+	}, []string{labelPolicyNamespace, labelPolicy, labelNamespace, labelPVC})
+
+	// BackupFailures counts failed backups by reason
+	BackupFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "backup_failures_total",
+		Help:      "Number of failed backups by reason.",
+	}, []string{labelPolicyNamespace, labelPolicy, labelReason})
+
+	// RetentionDeletions counts backups deleted by the retention policy
+	RetentionDeletions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retention_deletions_total",
+		Help:      "Number of backups deleted by the retention policy.",
+	}, []string{labelPolicyNamespace, labelPolicy, labelStrategy})
+
+	// ActiveJobs is the number of backup Jobs of a policy that have not finished yet
+	ActiveJobs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_jobs",
+		Help:      "Number of unfinished Jobs per policy.",
+	}, []string{labelPolicyNamespace, labelPolicy})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		LastSuccessfulBackup,
+		BackupDuration,
+		BackupSize,
+		BackupFailures,
+		RetentionDeletions,
+		ActiveJobs,
+	)
+}
+
+// RecordBackupSuccess records a successful backup of a PVC
+func RecordBackupSuccess(policyNamespace, policy, strategy, namespace, pvc string, completed time.Time, duration time.Duration, sizeBytes int64) {
+	SetLastSuccessfulBackup(policyNamespace, policy, namespace, pvc, completed)
+	if duration > 0 {
+		BackupDuration.WithLabelValues(policyNamespace, policy, strategy).Observe(duration.Seconds())
+	}
+	if sizeBytes > 0 {
+		BackupSize.WithLabelValues(policyNamespace, policy, namespace, pvc).Set(float64(sizeBytes))
+	}
+}
+
+// SetLastSuccessfulBackup sets the completion time of the newest successful backup of a PVC, e.g. from the
+// Backup records after the operator restarted
+func SetLastSuccessfulBackup(policyNamespace, policy, namespace, pvc string, completed time.Time) {
+	LastSuccessfulBackup.WithLabelValues(policyNamespace, policy, namespace, pvc).Set(float64(completed.Unix()))
+}
+
+// RecordBackupFailure records a failed backup
+func RecordBackupFailure(policyNamespace, policy, reason string) {
+	BackupFailures.WithLabelValues(policyNamespace, policy, reason).Inc()
+}
+
+// RecordRetentionDeletions records backups removed by the retention policy
+func RecordRetentionDeletions(policyNamespace, policy, strategy string, count int) {
+	if count <= 0 {
+		return
+	}
+	RetentionDeletions.WithLabelValues(policyNamespace, policy, strategy).Add(float64(count))
+}
+
+// DeleteUntargetedPVCs removes the per-PVC series of a policy for PVCs targeted reports false for, e.g. PVCs
+// that were deleted or are no longer selected, so their last successful backup does not go stale forever
+func DeleteUntargetedPVCs(policyNamespace, policy string, targeted func(namespace, pvc string) bool) {
+	for _, vec := range []*prometheus.GaugeVec{LastSuccessfulBackup, BackupSize} {
+		// Collect holds the vector's lock, so the series are deleted once it is done
+		metrics := make(chan prometheus.Metric)
+		go func() {
+			vec.Collect(metrics)
+			close(metrics)
+		}()
+		var stale []prometheus.Labels
+		for metric := range metrics {
+			series := &dto.Metric{}
+			if err := metric.Write(series); err != nil {
+				continue
+			}
+			labels := prometheus.Labels{}
+			for _, pair := range series.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			if labels[labelPolicyNamespace] == policyNamespace && labels[labelPolicy] == policy &&
+				!targeted(labels[labelNamespace], labels[labelPVC]) {
+				stale = append(stale, labels)
+			}
+		}
+		for _, labels := range stale {
+			vec.Delete(labels)
+		}
+	}
+}
+
+// DeletePolicy removes all series of a deleted policy
+func DeletePolicy(policyNamespace, policy string) {
+	labels := prometheus.Labels{labelPolicyNamespace: policyNamespace, labelPolicy: policy}
+	LastSuccessfulBackup.DeletePartialMatch(labels)
+	BackupDuration.DeletePartialMatch(labels)
+	BackupSize.DeletePartialMatch(labels)
+	BackupFailures.DeletePartialMatch(labels)
+	RetentionDeletions.DeletePartialMatch(labels)
+	ActiveJobs.DeletePartialMatch(labels)
+}
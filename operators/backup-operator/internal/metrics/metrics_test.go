@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordBackupSuccessAndDeletePolicy(t *testing.T) {
+	completed := time.Unix(1735700000, 0)
+	RecordBackupSuccess("control", "policy", "external", "tenant", "data", completed, 2*time.Minute, 1024)
+	RecordBackupFailure("control", "policy", ReasonJobFailed)
+
+	if got := testutil.ToFloat64(LastSuccessfulBackup.WithLabelValues("control", "policy", "tenant", "data")); got != float64(completed.Unix()) {
+		t.Fatalf("expected last successful backup timestamp %d, got %v", completed.Unix(), got)
+	}
+	if got := testutil.ToFloat64(BackupSize.WithLabelValues("control", "policy", "tenant", "data")); got != 1024 {
+		t.Fatalf("expected backup size 1024, got %v", got)
+	}
+	if got := testutil.ToFloat64(BackupFailures.WithLabelValues("control", "policy", ReasonJobFailed)); got != 1 {
+		t.Fatalf("expected one failure, got %v", got)
+	}
+
+	DeletePolicy("control", "policy")
+	if got := testutil.CollectAndCount(LastSuccessfulBackup); got != 0 {
+		t.Fatalf("expected series of deleted policy to be removed, got %d", got)
+	}
+	if got := testutil.CollectAndCount(BackupFailures); got != 0 {
+		t.Fatalf("expected failure series of deleted policy to be removed, got %d", got)
+	}
+}
+
+func TestDeleteUntargetedPVCs(t *testing.T) {
+	completed := time.Unix(1735700000, 0)
+	RecordBackupSuccess("control", "policy", "external", "tenant", "data", completed, time.Minute, 1024)
+	RecordBackupSuccess("control", "policy", "external", "tenant", "removed", completed, time.Minute, 1024)
+	RecordBackupSuccess("control", "other", "external", "tenant", "removed", completed, time.Minute, 1024)
+	defer DeletePolicy("control", "policy")
+	defer DeletePolicy("control", "other")
+
+	DeleteUntargetedPVCs("control", "policy", func(namespace, pvc string) bool {
+		return namespace == "tenant" && pvc == "data"
+	})
+	if got := testutil.CollectAndCount(LastSuccessfulBackup); got != 2 {
+		t.Fatalf("expected the series of the untargeted PVC to be removed, got %d series", got)
+	}
+	if got := testutil.CollectAndCount(BackupSize); got != 2 {
+		t.Fatalf("expected the size of the untargeted PVC to be removed, got %d series", got)
+	}
+	if got := testutil.ToFloat64(LastSuccessfulBackup.WithLabelValues("control", "other", "tenant", "removed")); got != float64(completed.Unix()) {
+		t.Fatalf("expected the series of other policies to be kept, got %v", got)
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notify delivers backup lifecycle notifications to webhooks configured on a BackupPolicy.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+const (
+	// URLKey is the Secret key holding a webhook URL
+	URLKey = "url"
+
+	// Payload formats
+	FormatGeneric = "generic"
+	FormatSlack   = "slack"
+	FormatTeams   = "teams"
+
+	defaultMaxAttempts = 4
+	defaultBackoff     = 2 * time.Second
+	defaultTimeout     = 10 * time.Second
+)
+
+// Event describes a backup lifecycle event sent to webhooks
+type Event struct {
+	Type            backupv1alpha1.NotificationEvent `json:"event"`
+	PolicyNamespace string                           `json:"policyNamespace"`
+	Policy          string                           `json:"policy"`
+	Namespace       string                           `json:"namespace,omitempty"`
+	PVC             string                           `json:"pvc,omitempty"`
+	Backup          string                           `json:"backup,omitempty"`
+	Message         string                           `json:"message"`
+	Time            time.Time                        `json:"time"`
+}
+
+// Notifier sends events to the webhooks of a policy, retrying failed deliveries with exponential backoff
+type Notifier struct {
+	client     client.Reader
+	httpClient *http.Client
+
+	// MaxAttempts is the number of delivery attempts per webhook
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles after every attempt
+	Backoff time.Duration
+}
+
+// NewNotifier creates a Notifier reading webhook URL Secrets with the given client
+func NewNotifier(c client.Reader) *Notifier {
+	return &Notifier{
+		client:      c,
+		httpClient:  &http.Client{Timeout: defaultTimeout},
+		MaxAttempts: defaultMaxAttempts,
+		Backoff:     defaultBackoff,
+	}
+}
+
+// Notify delivers the event to every webhook of the policy subscribed to its type.
+// Failing webhooks do not prevent delivery to the others; all errors are returned joined.
+func (n *Notifier) Notify(ctx context.Context, policy *backupv1alpha1.BackupPolicy, event Event) error {
+	if policy.Spec.Notifications == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, webhook := range policy.Spec.Notifications.Webhooks {
+		if !subscribed(webhook, event.Type) {
+			continue
+		}
+		if err := n.send(ctx, policy.Namespace, webhook, event); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", webhook.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) send(ctx context.Context, namespace string, webhook backupv1alpha1.Webhook, event Event) error {
+	logger := log.FromContext(ctx)
+
+	url, err := n.webhookURL(ctx, namespace, webhook.URLSecret)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if webhook.Template != "" {
+		body, err = RenderTemplate(webhook.Template, event)
+	} else {
+		body, err = Payload(webhook.Format, event)
+	}
+	if err != nil {
+		return err
+	}
+
+	backoff := n.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := n.post(ctx, url, body)
+		if err == nil {
+			logger.V(1).Info("Delivered notification", "webhook", webhook.Name, "event", event.Type)
+			return nil
+		}
+		if !retry || attempt >= n.MaxAttempts {
+			return err
+		}
+
+		logger.Info("Notification delivery failed, retrying", "webhook", webhook.Name, "attempt", attempt, "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the payload and reports whether a failure is worth retrying
+func (n *Notifier) post(ctx context.Context, url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	// Rate limits and server errors are transient, other client errors are not
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+func (n *Notifier) webhookURL(ctx context.Context, namespace, secretName string) (string, error) {
+	secret := &corev1.Secret{}
+	if err := n.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: secretName}, secret); err != nil {
+		return "", fmt.Errorf("failed to get webhook Secret %s/%s: %w", namespace, secretName, err)
+	}
+
+	url := strings.TrimSpace(string(secret.Data[URLKey]))
+	if url == "" {
+		return "", fmt.Errorf("webhook Secret %s/%s has no %q key", namespace, secretName, URLKey)
+	}
+	return url, nil
+}
+
+func subscribed(webhook backupv1alpha1.Webhook, event backupv1alpha1.NotificationEvent) bool {
+	return len(webhook.Events) == 0 || slices.Contains(webhook.Events, event)
+}
+
+// Payload renders the event in the given webhook format
+func Payload(format string, event Event) ([]byte, error) {
+	switch format {
+	case "", FormatGeneric:
+		return json.Marshal(event)
+	case FormatSlack:
+		return json.Marshal(map[string]string{"text": summary(event)})
+	case FormatTeams:
+		// Office 365 connector MessageCard, accepted by Teams incoming webhooks
+		return json.Marshal(map[string]string{
+			"@type":      "MessageCard",
+			"@context":   "https://schema.org/extensions",
+			"summary":    title(event),
+			"themeColor": themeColor(event.Type),
+			"title":      title(event),
+			"text":       event.Message,
+		})
+	default:
+		return nil, fmt.Errorf("unknown webhook format: %s", format)
+	}
+}
+
+// RenderTemplate renders the event with a webhook's text/template. The json function quotes a value for JSON,
+// so messages with quotes or newlines keep the body valid.
+func RenderTemplate(text string, event Event) ([]byte, error) {
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"json": func(value any) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+	}).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, event); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	return body.Bytes(), nil
+}
+
+func title(event Event) string {
+	return fmt.Sprintf("%s: BackupPolicy %s/%s", event.Type, event.PolicyNamespace, event.Policy)
+}
+
+func summary(event Event) string {
+	icon := ":white_check_mark:"
+	if event.Type != backupv1alpha1.NotificationBackupSucceeded {
+		icon = ":rotating_light:"
+	}
+	return fmt.Sprintf("%s *%s*\n%s", icon, title(event), event.Message)
+}
+
+func themeColor(event backupv1alpha1.NotificationEvent) string {
+	switch event {
+	case backupv1alpha1.NotificationBackupSucceeded:
+		return "2EB886"
+	case backupv1alpha1.NotificationScheduleMissed:
+		return "DAA038"
+	default:
+		return "A30200"
+	}
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	backupv1alpha1 "github.com/example/backup-operator/api/v1alpha1"
+)
+
+func newTestNotifier(t *testing.T, url string) *Notifier {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add core scheme: %v", err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: "default"},
+		Data:       map[string][]byte{URLKey: []byte(url)},
+	}
+	n := NewNotifier(fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build())
+	n.Backoff = time.Millisecond
+	return n
+}
+
+func testPolicy(webhook backupv1alpha1.Webhook) *backupv1alpha1.BackupPolicy {
+	return &backupv1alpha1.BackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+		Spec: backupv1alpha1.BackupPolicySpec{
+			Notifications: &backupv1alpha1.Notifications{Webhooks: []backupv1alpha1.Webhook{webhook}},
+		},
+	}
+}
+
+func TestNotifyRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	n := newTestNotifier(t, server.URL)
+	policy := testPolicy(backupv1alpha1.Webhook{Name: "ops", URLSecret: "webhook", Format: FormatSlack})
+	event := Event{Type: backupv1alpha1.NotificationBackupFailed, PolicyNamespace: "default", Policy: "policy", Message: "backup failed"}
+
+	if err := n.Notify(context.Background(), policy, event); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if !strings.Contains(payload["text"], "backup failed") {
+		t.Fatalf("unexpected Slack payload %q", payload["text"])
+	}
+}
+
+func TestNotifyDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	n := newTestNotifier(t, server.URL)
+	policy := testPolicy(backupv1alpha1.Webhook{Name: "ops", URLSecret: "webhook"})
+
+	if err := n.Notify(context.Background(), policy, Event{Type: backupv1alpha1.NotificationBackupFailed}); err == nil {
+		t.Fatalf("expected an error for a 404 response")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", calls.Load())
+	}
+}
+
+func TestNotifySkipsUnsubscribedEvents(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	n := newTestNotifier(t, server.URL)
+	policy := testPolicy(backupv1alpha1.Webhook{
+		Name:      "ops",
+		URLSecret: "webhook",
+		Events:    []backupv1alpha1.NotificationEvent{backupv1alpha1.NotificationBackupFailed},
+	})
+
+	if err := n.Notify(context.Background(), policy, Event{Type: backupv1alpha1.NotificationBackupSucceeded}); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected no delivery for an unsubscribed event, got %d", calls.Load())
+	}
+}
+
+func TestPayloadFormats(t *testing.T) {
+	event := Event{Type: backupv1alpha1.NotificationScheduleMissed, PolicyNamespace: "default", Policy: "policy", Message: "late"}
+
+	generic, err := Payload(FormatGeneric, event)
+	if err != nil || !strings.Contains(string(generic), `"event":"ScheduleMissed"`) {
+		t.Fatalf("unexpected generic payload %s (%v)", generic, err)
+	}
+
+	teams, err := Payload(FormatTeams, event)
+	if err != nil || !strings.Contains(string(teams), `"@type":"MessageCard"`) {
+		t.Fatalf("unexpected Teams payload %s (%v)", teams, err)
+	}
+
+	if _, err := Payload("pager", event); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	event := Event{Type: backupv1alpha1.NotificationBackupFailed, PolicyNamespace: "default", Policy: "policy", Message: "restic said \"no\""}
+
+	body, err := RenderTemplate(`{"summary": {{ printf "%s: %s" .Type .Message | json }}, "policy": "{{ .Policy }}"}`, event)
+	if err != nil {
+		t.Fatalf("RenderTemplate returned error: %v", err)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("expected valid JSON, got %s (%v)", body, err)
+	}
+	if decoded["summary"] != `BackupFailed: restic said "no"` || decoded["policy"] != "policy" {
+		t.Fatalf("unexpected rendered payload %v", decoded)
+	}
+
+	if _, err := RenderTemplate(`{{ .Cluster }}`, event); err == nil {
+		t.Fatalf("expected an error for an unknown field")
+	}
+}
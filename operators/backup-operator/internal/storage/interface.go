@@ -27,13 +27,13 @@ import (
 type BackupInfo struct {
 	// Name/key of the backup
 	Name string
-	// Full path/location
+	// Path relative to the backend prefix, as accepted by Download, Delete and GetMetadata
 	Path string
 	// Size in bytes
 	Size int64
 	// Last modified time
 	ModifiedTime time.Time
-	// Additional metadata
+	// Additional metadata; List leaves it empty, use LoadMetadata to fetch it
 	Metadata map[string]string
 }
 
@@ -48,7 +48,7 @@ type Backend interface {
 	// Delete deletes a backup from the storage backend
 	Delete(ctx context.Context, path string) error
 
-	// List lists all backups with the given prefix
+	// List lists all backups with the given prefix without fetching their metadata
 	List(ctx context.Context, prefix string) ([]BackupInfo, error)
 
 	// Exists checks if a backup exists
@@ -68,13 +68,43 @@ type Config struct {
 	Bucket string
 	// Path prefix
 	Prefix string
-	// Credentials
+	// Credentials; the AWS default credential chain is used when empty
 	AccessKey string
 	SecretKey string
+	// IAM role assumed through STS, with the external ID required by its trust policy
+	RoleARN    string
+	ExternalID string
 	// Region (for S3)
 	Region string
 	// Storage class (for S3: STANDARD, GLACIER, DEEP_ARCHIVE)
 	StorageClass string
+	// Server-side encryption (for S3: AES256 or aws:kms) and the KMS key for aws:kms
+	ServerSideEncryption string
+	KMSKeyID             string
+	// Object lock retention (for S3: GOVERNANCE or COMPLIANCE) applied for the given number of days
+	ObjectLockMode          string
+	ObjectLockRetentionDays int32
+	// Multipart part size in bytes and parallel parts; SDK defaults when zero
+	PartSize    int64
+	Concurrency int
+	// Bandwidth limit in bytes per second for uploads and downloads; unlimited when zero
+	BandwidthLimit int64
+	// Checksum algorithm (for S3: CRC32C or SHA256) sent on uploads and validated on downloads
+	ChecksumAlgorithm string
+}
+
+// ProtectionVerifier is implemented by backends that can check the storage itself enforces encryption and
+// immutability, for writers such as restic that cannot set them per object
+type ProtectionVerifier interface {
+	// VerifyProtection returns an error when the storage defaults do not match the configured protection
+	VerifyProtection(ctx context.Context) error
+}
+
+// PrefixLister is implemented by backends that can list the directories below a prefix without listing
+// every object in them, e.g. to find restic repositories without walking their data
+type PrefixLister interface {
+	// ListPrefixes returns the paths of the directories directly below prefix, each ending in "/"
+	ListPrefixes(ctx context.Context, prefix string) ([]string, error)
 }
 
 // NewBackend creates a new storage backend based on the config
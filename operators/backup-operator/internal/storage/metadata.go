@@ -0,0 +1,51 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultMetadataConcurrency bounds the metadata requests LoadMetadata issues in parallel
+const DefaultMetadataConcurrency = 16
+
+// LoadMetadata fills the Metadata of the listed backups using at most concurrency parallel requests.
+// Backups whose metadata could not be read keep an empty map; the first error is returned.
+func LoadMetadata(ctx context.Context, backend Backend, backups []BackupInfo, concurrency int) error {
+	if concurrency <= 0 {
+		concurrency = DefaultMetadataConcurrency
+	}
+
+	// A plain group keeps going after a failure so one unreadable object does not hide the others
+	var g errgroup.Group
+	g.SetLimit(concurrency)
+	for i := range backups {
+		backup := &backups[i]
+		g.Go(func() error {
+			metadata, err := backend.GetMetadata(ctx, backup.Path)
+			if err != nil {
+				backup.Metadata = map[string]string{}
+				return err
+			}
+			backup.Metadata = metadata
+			return nil
+		})
+	}
+	return g.Wait()
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// metadataBackend serves GetMetadata slowly and tracks how many calls run at once
+type metadataBackend struct {
+	active, peak atomic.Int32
+}
+
+func (b *metadataBackend) GetMetadata(ctx context.Context, path string) (map[string]string, error) {
+	n := b.active.Add(1)
+	defer b.active.Add(-1)
+	for {
+		peak := b.peak.Load()
+		if n <= peak || b.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	if path == "broken" {
+		return nil, errors.New("access denied")
+	}
+	return map[string]string{"path": path}, nil
+}
+
+func (b *metadataBackend) Upload(context.Context, io.Reader, string, map[string]string) error {
+	return nil
+}
+func (b *metadataBackend) Download(context.Context, string) (io.ReadCloser, error) { return nil, nil }
+func (b *metadataBackend) Delete(context.Context, string) error                    { return nil }
+func (b *metadataBackend) List(context.Context, string) ([]BackupInfo, error)      { return nil, nil }
+func (b *metadataBackend) Exists(context.Context, string) (bool, error)            { return true, nil }
+
+func TestLoadMetadataBoundsConcurrency(t *testing.T) {
+	backend := &metadataBackend{}
+	backups := make([]BackupInfo, 20)
+	for i := range backups {
+		backups[i].Path = fmt.Sprintf("file-%d", i)
+	}
+	backups[7].Path = "broken"
+
+	err := LoadMetadata(context.Background(), backend, backups, 3)
+	if err == nil {
+		t.Fatalf("expected the failed metadata request to be reported")
+	}
+	if peak := backend.peak.Load(); peak > 3 {
+		t.Errorf("expected at most 3 concurrent requests, got %d", peak)
+	}
+	for i, backup := range backups {
+		if i == 7 {
+			if len(backup.Metadata) != 0 {
+				t.Errorf("expected empty metadata for the failed object, got %v", backup.Metadata)
+			}
+			continue
+		}
+		if backup.Metadata["path"] != backup.Path {
+			t.Errorf("expected metadata for %s, got %v", backup.Path, backup.Metadata)
+		}
+	}
+}
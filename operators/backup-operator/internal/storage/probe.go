@@ -0,0 +1,64 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ProbeKey returns the object written by the write probes of owner, relative to the backend prefix.
+// Each owner probes its own object, so concurrent probes sharing a bucket and prefix do not race.
+func ProbeKey(owner string) string {
+	return ".backup-operator-probe-" + owner
+}
+
+// Probe checks that the backend can be reached with its credentials. A read probe looks up key;
+// a write probe also uploads, reads back and deletes it, which needs the permissions backup Jobs use.
+// Buckets with object lock retain every probe version, so only read probes should be used on them.
+func Probe(ctx context.Context, backend Backend, key string, write bool) error {
+	if _, err := backend.Exists(ctx, key); err != nil {
+		return fmt.Errorf("read probe failed: %w", err)
+	}
+	if !write {
+		return nil
+	}
+
+	payload := []byte(time.Now().UTC().Format(time.RFC3339Nano))
+	if err := backend.Upload(ctx, bytes.NewReader(payload), key, map[string]string{"purpose": "probe"}); err != nil {
+		return fmt.Errorf("write probe failed: %w", err)
+	}
+	reader, err := backend.Download(ctx, key)
+	if err != nil {
+		return fmt.Errorf("read-back probe failed: %w", err)
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return fmt.Errorf("read-back probe failed: %w", err)
+	}
+	if !bytes.Equal(data, payload) {
+		return fmt.Errorf("read-back probe returned %d bytes that differ from the %d bytes written", len(data), len(payload))
+	}
+	if err := backend.Delete(ctx, key); err != nil {
+		return fmt.Errorf("delete probe failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// memoryBackend keeps objects in memory and fails the operations listed in failing
+type memoryBackend struct {
+	objects map[string][]byte
+	failing map[string]error
+}
+
+func (b *memoryBackend) Upload(_ context.Context, data io.Reader, path string, _ map[string]string) error {
+	if err := b.failing["upload"]; err != nil {
+		return err
+	}
+	content, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	b.objects[path] = content
+	return nil
+}
+
+func (b *memoryBackend) Download(_ context.Context, path string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(b.objects[path])), nil
+}
+
+func (b *memoryBackend) Delete(_ context.Context, path string) error {
+	if err := b.failing["delete"]; err != nil {
+		return err
+	}
+	delete(b.objects, path)
+	return nil
+}
+
+func (b *memoryBackend) List(context.Context, string) ([]BackupInfo, error) { return nil, nil }
+
+func (b *memoryBackend) Exists(_ context.Context, path string) (bool, error) {
+	if err := b.failing["exists"]; err != nil {
+		return false, err
+	}
+	_, ok := b.objects[path]
+	return ok, nil
+}
+
+func (b *memoryBackend) GetMetadata(context.Context, string) (map[string]string, error) {
+	return nil, nil
+}
+
+func TestProbe(t *testing.T) {
+	denied := errors.New("AccessDenied")
+	tests := []struct {
+		name    string
+		write   bool
+		failing map[string]error
+		wantErr string
+	}{
+		{name: "read probe", write: false},
+		{name: "write probe", write: true},
+		{name: "bad credentials", write: false, failing: map[string]error{"exists": denied}, wantErr: "read probe failed"},
+		{name: "read-only credentials", write: true, failing: map[string]error{"upload": denied}, wantErr: "write probe failed"},
+		{name: "delete denied", write: true, failing: map[string]error{"delete": denied}, wantErr: "delete probe failed"},
+		{name: "read probe skips writes", write: false, failing: map[string]error{"upload": denied}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &memoryBackend{objects: map[string][]byte{}, failing: tt.failing}
+			err := Probe(context.Background(), backend, ProbeKey("ns.policy"), tt.write)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(backend.objects) != 0 {
+					t.Fatalf("expected the probe object to be removed, got %v", backend.objects)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !errors.Is(err, denied) {
+				t.Fatalf("expected %q wrapping the backend error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -21,15 +21,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"path/filepath"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"golang.org/x/time/rate"
 )
 
 // S3Backend implements the Backend interface for S3-compatible storage
@@ -37,6 +41,7 @@ type S3Backend struct {
 	config   *Config
 	client   *s3.Client
 	uploader *manager.Uploader
+	limiter  *rate.Limiter
 }
 
 // NewS3Backend creates a new S3 storage backend
@@ -64,6 +69,17 @@ func NewS3Backend(cfg *Config) (Backend, error) {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
+	// Assume the configured role on top of the base credentials
+	if cfg.RoleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsConfig), cfg.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = "backup-operator"
+			if cfg.ExternalID != "" {
+				o.ExternalID = aws.String(cfg.ExternalID)
+			}
+		})
+		awsConfig.Credentials = aws.NewCredentialsCache(provider)
+	}
+
 	// Build S3 client options
 	var s3Opts []func(*s3.Options)
 
@@ -80,7 +96,7 @@ func NewS3Backend(cfg *Config) (Backend, error) {
 	client := s3.NewFromConfig(awsConfig, s3Opts...)
 
 	// Create uploader for efficient uploads
-	uploader := manager.NewUploader(client)
+	uploader := newUploader(client, cfg)
 
 	// Note: Skip connection test during initialization to allow operator to run outside cluster
 	// The actual connection will be tested when backup Jobs run inside the cluster
@@ -89,6 +105,7 @@ func NewS3Backend(cfg *Config) (Backend, error) {
 		config:   cfg,
 		client:   client,
 		uploader: uploader,
+		limiter:  newLimiter(cfg),
 	}, nil
 }
 
@@ -103,33 +120,153 @@ func (s *S3Backend) Upload(ctx context.Context, data io.Reader, path string, met
 		s3Metadata[k] = v
 	}
 
+	// Seekable sources continue an upload interrupted earlier instead of starting from zero. Only sources
+	// larger than a part are uploaded in parts, so smaller ones never leave an upload to resume.
+	if src, ok := data.(io.ReadSeeker); ok && s.resumable(src) {
+		if resumed, err := s.resumeUpload(ctx, src, key); err == nil && resumed {
+			return nil
+		}
+		// Nothing to resume, or it could not be resumed, e.g. without permission to list uploads
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind upload data: %w", err)
+		}
+	}
+
 	// Upload using the uploader (handles multipart automatically for large files)
-	_, err := s.uploader.Upload(ctx, &s3.PutObjectInput{
+	_, err := s.uploader.Upload(ctx, s.putObjectInput(key, s.throttle(ctx, data), s3Metadata, time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to upload to s3://%s/%s: %w", s.config.Bucket, key, err)
+	}
+
+	return nil
+}
+
+// putObjectInput builds the upload request carrying the configured storage class, encryption and retention
+func (s *S3Backend) putObjectInput(key string, data io.Reader, metadata map[string]string, now time.Time) *s3.PutObjectInput {
+	input := &s3.PutObjectInput{
 		Bucket:   aws.String(s.config.Bucket),
 		Key:      aws.String(key),
 		Body:     data,
-		Metadata: s3Metadata,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to upload to s3://%s/%s: %w", s.config.Bucket, key, err)
+		Metadata: metadata,
 	}
 
+	if s.config.StorageClass != "" {
+		input.StorageClass = types.StorageClass(s.config.StorageClass)
+	}
+	if s.config.ChecksumAlgorithm != "" {
+		input.ChecksumAlgorithm = types.ChecksumAlgorithm(s.config.ChecksumAlgorithm)
+	}
+	if s.config.ServerSideEncryption != "" {
+		input.ServerSideEncryption = types.ServerSideEncryption(s.config.ServerSideEncryption)
+		if s.config.KMSKeyID != "" {
+			input.SSEKMSKeyId = aws.String(s.config.KMSKeyID)
+		}
+	}
+	if s.config.ObjectLockMode != "" && s.config.ObjectLockRetentionDays > 0 {
+		input.ObjectLockMode = types.ObjectLockMode(s.config.ObjectLockMode)
+		input.ObjectLockRetainUntilDate = aws.Time(now.AddDate(0, 0, int(s.config.ObjectLockRetentionDays)))
+	}
+	return input
+}
+
+// VerifyProtection checks that the bucket defaults apply the configured encryption and object lock retention
+// to objects written without per-object settings
+func (s *S3Backend) VerifyProtection(ctx context.Context) error {
+	if s.config.ServerSideEncryption != "" {
+		out, err := s.client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{Bucket: aws.String(s.config.Bucket)})
+		if err != nil {
+			return fmt.Errorf("failed to read default encryption of bucket %s: %w", s.config.Bucket, err)
+		}
+		if err := checkDefaultEncryption(s.config, out.ServerSideEncryptionConfiguration); err != nil {
+			return err
+		}
+	}
+
+	if s.config.ObjectLockMode != "" {
+		out, err := s.client.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{Bucket: aws.String(s.config.Bucket)})
+		if err != nil {
+			return fmt.Errorf("failed to read object lock configuration of bucket %s: %w", s.config.Bucket, err)
+		}
+		if err := checkDefaultRetention(s.config, out.ObjectLockConfiguration); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func checkDefaultEncryption(cfg *Config, sse *types.ServerSideEncryptionConfiguration) error {
+	if sse != nil {
+		for _, rule := range sse.Rules {
+			def := rule.ApplyServerSideEncryptionByDefault
+			if def == nil || string(def.SSEAlgorithm) != cfg.ServerSideEncryption {
+				continue
+			}
+			if cfg.KMSKeyID == "" || kmsKeyMatches(aws.ToString(def.KMSMasterKeyID), cfg.KMSKeyID) {
+				return nil
+			}
+		}
+	}
+	if cfg.KMSKeyID != "" {
+		return fmt.Errorf("bucket %s does not encrypt new objects with %s key %s by default", cfg.Bucket, cfg.ServerSideEncryption, cfg.KMSKeyID)
+	}
+	return fmt.Errorf("bucket %s does not encrypt new objects with %s by default", cfg.Bucket, cfg.ServerSideEncryption)
+}
+
+func checkDefaultRetention(cfg *Config, lock *types.ObjectLockConfiguration) error {
+	if lock == nil || lock.ObjectLockEnabled != types.ObjectLockEnabledEnabled {
+		return fmt.Errorf("object lock is not enabled on bucket %s", cfg.Bucket)
+	}
+	if lock.Rule == nil || lock.Rule.DefaultRetention == nil {
+		return fmt.Errorf("bucket %s has no default object lock retention", cfg.Bucket)
+	}
+
+	retention := lock.Rule.DefaultRetention
+	days := aws.ToInt32(retention.Days) + 365*aws.ToInt32(retention.Years)
+	if string(retention.Mode) != cfg.ObjectLockMode || days < cfg.ObjectLockRetentionDays {
+		return fmt.Errorf("bucket %s default retention is %s for %d days, expected %s for at least %d days",
+			cfg.Bucket, retention.Mode, days, cfg.ObjectLockMode, cfg.ObjectLockRetentionDays)
+	}
+	return nil
+}
+
+// kmsKeyMatches compares KMS keys given as key IDs, aliases or ARNs
+func kmsKeyMatches(a, b string) bool {
+	if a == b {
+		return true
+	}
+	for _, sep := range []string{"/", ":"} {
+		if strings.HasSuffix(a, sep+b) || strings.HasSuffix(b, sep+a) {
+			return true
+		}
+	}
+	return false
+}
+
 // Download downloads data from S3
 func (s *S3Backend) Download(ctx context.Context, path string) (io.ReadCloser, error) {
 	key := s.buildKey(path)
 
-	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(s.config.Bucket),
 		Key:    aws.String(key),
-	})
+	}
+	// The SDK validates the stored checksum while the body is read
+	if s.config.ChecksumAlgorithm != "" {
+		input.ChecksumMode = types.ChecksumModeEnabled
+	}
+
+	result, err := s.client.GetObject(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download from s3://%s/%s: %w", s.config.Bucket, key, err)
 	}
 
-	return result.Body, nil
+	if s.limiter == nil {
+		return result.Body, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{s.throttle(ctx, result.Body), result.Body}, nil
 }
 
 // Delete deletes an object from S3
@@ -147,9 +284,9 @@ func (s *S3Backend) Delete(ctx context.Context, path string) error {
 	return nil
 }
 
-// List lists all objects with the given prefix
+// List lists all objects with the given prefix; one ListObjectsV2 call per 1000 objects and no per-object requests
 func (s *S3Backend) List(ctx context.Context, prefix string) ([]BackupInfo, error) {
-	fullPrefix := s.buildKey(prefix)
+	fullPrefix := s.listPrefix(prefix)
 
 	var backups []BackupInfo
 	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
@@ -167,20 +304,11 @@ func (s *S3Backend) List(ctx context.Context, prefix string) ([]BackupInfo, erro
 			if obj.Key == nil {
 				continue
 			}
-
-			// Get metadata for this object
-			metadata, err := s.GetMetadata(ctx, strings.TrimPrefix(*obj.Key, s.config.Prefix+"/"))
-			if err != nil {
-				// Log error but continue listing
-				metadata = make(map[string]string)
-			}
-
 			backups = append(backups, BackupInfo{
-				Name:         filepath.Base(*obj.Key),
-				Path:         *obj.Key,
-				Size:         *obj.Size,
-				ModifiedTime: *obj.LastModified,
-				Metadata:     metadata,
+				Name:         path.Base(*obj.Key),
+				Path:         s.relativeKey(*obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				ModifiedTime: aws.ToTime(obj.LastModified),
 			})
 		}
 	}
@@ -188,6 +316,37 @@ func (s *S3Backend) List(ctx context.Context, prefix string) ([]BackupInfo, erro
 	return backups, nil
 }
 
+// ListPrefixes lists the common prefixes directly below prefix using "/" as delimiter
+func (s *S3Backend) ListPrefixes(ctx context.Context, prefix string) ([]string, error) {
+	fullPrefix := s.listPrefix(strings.TrimSuffix(prefix, "/") + "/")
+	if fullPrefix == "/" {
+		fullPrefix = ""
+	}
+
+	var prefixes []string
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.config.Bucket),
+		Prefix:    aws.String(fullPrefix),
+		Delimiter: aws.String("/"),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list prefixes below %s: %w", fullPrefix, err)
+		}
+
+		for _, common := range page.CommonPrefixes {
+			if common.Prefix == nil {
+				continue
+			}
+			prefixes = append(prefixes, s.relativeKey(*common.Prefix))
+		}
+	}
+
+	return prefixes, nil
+}
+
 // Exists checks if an object exists in S3
 func (s *S3Backend) Exists(ctx context.Context, path string) (bool, error) {
 	key := s.buildKey(path)
@@ -230,10 +389,30 @@ func (s *S3Backend) GetMetadata(ctx context.Context, path string) (map[string]st
 	return metadata, nil
 }
 
-// buildKey builds the full S3 key from a relative path
-func (s *S3Backend) buildKey(path string) string {
-	if s.config.Prefix == "" {
-		return path
+// buildKey builds the full S3 key from a relative path; S3 keys always use forward slashes
+func (s *S3Backend) buildKey(rel string) string {
+	prefix := strings.Trim(s.config.Prefix, "/")
+	if prefix == "" {
+		return rel
+	}
+	return path.Join(prefix, rel)
+}
+
+// listPrefix builds the key prefix for a listing; an empty or directory-like prefix only matches
+// keys below it, so listing "backups" does not also return "backups-old/..."
+func (s *S3Backend) listPrefix(prefix string) string {
+	key := s.buildKey(prefix)
+	if key != "" && (prefix == "" || strings.HasSuffix(prefix, "/")) && !strings.HasSuffix(key, "/") {
+		key += "/"
+	}
+	return key
+}
+
+// relativeKey strips the backend prefix from a full S3 key
+func (s *S3Backend) relativeKey(key string) string {
+	prefix := strings.Trim(s.config.Prefix, "/")
+	if prefix == "" {
+		return key
 	}
-	return filepath.Join(s.config.Prefix, path)
+	return strings.TrimPrefix(key, prefix+"/")
 }
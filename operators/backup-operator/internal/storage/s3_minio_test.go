@@ -136,6 +136,10 @@ func TestMinIOCompatibility(t *testing.T) {
 			t.Fatalf("Expected at least 4 files, got %d", len(backups))
 		}
 
+		if err := LoadMetadata(ctx, backend, backups, 4); err != nil {
+			t.Fatalf("LoadMetadata failed: %v", err)
+		}
+
 		t.Logf("✅ List successful, found %d files:", len(backups))
 		for _, backup := range backups {
 			t.Logf("  - %s (size: %d, modified: %v)", backup.Name, backup.Size, backup.ModifiedTime)
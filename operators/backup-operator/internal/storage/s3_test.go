@@ -0,0 +1,108 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestPutObjectInputAppliesProtection(t *testing.T) {
+	backend := &S3Backend{config: &Config{
+		Bucket:                  "backups",
+		StorageClass:            "STANDARD_IA",
+		ServerSideEncryption:    "aws:kms",
+		KMSKeyID:                "1234abcd",
+		ObjectLockMode:          "COMPLIANCE",
+		ObjectLockRetentionDays: 30,
+	}}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	input := backend.putObjectInput("policy/data", strings.NewReader("x"), nil, now)
+	if input.StorageClass != types.StorageClassStandardIa {
+		t.Errorf("expected storage class STANDARD_IA, got %q", input.StorageClass)
+	}
+	if input.ServerSideEncryption != types.ServerSideEncryptionAwsKms || aws.ToString(input.SSEKMSKeyId) != "1234abcd" {
+		t.Errorf("expected SSE-KMS with key 1234abcd, got %q %q", input.ServerSideEncryption, aws.ToString(input.SSEKMSKeyId))
+	}
+	if input.ObjectLockMode != types.ObjectLockModeCompliance || !input.ObjectLockRetainUntilDate.Equal(now.AddDate(0, 0, 30)) {
+		t.Errorf("expected COMPLIANCE retention for 30 days, got %q until %v", input.ObjectLockMode, input.ObjectLockRetainUntilDate)
+	}
+}
+
+func TestCheckBucketDefaults(t *testing.T) {
+	cfg := &Config{
+		Bucket:                  "backups",
+		ServerSideEncryption:    "aws:kms",
+		KMSKeyID:                "1234abcd",
+		ObjectLockMode:          "GOVERNANCE",
+		ObjectLockRetentionDays: 30,
+	}
+
+	sse := &types.ServerSideEncryptionConfiguration{Rules: []types.ServerSideEncryptionRule{{
+		ApplyServerSideEncryptionByDefault: &types.ServerSideEncryptionByDefault{
+			SSEAlgorithm:   types.ServerSideEncryptionAwsKms,
+			KMSMasterKeyID: aws.String("arn:aws:kms:eu-west-1:123456789012:key/1234abcd"),
+		},
+	}}}
+	if err := checkDefaultEncryption(cfg, sse); err != nil {
+		t.Errorf("expected key ARN to match key ID: %v", err)
+	}
+	sse.Rules[0].ApplyServerSideEncryptionByDefault.SSEAlgorithm = types.ServerSideEncryptionAes256
+	if err := checkDefaultEncryption(cfg, sse); err == nil {
+		t.Errorf("expected SSE-S3 default to be rejected when SSE-KMS is required")
+	}
+
+	lock := &types.ObjectLockConfiguration{
+		ObjectLockEnabled: types.ObjectLockEnabledEnabled,
+		Rule: &types.ObjectLockRule{DefaultRetention: &types.DefaultRetention{
+			Mode:  types.ObjectLockRetentionModeGovernance,
+			Years: aws.Int32(1),
+		}},
+	}
+	if err := checkDefaultRetention(cfg, lock); err != nil {
+		t.Errorf("expected one year of retention to satisfy 30 days: %v", err)
+	}
+	lock.Rule.DefaultRetention = &types.DefaultRetention{Mode: types.ObjectLockRetentionModeGovernance, Days: aws.Int32(7)}
+	if err := checkDefaultRetention(cfg, lock); err == nil {
+		t.Errorf("expected a 7 day default retention to be rejected")
+	}
+	if err := checkDefaultRetention(cfg, &types.ObjectLockConfiguration{}); err == nil {
+		t.Errorf("expected a bucket without object lock to be rejected")
+	}
+}
+
+func TestS3KeysUseForwardSlashes(t *testing.T) {
+	backend := &S3Backend{config: &Config{Bucket: "backups", Prefix: "/prod/cluster-a/"}}
+
+	if got := backend.buildKey("policy/ns/data"); got != "prod/cluster-a/policy/ns/data" {
+		t.Errorf("unexpected key %q", got)
+	}
+	if got := backend.relativeKey("prod/cluster-a/policy/ns/data"); got != "policy/ns/data" {
+		t.Errorf("unexpected relative key %q", got)
+	}
+	if got := backend.listPrefix(""); got != "prod/cluster-a/" {
+		t.Errorf("expected listing of the whole prefix to end with a slash, got %q", got)
+	}
+	if got := backend.listPrefix("policy-"); got != "prod/cluster-a/policy-" {
+		t.Errorf("expected partial name prefixes to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,333 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+// maxThrottleBurst caps the bytes a single read may take from the bandwidth limiter
+const maxThrottleBurst = 1024 * 1024
+
+// newUploader creates the multipart uploader; parts of failed uploads are kept so the upload can be resumed
+func newUploader(client *s3.Client, cfg *Config) *manager.Uploader {
+	return manager.NewUploader(client, func(u *manager.Uploader) {
+		if cfg.PartSize > 0 {
+			u.PartSize = max(cfg.PartSize, manager.MinUploadPartSize)
+		}
+		if cfg.Concurrency > 0 {
+			u.Concurrency = cfg.Concurrency
+		}
+		u.LeavePartsOnError = true
+	})
+}
+
+// newLimiter returns the bandwidth limiter shared by all transfers of a backend, nil when unlimited
+func newLimiter(cfg *Config) *rate.Limiter {
+	if cfg.BandwidthLimit <= 0 {
+		return nil
+	}
+	burst := int(min(cfg.BandwidthLimit, maxThrottleBurst))
+	return rate.NewLimiter(rate.Limit(cfg.BandwidthLimit), burst)
+}
+
+// throttledReader delays reads so the bytes passing through stay within the limiter's rate
+type throttledReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if burst := t.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := t.r.Read(p)
+	if n > 0 {
+		if waitErr := t.limiter.WaitN(t.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// throttle applies the backend's bandwidth limit to a reader
+func (s *S3Backend) throttle(ctx context.Context, r io.Reader) io.Reader {
+	if s.limiter == nil {
+		return r
+	}
+	return &throttledReader{ctx: ctx, r: r, limiter: s.limiter}
+}
+
+func (s *S3Backend) partSize() int64 {
+	if s.config.PartSize > 0 {
+		return max(s.config.PartSize, manager.MinUploadPartSize)
+	}
+	return manager.DefaultUploadPartSize
+}
+
+func (s *S3Backend) concurrency() int {
+	if s.config.Concurrency > 0 {
+		return s.config.Concurrency
+	}
+	return manager.DefaultUploadConcurrency
+}
+
+// resumable reports whether src, read from its start, is larger than one part
+func (s *S3Backend) resumable(src io.Seeker) bool {
+	size, err := src.Seek(0, io.SeekEnd)
+	if _, rewindErr := src.Seek(0, io.SeekStart); err != nil || rewindErr != nil {
+		return false
+	}
+	return size > s.partSize()
+}
+
+// resumeUpload continues the newest incomplete multipart upload of key, skipping the parts already stored.
+// It returns false with src rewound when there is nothing to resume or the stored parts do not match src.
+func (s *S3Backend) resumeUpload(ctx context.Context, src io.ReadSeeker, key string) (bool, error) {
+	uploadID, err := s.findIncompleteUpload(ctx, key)
+	if err != nil || uploadID == "" {
+		return false, err
+	}
+
+	stored, err := s.listParts(ctx, key, uploadID)
+	if err != nil {
+		return false, err
+	}
+
+	partSize := s.partSize()
+	buf := make([]byte, partSize)
+	completed := make([]types.CompletedPart, 0, len(stored))
+	for i, part := range stored {
+		matches := aws.ToInt32(part.PartNumber) == int32(i+1) && aws.ToInt64(part.Size) == partSize
+		if matches {
+			_, err := io.ReadFull(src, buf)
+			matches = err == nil && s.partMatches(part, buf)
+		}
+		if !matches {
+			// Different data or part size: start over instead of producing a corrupt object
+			return false, s.abandonUpload(ctx, src, key, uploadID)
+		}
+		completed = append(completed, types.CompletedPart{
+			PartNumber:     part.PartNumber,
+			ETag:           part.ETag,
+			ChecksumCRC32C: part.ChecksumCRC32C,
+			ChecksumSHA256: part.ChecksumSHA256,
+		})
+	}
+
+	rest, err := s.uploadParts(ctx, s.throttle(ctx, src), key, uploadID, int32(len(stored)+1))
+	if err != nil {
+		return false, err
+	}
+	completed = append(completed, rest...)
+	sort.Slice(completed, func(i, j int) bool {
+		return aws.ToInt32(completed[i].PartNumber) < aws.ToInt32(completed[j].PartNumber)
+	})
+
+	if _, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.config.Bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	}); err != nil {
+		return false, fmt.Errorf("failed to complete multipart upload %s: %w", uploadID, err)
+	}
+	return true, nil
+}
+
+// findIncompleteUpload returns the ID of the most recently started multipart upload of key, if any
+func (s *S3Backend) findIncompleteUpload(ctx context.Context, key string) (string, error) {
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(s.config.Bucket),
+		Prefix: aws.String(key),
+	}
+
+	var latest *types.MultipartUpload
+	for {
+		out, err := s.client.ListMultipartUploads(ctx, input)
+		if err != nil {
+			return "", fmt.Errorf("failed to list multipart uploads of %s: %w", key, err)
+		}
+		for i := range out.Uploads {
+			upload := &out.Uploads[i]
+			if aws.ToString(upload.Key) != key {
+				continue
+			}
+			if latest == nil || aws.ToTime(upload.Initiated).After(aws.ToTime(latest.Initiated)) {
+				latest = upload
+			}
+		}
+		if !aws.ToBool(out.IsTruncated) {
+			break
+		}
+		input.KeyMarker = out.NextKeyMarker
+		input.UploadIdMarker = out.NextUploadIdMarker
+	}
+
+	if latest == nil {
+		return "", nil
+	}
+	return aws.ToString(latest.UploadId), nil
+}
+
+func (s *S3Backend) listParts(ctx context.Context, key, uploadID string) ([]types.Part, error) {
+	var parts []types.Part
+	paginator := s3.NewListPartsPaginator(s.client, &s3.ListPartsInput{
+		Bucket:   aws.String(s.config.Bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list parts of multipart upload %s: %w", uploadID, err)
+		}
+		parts = append(parts, page.Parts...)
+	}
+	return parts, nil
+}
+
+// uploadParts uploads the rest of r as parts numbered from first, with bounded concurrency
+func (s *S3Backend) uploadParts(ctx context.Context, r io.Reader, key, uploadID string, first int32) ([]types.CompletedPart, error) {
+	var (
+		g         errgroup.Group
+		completed []types.CompletedPart
+		results   = make(chan types.CompletedPart)
+		collected = make(chan struct{})
+	)
+	go func() {
+		for part := range results {
+			completed = append(completed, part)
+		}
+		close(collected)
+	}()
+
+	g.SetLimit(s.concurrency())
+	var readErr error
+	for number := first; ; number++ {
+		buf := make([]byte, s.partSize())
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if number > manager.MaxUploadParts {
+				readErr = fmt.Errorf("upload exceeds %d parts, increase the part size", manager.MaxUploadParts)
+				break
+			}
+			partNumber, body := number, buf[:n]
+			g.Go(func() error {
+				part, err := s.uploadPart(ctx, key, uploadID, partNumber, body)
+				if err == nil {
+					results <- part
+				}
+				return err
+			})
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			readErr = fmt.Errorf("failed to read upload data: %w", err)
+			break
+		}
+	}
+
+	err := g.Wait()
+	close(results)
+	<-collected
+	if readErr != nil {
+		return nil, readErr
+	}
+	return completed, err
+}
+
+func (s *S3Backend) uploadPart(ctx context.Context, key, uploadID string, number int32, body []byte) (types.CompletedPart, error) {
+	input := &s3.UploadPartInput{
+		Bucket:        aws.String(s.config.Bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(number),
+		Body:          bytes.NewReader(body),
+		ContentLength: aws.Int64(int64(len(body))),
+	}
+	if s.config.ChecksumAlgorithm != "" {
+		input.ChecksumAlgorithm = types.ChecksumAlgorithm(s.config.ChecksumAlgorithm)
+	}
+
+	out, err := s.client.UploadPart(ctx, input)
+	if err != nil {
+		return types.CompletedPart{}, fmt.Errorf("failed to upload part %d: %w", number, err)
+	}
+	return types.CompletedPart{
+		PartNumber:     aws.Int32(number),
+		ETag:           out.ETag,
+		ChecksumCRC32C: out.ChecksumCRC32C,
+		ChecksumSHA256: out.ChecksumSHA256,
+	}, nil
+}
+
+// partMatches compares a stored part with local data using the configured checksum, or the MD5 ETag otherwise
+func (s *S3Backend) partMatches(part types.Part, data []byte) bool {
+	switch types.ChecksumAlgorithm(s.config.ChecksumAlgorithm) {
+	case types.ChecksumAlgorithmCrc32c:
+		sum := make([]byte, 4)
+		binary.BigEndian.PutUint32(sum, crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)))
+		return aws.ToString(part.ChecksumCRC32C) == base64.StdEncoding.EncodeToString(sum)
+	case types.ChecksumAlgorithmSha256:
+		sum := sha256.Sum256(data)
+		return aws.ToString(part.ChecksumSHA256) == base64.StdEncoding.EncodeToString(sum[:])
+	default:
+		// ETags of SSE-KMS objects are not MD5 digests, so those uploads are restarted
+		sum := md5.Sum(data)
+		return strings.Trim(aws.ToString(part.ETag), `"`) == hex.EncodeToString(sum[:])
+	}
+}
+
+// abandonUpload aborts a multipart upload that cannot be resumed and rewinds the source
+func (s *S3Backend) abandonUpload(ctx context.Context, src io.Seeker, key, uploadID string) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.config.Bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	var noSuchUpload *types.NoSuchUpload
+	if err != nil && !errors.As(err, &noSuchUpload) {
+		return fmt.Errorf("failed to abort multipart upload %s: %w", uploadID, err)
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind upload data: %w", err)
+	}
+	return nil
+}
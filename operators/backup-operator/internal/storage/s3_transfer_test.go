@@ -0,0 +1,90 @@
+/*
+Copyright 2025 hepj1999@gmail.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestPartMatches(t *testing.T) {
+	data := []byte("part data")
+
+	crc := &S3Backend{config: &Config{ChecksumAlgorithm: "CRC32C"}}
+	sum32 := make([]byte, 4)
+	binary.BigEndian.PutUint32(sum32, crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)))
+	stored := types.Part{ChecksumCRC32C: aws.String(base64.StdEncoding.EncodeToString(sum32))}
+	if !crc.partMatches(stored, data) {
+		t.Errorf("expected CRC32C checksum to match")
+	}
+	if crc.partMatches(stored, []byte("other data")) {
+		t.Errorf("expected CRC32C checksum of different data not to match")
+	}
+
+	sum := md5.Sum(data)
+	etag := &S3Backend{config: &Config{}}
+	if !etag.partMatches(types.Part{ETag: aws.String(`"` + hex.EncodeToString(sum[:]) + `"`)}, data) {
+		t.Errorf("expected MD5 ETag to match without a checksum algorithm")
+	}
+}
+
+func TestResumableOnlyAboveOnePart(t *testing.T) {
+	backend := &S3Backend{config: &Config{PartSize: 5 << 20}}
+
+	small := bytes.NewReader(make([]byte, 5<<20))
+	if backend.resumable(small) {
+		t.Errorf("expected a source of one part not to be resumable")
+	}
+	large := bytes.NewReader(make([]byte, 5<<20+1))
+	if _, err := large.Seek(10, io.SeekStart); err != nil {
+		t.Fatalf("failed to seek: %v", err)
+	}
+	if !backend.resumable(large) {
+		t.Errorf("expected a source larger than a part to be resumable")
+	}
+	if offset, _ := large.Seek(0, io.SeekCurrent); offset != 0 {
+		t.Errorf("expected the source to be rewound, got offset %d", offset)
+	}
+}
+
+func TestThrottleLimitsBandwidth(t *testing.T) {
+	backend := &S3Backend{config: &Config{}, limiter: newLimiter(&Config{BandwidthLimit: 64 * 1024})}
+	// Drain the initial burst so the measured read is rate limited
+	if err := backend.limiter.WaitN(context.Background(), backend.limiter.Burst()); err != nil {
+		t.Fatalf("failed to drain limiter: %v", err)
+	}
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, backend.throttle(context.Background(), bytes.NewReader(make([]byte, 16*1024))))
+	if err != nil || n != 16*1024 {
+		t.Fatalf("unexpected copy result %d, %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected 16KiB at 64KiB/s to take about 250ms, took %v", elapsed)
+	}
+}